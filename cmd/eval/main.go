@@ -0,0 +1,122 @@
+// Command eval runs a dataset of conversations through the chat logic under
+// two configurations and writes a report comparing them.
+//
+//	go run ./cmd/eval -dataset cases.yaml -spec spec.yaml -out report.md
+//
+// It needs the same MySQL, Redis and environment as the server. Point
+// -base-url at a local mock provider to run it in CI; OPENAI_KEY then
+// defaults to a placeholder.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/eval"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/impls/openai"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+)
+
+func main() {
+	var (
+		configPath       = flag.String("config", "configs/dev.yaml", "service config")
+		datasetPath      = flag.String("dataset", "", "YAML or JSONL dataset of cases")
+		specPath         = flag.String("spec", "", "YAML spec of the baseline and candidate configurations")
+		baseURL          = flag.String("base-url", "", "override the provider base URL, e.g. a local mock")
+		outPath          = flag.String("out", "", "markdown report path; stdout when empty")
+		jsonPath         = flag.String("json", "", "also write the full results as JSON")
+		userID           = flag.String("user", "eval-runner", "user the conversations are created for")
+		timeout          = flag.Duration("timeout", 2*time.Minute, "timeout per turn")
+		keep             = flag.Bool("keep", false, "keep the evaluated conversations")
+		failOnRegression = flag.Bool("fail-on-regression", false, "exit 1 when the candidate fails a turn the baseline passed")
+	)
+	flag.Parse()
+	lgr := logger.L()
+	if *datasetPath == "" || *specPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cases, err := eval.LoadDataset(*datasetPath)
+	if err != nil {
+		lgr.Fatalf("load dataset error: %v", err)
+	}
+	spec, err := eval.LoadSpec(*specPath)
+	if err != nil {
+		lgr.Fatalf("load spec error: %v", err)
+	}
+	cfg, err := configs.Load(*configPath)
+	if err != nil {
+		lgr.Fatalf("load config error: %v", err)
+	}
+	if *baseURL != "" {
+		cfg.LLMRequestConf.OpenAI.BaseURL = *baseURL
+		if os.Getenv("OPENAI_KEY") == "" {
+			_ = os.Setenv("OPENAI_KEY", "eval")
+		}
+	}
+	// Cached replies would hide the configuration under test.
+	cfg.CacheConf.Enabled = false
+	svcCtx := svc.NewContext(cfg)
+
+	completer, err := openai.NewCompleter(svcCtx)
+	if err != nil {
+		lgr.Fatalf("judge init error: %v", err)
+	}
+	graders := eval.NewGraders()
+	graders.Register(eval.GraderJudge, eval.JudgeGraders(completer, spec.JudgeModel))
+	suite, err := eval.NewSuite(cases, graders)
+	if err != nil {
+		lgr.Fatalf("build graders error: %v", err)
+	}
+
+	runner := &eval.Runner{UserID: *userID, TurnTimeout: *timeout, Keep: *keep}
+	ctx := context.Background()
+	report := &eval.Report{Dataset: *datasetPath, GeneratedAt: time.Now()}
+	report.Baseline = runner.Run(ctx, suite, target(svcCtx, spec.Baseline))
+	report.Candidate = runner.Run(ctx, suite, target(svcCtx, spec.Candidate))
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			lgr.Fatalf("create report error: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := report.WriteMarkdown(out); err != nil {
+		lgr.Fatalf("write report error: %v", err)
+	}
+	if *jsonPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			lgr.Fatalf("encode results error: %v", err)
+		}
+		if err := os.WriteFile(*jsonPath, data, 0o644); err != nil {
+			lgr.Fatalf("write results error: %v", err)
+		}
+	}
+	if regressions := report.Regressions(); *failOnRegression && len(regressions) > 0 {
+		lgr.Errorf("candidate regressed on %d turns", len(regressions))
+		os.Exit(1)
+	}
+}
+
+// target builds the chat logic with every conversation assigned to the
+// variant.
+func target(svcCtx *svc.Context, v configs.ExperimentVariant) eval.Target {
+	c := *svcCtx
+	c.Config.ExperimentConf = eval.ExperimentConfig(v)
+	logic, err := openai.NewChatLogic(&c)
+	if err != nil {
+		logger.L().Fatalf("chat logic init for %s error: %v", v.Name, err)
+	}
+	return eval.Target{Variant: v, Logic: logic}
+}
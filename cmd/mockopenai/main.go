@@ -0,0 +1,33 @@
+// Command mockopenai serves the in-process mock provider on a local port so
+// the server or the eval harness can run without a real provider:
+//
+//	go run ./cmd/mockopenai -addr :8089
+//	go run ./cmd/eval -base-url http://localhost:8089/v1 ...
+//
+// Chat requests are answered by echoing the last user message.
+package main
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	"github.com/im-core-go/im-core-bot-platform/pkg/openaitest"
+)
+
+func main() {
+	var (
+		addr   = flag.String("addr", ":8089", "listen address")
+		models = flag.String("models", "mock-model", "comma-separated models /models lists")
+	)
+	flag.Parse()
+	lgr := logger.L()
+
+	provider := openaitest.NewProvider()
+	provider.SetModels(strings.Split(*models, ",")...)
+	lgr.Infof("mock provider listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, provider); err != nil {
+		lgr.Fatalf("mock provider error: %v", err)
+	}
+}
@@ -4,6 +4,25 @@ type Config struct {
 	MysqlConf      MysqlConfig      `json:"mysql_conf" yaml:"mysql_conf"`
 	RedisConf      RedisConfig      `json:"redis_conf" yaml:"redis_conf"`
 	LLMRequestConf LLMRequestConfig `json:"llm_request_conf" yaml:"llm_request_conf"`
+	ImageInputConf ImageInputConfig `json:"image_input_conf" yaml:"image_input_conf"`
+	BlobConf       BlobConfig       `json:"blob_conf" yaml:"blob_conf"`
+	AttachmentConf AttachmentConfig `json:"attachment_conf" yaml:"attachment_conf"`
+	VectorConf     VectorConfig     `json:"vector_conf" yaml:"vector_conf"`
+	KnowledgeConf  KnowledgeConfig  `json:"knowledge_conf" yaml:"knowledge_conf"`
+	SearchConf     SearchConfig     `json:"search_conf" yaml:"search_conf"`
+	TrashConf      TrashConfig      `json:"trash_conf" yaml:"trash_conf"`
+	ImportConf     ImportConfig     `json:"import_conf" yaml:"import_conf"`
+	GroupConf      GroupConfig      `json:"group_conf" yaml:"group_conf"`
+	IMConf         IMConfig         `json:"im_conf" yaml:"im_conf"`
+	WebhookConf    WebhookConfig    `json:"webhook_conf" yaml:"webhook_conf"`
+	CommandConf    CommandConfig    `json:"command_conf" yaml:"command_conf"`
+	ReminderConf   ReminderConfig   `json:"reminder_conf" yaml:"reminder_conf"`
+	ModerationConf ModerationConfig `json:"moderation_conf" yaml:"moderation_conf"`
+	PIIConf        PIIConfig        `json:"pii_conf" yaml:"pii_conf"`
+	InjectionConf  InjectionConfig  `json:"injection_conf" yaml:"injection_conf"`
+	FeedbackConf   FeedbackConfig   `json:"feedback_conf" yaml:"feedback_conf"`
+	ExperimentConf ExperimentConfig `json:"experiment_conf" yaml:"experiment_conf"`
+	CacheConf      CacheConfig      `json:"cache_conf" yaml:"cache_conf"`
 }
 
 type MysqlConfig struct {
@@ -25,5 +44,285 @@ type LLMRequestConfig struct {
 }
 
 type OpenAIConfig struct {
-	BaseURL string `json:"base_url" yaml:"base_url"`
+	BaseURL                  string   `json:"base_url" yaml:"base_url"`
+	IncludeReasoningInPrompt bool     `json:"include_reasoning_in_prompt" yaml:"include_reasoning_in_prompt"`
+	VisionModels             []string `json:"vision_models" yaml:"vision_models"`
+	ImageSize                string   `json:"image_size" yaml:"image_size"`
+	TitleModel               string   `json:"title_model" yaml:"title_model"`
+	EmbeddingModel           string   `json:"embedding_model" yaml:"embedding_model"`
+	// PromptVersion labels the current system prompt; it is recorded on
+	// replies so feedback can be compared across prompt changes.
+	PromptVersion string `json:"prompt_version" yaml:"prompt_version"`
+	// StreamUsage asks for token usage at the end of streamed completions
+	// (stream_options.include_usage); not every gateway supports it.
+	StreamUsage bool `json:"stream_usage" yaml:"stream_usage"`
+}
+
+// ImageInputConfig limits uploaded images. PromptMaxImages caps the images
+// sent with one prompt, newest first; older ones are replaced by captions.
+type ImageInputConfig struct {
+	MaxBytes        int64    `json:"max_bytes" yaml:"max_bytes"`
+	MaxCount        int      `json:"max_count" yaml:"max_count"`
+	MimeTypes       []string `json:"mime_types" yaml:"mime_types"`
+	PromptMaxImages int      `json:"prompt_max_images" yaml:"prompt_max_images"`
+}
+
+// BlobConfig configures stored images and files. Clients get signed links
+// that expire after URLTTLSeconds, served over HTTP on HTTPAddr under the
+// path of the store's public URL; an empty HTTPAddr serves nothing.
+type BlobConfig struct {
+	Driver        string          `json:"driver" yaml:"driver"`
+	Local         LocalBlobConfig `json:"local" yaml:"local"`
+	HTTPAddr      string          `json:"http_addr" yaml:"http_addr"`
+	URLTTLSeconds int             `json:"url_ttl_seconds" yaml:"url_ttl_seconds"`
+}
+
+type LocalBlobConfig struct {
+	Root      string `json:"root" yaml:"root"`
+	PublicURL string `json:"public_url" yaml:"public_url"`
+}
+
+type AttachmentConfig struct {
+	MaxBytes          int64 `json:"max_bytes" yaml:"max_bytes"`
+	PromptTokenBudget int   `json:"prompt_token_budget" yaml:"prompt_token_budget"`
+}
+
+type VectorConfig struct {
+	CacheTTLSeconds int `json:"cache_ttl_seconds" yaml:"cache_ttl_seconds"`
+	MaxNamespaces   int `json:"max_namespaces" yaml:"max_namespaces"`
+}
+
+// KnowledgeConfig configures retrieval. Retrieved chunks go into every
+// user's prompts as system context, so only the users BotOwners lists for a
+// bot may attach knowledge bases to it; a bot without owners takes none.
+type KnowledgeConfig struct {
+	ChunkTokens   int                 `json:"chunk_tokens" yaml:"chunk_tokens"`
+	ChunkOverlap  int                 `json:"chunk_overlap" yaml:"chunk_overlap"`
+	TopK          int                 `json:"top_k" yaml:"top_k"`
+	MinScore      float32             `json:"min_score" yaml:"min_score"`
+	ContextTokens int                 `json:"context_tokens" yaml:"context_tokens"`
+	BotOwners     map[string][]string `json:"bot_owners" yaml:"bot_owners"`
+}
+
+type SearchConfig struct {
+	Semantic bool    `json:"semantic" yaml:"semantic"`
+	MinScore float32 `json:"min_score" yaml:"min_score"`
+}
+
+type TrashConfig struct {
+	RetentionDays        int `json:"retention_days" yaml:"retention_days"`
+	PurgeIntervalMinutes int `json:"purge_interval_minutes" yaml:"purge_interval_minutes"`
+	PurgeBatchSize       int `json:"purge_batch_size" yaml:"purge_batch_size"`
+}
+
+type ImportConfig struct {
+	MaxBytes int64 `json:"max_bytes" yaml:"max_bytes"`
+}
+
+type GroupConfig struct {
+	// BotHandle is the name members @-mention to address the bot.
+	BotHandle       string `json:"bot_handle" yaml:"bot_handle"`
+	MaxParticipants int    `json:"max_participants" yaml:"max_participants"`
+}
+
+// IMConfig configures the inbound IM event adapter. Transport selects the
+// event source; only "redis" (Redis Streams) is implemented.
+type IMConfig struct {
+	Enabled             bool   `json:"enabled" yaml:"enabled"`
+	Transport           string `json:"transport" yaml:"transport"`
+	InboundStream       string `json:"inbound_stream" yaml:"inbound_stream"`
+	OutboundStream      string `json:"outbound_stream" yaml:"outbound_stream"`
+	Group               string `json:"group" yaml:"group"`
+	Consumer            string `json:"consumer" yaml:"consumer"`
+	Workers             int    `json:"workers" yaml:"workers"`
+	BatchSize           int    `json:"batch_size" yaml:"batch_size"`
+	ClaimIdleSeconds    int    `json:"claim_idle_seconds" yaml:"claim_idle_seconds"`
+	EditIntervalMs      int    `json:"edit_interval_ms" yaml:"edit_interval_ms"`
+	ReplyTimeoutSeconds int    `json:"reply_timeout_seconds" yaml:"reply_timeout_seconds"`
+	OutboundMaxLen      int64  `json:"outbound_max_len" yaml:"outbound_max_len"`
+	DedupTTLHours       int    `json:"dedup_ttl_hours" yaml:"dedup_ttl_hours"`
+	DefaultModel        string `json:"default_model" yaml:"default_model"`
+}
+
+// WebhookConfig configures outbound webhooks. Subscriptions see every
+// user's conversations, so only AdminUserIDs may manage them.
+type WebhookConfig struct {
+	Enabled             bool     `json:"enabled" yaml:"enabled"`
+	AdminUserIDs        []string `json:"admin_user_ids" yaml:"admin_user_ids"`
+	MaxAttempts         int      `json:"max_attempts" yaml:"max_attempts"`
+	PollIntervalSeconds int      `json:"poll_interval_seconds" yaml:"poll_interval_seconds"`
+	BatchSize           int      `json:"batch_size" yaml:"batch_size"`
+	TimeoutSeconds      int      `json:"timeout_seconds" yaml:"timeout_seconds"`
+}
+
+// CommandConfig controls slash commands in chats. A bot listed in Bots
+// accepts exactly the named commands; other bots accept DefaultCommands, or
+// every command when that is empty.
+type CommandConfig struct {
+	Enabled         bool                `json:"enabled" yaml:"enabled"`
+	DefaultCommands []string            `json:"default_commands" yaml:"default_commands"`
+	Bots            map[string][]string `json:"bots" yaml:"bots"`
+}
+
+// ReminderConfig configures scheduled reminders. Every replica may run the
+// worker; a Redis lease of LeaseSeconds elects the one that fires them.
+// Cron reminders firing more often than MinIntervalMinutes are rejected.
+type ReminderConfig struct {
+	Enabled             bool   `json:"enabled" yaml:"enabled"`
+	DefaultTimezone     string `json:"default_timezone" yaml:"default_timezone"`
+	MaxPerUser          int    `json:"max_per_user" yaml:"max_per_user"`
+	MinIntervalMinutes  int    `json:"min_interval_minutes" yaml:"min_interval_minutes"`
+	PollIntervalSeconds int    `json:"poll_interval_seconds" yaml:"poll_interval_seconds"`
+	BatchSize           int    `json:"batch_size" yaml:"batch_size"`
+	LeaseSeconds        int    `json:"lease_seconds" yaml:"lease_seconds"`
+}
+
+// ModerationConfig configures the moderation of user input and model
+// output. Providers are "rules" (Rules below) and "openai" (the
+// /moderations endpoint of the OpenAI-compatible API). Actions are
+// "block", "redact", "flag" or empty for none; Bots overrides them per bot.
+type ModerationConfig struct {
+	Enabled      bool                              `json:"enabled" yaml:"enabled"`
+	Providers    []string                          `json:"providers" yaml:"providers"`
+	Model        string                            `json:"model" yaml:"model"`
+	Rules        []ModerationRule                  `json:"rules" yaml:"rules"`
+	InputAction  string                            `json:"input_action" yaml:"input_action"`
+	OutputAction string                            `json:"output_action" yaml:"output_action"`
+	Bots         map[string]ModerationPolicyConfig `json:"bots" yaml:"bots"`
+	// OutputChunkChars is how much streamed output is held back and
+	// moderated at a time.
+	OutputChunkChars int `json:"output_chunk_chars" yaml:"output_chunk_chars"`
+	// FailOpen lets text through when a moderator fails; by default it is
+	// treated as flagged.
+	FailOpen bool `json:"fail_open" yaml:"fail_open"`
+}
+
+// ModerationRule flags text containing any of Keywords (matched literally)
+// or Patterns (regular expressions), both case-insensitive.
+type ModerationRule struct {
+	Category string   `json:"category" yaml:"category"`
+	Keywords []string `json:"keywords" yaml:"keywords"`
+	Patterns []string `json:"patterns" yaml:"patterns"`
+}
+
+type ModerationPolicyConfig struct {
+	InputAction  string `json:"input_action" yaml:"input_action"`
+	OutputAction string `json:"output_action" yaml:"output_action"`
+}
+
+// PIIConfig controls redaction of personal data in prompts sent upstream.
+// Kinds are phone, email, id_card, bank_card and the names of Custom
+// patterns. A bot listed in Bots redacts exactly the named kinds, none when
+// the list is empty; other bots redact DefaultKinds, or every kind when
+// that is empty.
+type PIIConfig struct {
+	Enabled      bool                `json:"enabled" yaml:"enabled"`
+	DefaultKinds []string            `json:"default_kinds" yaml:"default_kinds"`
+	Custom       []PIIPattern        `json:"custom" yaml:"custom"`
+	Bots         map[string][]string `json:"bots" yaml:"bots"`
+}
+
+type PIIPattern struct {
+	Name    string `json:"name" yaml:"name"`
+	Pattern string `json:"pattern" yaml:"pattern"`
+}
+
+// InjectionConfig configures prompt-injection detection. User input and
+// retrieved documents scoring at least Threshold are flagged. InputAction
+// is "tag" (wrap it in delimiters in the prompt) or "block"; DocumentAction
+// is "tag" or "drop". ToolAction is what tool calls need once flagged
+// content is in the prompt: "allow", "confirm" (the default) or "block".
+// Bots overrides the actions per bot.
+type InjectionConfig struct {
+	Enabled        bool                             `json:"enabled" yaml:"enabled"`
+	Threshold      float64                          `json:"threshold" yaml:"threshold"`
+	Rules          []InjectionRule                  `json:"rules" yaml:"rules"`
+	InputAction    string                           `json:"input_action" yaml:"input_action"`
+	DocumentAction string                           `json:"document_action" yaml:"document_action"`
+	ToolAction     string                           `json:"tool_action" yaml:"tool_action"`
+	Classifier     InjectionClassifierConfig        `json:"classifier" yaml:"classifier"`
+	Bots           map[string]InjectionPolicyConfig `json:"bots" yaml:"bots"`
+}
+
+// InjectionRule adds a heuristic to the built-in ones; Weight is how
+// strongly a match alone indicates an injection, between 0 and 1.
+type InjectionRule struct {
+	Name    string  `json:"name" yaml:"name"`
+	Pattern string  `json:"pattern" yaml:"pattern"`
+	Weight  float64 `json:"weight" yaml:"weight"`
+}
+
+// InjectionClassifierConfig enables a second opinion from the model on user
+// input the heuristics did not flag. Model defaults to the chat model.
+type InjectionClassifierConfig struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Model   string `json:"model" yaml:"model"`
+}
+
+type InjectionPolicyConfig struct {
+	InputAction    string `json:"input_action" yaml:"input_action"`
+	DocumentAction string `json:"document_action" yaml:"document_action"`
+	ToolAction     string `json:"tool_action" yaml:"tool_action"`
+}
+
+// FeedbackConfig controls message ratings. ReasonTags restricts the reason
+// tags users may pick; empty allows any. Only AdminUserIDs may read the
+// aggregated statistics.
+type FeedbackConfig struct {
+	ReasonTags      []string `json:"reason_tags" yaml:"reason_tags"`
+	MaxCommentChars int      `json:"max_comment_chars" yaml:"max_comment_chars"`
+	AdminUserIDs    []string `json:"admin_user_ids" yaml:"admin_user_ids"`
+}
+
+// ExperimentConfig defines A/B experiments. Only AdminUserIDs may read
+// their results.
+type ExperimentConfig struct {
+	Enabled      bool         `json:"enabled" yaml:"enabled"`
+	AdminUserIDs []string     `json:"admin_user_ids" yaml:"admin_user_ids"`
+	Experiments  []Experiment `json:"experiments" yaml:"experiments"`
+}
+
+// Experiment splits a bot's traffic, or every bot's when BotID is empty,
+// between variants. Users are bucketed by a hash of their ID, so each user
+// stays in one variant; traffic beyond the variants' weights is not part of
+// the experiment. A bot takes part in the first enabled experiment that
+// covers it.
+type Experiment struct {
+	ID       string              `json:"id" yaml:"id"`
+	BotID    string              `json:"bot_id" yaml:"bot_id"`
+	Enabled  bool                `json:"enabled" yaml:"enabled"`
+	Variants []ExperimentVariant `json:"variants" yaml:"variants"`
+}
+
+// ExperimentVariant overrides the request for its share of traffic. Weight
+// is a percentage; zero values keep the request's settings, so a variant
+// without overrides is the control. SystemPrompt is a text/template
+// rendered with .BotID, .Model and .Date.
+type ExperimentVariant struct {
+	Name          string   `json:"name" yaml:"name"`
+	Weight        int      `json:"weight" yaml:"weight"`
+	Model         string   `json:"model" yaml:"model"`
+	Temperature   *float64 `json:"temperature" yaml:"temperature"`
+	TopP          *float64 `json:"top_p" yaml:"top_p"`
+	MaxTokens     int      `json:"max_tokens" yaml:"max_tokens"`
+	SystemPrompt  string   `json:"system_prompt" yaml:"system_prompt"`
+	PromptVersion string   `json:"prompt_version" yaml:"prompt_version"`
+}
+
+// CacheConfig puts a response cache in front of completions. Entries are
+// scoped to the bot, model and system prompts and keyed on the normalized
+// question; only the first question of a conversation is looked up or
+// stored, since later replies depend on the history. An empty Bots caches
+// every bot. With Semantic set, a miss falls back to the most similar cached
+// question at or above SimilarityThreshold (cosine), among the newest
+// MaxSemanticEntries of the scope.
+type CacheConfig struct {
+	Enabled             bool     `json:"enabled" yaml:"enabled"`
+	Bots                []string `json:"bots" yaml:"bots"`
+	TTLSeconds          int      `json:"ttl_seconds" yaml:"ttl_seconds"`
+	Semantic            bool     `json:"semantic" yaml:"semantic"`
+	SimilarityThreshold float64  `json:"similarity_threshold" yaml:"similarity_threshold"`
+	MaxSemanticEntries  int      `json:"max_semantic_entries" yaml:"max_semantic_entries"`
+	// ReplayChunkChars is the size of the deltas a cached reply is streamed in.
+	ReplayChunkChars int `json:"replay_chunk_chars" yaml:"replay_chunk_chars"`
 }
@@ -0,0 +1,12 @@
+package attachment
+
+import "github.com/im-core-go/im-core-bot-platform/internal/model"
+
+type Dao interface {
+	CreateAttachment(attachment model.Attachment) error
+	GetAttachmentByID(attachmentID string) (*model.Attachment, error)
+	ListAttachmentsByIDs(attachmentIDs []string) ([]model.Attachment, error)
+	ListAttachmentsByMessages(messageIDs []int64) ([]model.Attachment, error)
+	LinkAttachments(attachmentIDs []string, conversationID string, messageID int64) error
+	DeleteAttachmentsByConversation(conversationID string) error
+}
@@ -0,0 +1,65 @@
+package attachment
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type attachmentDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &attachmentDaoImpl{db: db}
+}
+
+func (a *attachmentDaoImpl) CreateAttachment(attachment model.Attachment) error {
+	return a.db.Create(&attachment).Error
+}
+
+func (a *attachmentDaoImpl) GetAttachmentByID(attachmentID string) (*model.Attachment, error) {
+	var entity model.Attachment
+	if err := a.db.Where("uuid = ?", attachmentID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (a *attachmentDaoImpl) ListAttachmentsByIDs(attachmentIDs []string) ([]model.Attachment, error) {
+	var items []model.Attachment
+	if len(attachmentIDs) == 0 {
+		return items, nil
+	}
+	if err := a.db.Where("uuid IN ?", attachmentIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (a *attachmentDaoImpl) ListAttachmentsByMessages(messageIDs []int64) ([]model.Attachment, error) {
+	var items []model.Attachment
+	if len(messageIDs) == 0 {
+		return items, nil
+	}
+	if err := a.db.Where("message_id IN ?", messageIDs).Order("created_at asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (a *attachmentDaoImpl) LinkAttachments(attachmentIDs []string, conversationID string, messageID int64) error {
+	if len(attachmentIDs) == 0 {
+		return nil
+	}
+	return a.db.Model(&model.Attachment{}).
+		Where("uuid IN ? AND message_id = ?", attachmentIDs, 0).
+		Updates(map[string]interface{}{
+			"conversation_id": conversationID,
+			"message_id":      messageID,
+		}).Error
+}
+
+func (a *attachmentDaoImpl) DeleteAttachmentsByConversation(conversationID string) error {
+	return a.db.Where("conversation_id = ?", conversationID).Delete(&model.Attachment{}).Error
+}
@@ -0,0 +1,10 @@
+package channel
+
+import "github.com/im-core-go/im-core-bot-platform/internal/model"
+
+type Dao interface {
+	GetBinding(platform, channelID, threadID, botID string) (*model.ChannelBinding, error)
+	CreateBinding(binding model.ChannelBinding) error
+	ListBindingsByConversation(conversationID string) ([]model.ChannelBinding, error)
+	DeleteBinding(id int64) error
+}
@@ -0,0 +1,44 @@
+package channel
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type channelDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &channelDaoImpl{db: db}
+}
+
+func (c *channelDaoImpl) GetBinding(platform, channelID, threadID, botID string) (*model.ChannelBinding, error) {
+	var entity model.ChannelBinding
+	err := c.db.Where("platform = ? AND channel_id = ? AND thread_id = ? AND bot_id = ?", platform, channelID, threadID, botID).
+		First(&entity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// CreateBinding keeps the existing binding when one was created
+// concurrently; callers re-read to learn which one won.
+func (c *channelDaoImpl) CreateBinding(binding model.ChannelBinding) error {
+	return c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&binding).Error
+}
+
+func (c *channelDaoImpl) ListBindingsByConversation(conversationID string) ([]model.ChannelBinding, error) {
+	var items []model.ChannelBinding
+	if err := c.db.Where("conversation_id = ?", conversationID).Order("id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *channelDaoImpl) DeleteBinding(id int64) error {
+	return c.db.Where("id = ?", id).Delete(&model.ChannelBinding{}).Error
+}
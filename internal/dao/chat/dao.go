@@ -2,18 +2,98 @@ package chat
 
 import "github.com/im-core-go/im-core-bot-platform/internal/model"
 
+// MessageMatch is a message found by search together with the title of its
+// conversation and the relevance score of the match.
+type MessageMatch struct {
+	model.Message
+	Title string
+	Score float64
+}
+
+// ConversationFilter narrows ListConversationsByUser. Zero values do not
+// filter, except Archived: archived conversations are listed only when it is
+// set, and then exclusively.
+type ConversationFilter struct {
+	FolderID    string
+	Tag         string
+	BotID       string
+	Archived    bool
+	UpdatedFrom int64
+	UpdatedTo   int64
+}
+
+// ConversationKey is the position of a conversation in the list order
+// (pinned first, then most recently pinned, then most recently updated).
+type ConversationKey struct {
+	Pinned    bool
+	PinnedAt  int64
+	UpdatedAt int64
+	UUID      string
+}
+
+type TagCount struct {
+	Tag   string
+	Count int64
+}
+
+// ConversationMatch is a conversation whose title matched a search.
+type ConversationMatch struct {
+	model.Conversation
+	Score float64
+}
+
 type Dao interface {
-	CreateConversation(conversation model.Conversation) error
-	UpdateConversation(conversationID string, updateMap map[string]interface{}) error
+	CreateConversation(conversation model.Conversation, events ...model.WebhookEvent) error
+	UpdateConversation(conversationID string, updateMap map[string]interface{}, events ...model.WebhookEvent) error
+	UpdateConversationColumns(conversationID string, updateMap map[string]interface{}) error
 	GetConversationByID(conversationID string) (*model.Conversation, error)
-	ListConversationsByUser(userID string, offset, limit int) ([]model.Conversation, int64, error)
-	DeleteConversation(conversationID string) error
+	ListConversationsByUser(userID string, filter ConversationFilter, offset, limit int) ([]model.Conversation, int64, error)
+	ListConversationsByKey(userID string, filter ConversationFilter, key ConversationKey, before bool, limit int) ([]model.Conversation, error)
+	CountConversationsByUser(userID string, filter ConversationFilter) (int64, error)
+	DeleteConversation(conversationID string, events ...model.WebhookEvent) error
+	GetConversationByImportKey(userID, importKey string) (*model.Conversation, error)
+	ImportConversation(conversation model.Conversation, messages []model.Message) (bool, error)
+	GetTrashedConversationByID(conversationID string) (*model.Conversation, error)
+	ListTrashedConversationsByUser(userID string, offset, limit int) ([]model.Conversation, int64, error)
+	RestoreConversation(conversation model.Conversation) error
+	ListExpiredTrash(before int64, limit int) ([]model.Conversation, error)
+	ListAllMessagesByConversation(conversationID string) ([]model.Message, error)
+	ListAllAttachmentsByConversation(conversationID string) ([]model.Attachment, error)
+	PurgeConversation(conversationID string) error
+
+	CreateFolder(folder model.Folder) error
+	GetFolderByID(folderID string) (*model.Folder, error)
+	ListFoldersByUser(userID string) ([]model.Folder, error)
+	UpdateFolder(folderID string, updateMap map[string]interface{}) error
+	DeleteFolder(folderID string) error
 
-	CreateMessage(message model.Message) error
+	CreateGroupConversation(conversation model.Conversation, participants []model.ConversationParticipant, events ...model.WebhookEvent) error
+	GetParticipant(conversationID, userID string) (*model.ConversationParticipant, error)
+	ListParticipants(conversationID string) ([]model.ConversationParticipant, error)
+	CountParticipants(conversationID string) (int64, error)
+	AddParticipants(participants []model.ConversationParticipant) error
+	UpdateParticipant(conversationID, userID string, updateMap map[string]interface{}) error
+	RemoveParticipant(conversationID, userID string) error
+
+	ReplaceConversationTags(conversationID, userID string, tags []string) error
+	ListTagsByConversations(conversationIDs []string) ([]model.ConversationTag, error)
+	ListTagCountsByUser(userID string) ([]TagCount, error)
+
+	CreateMessage(message model.Message, events ...model.WebhookEvent) error
+	GetMessageByID(messageID int64) (*model.Message, error)
 	ListNonSummaryMessagesAfterSequence(conversationID string, afterSequence int64) ([]model.Message, error)
 	ListRecentNonSummaryMessages(conversationID string, limit int) ([]model.Message, error)
 	ListSummaryMessages(conversationID string, limit int) ([]model.Message, error)
 	GetLastSummary(conversationID string) (*model.Message, error)
 	ListMessagesByConversation(conversationID string, offset, limit int) ([]model.Message, int64, error)
+	ListMessagesBySequence(conversationID string, sequence int64, before bool, limit int) ([]model.Message, error)
+	ListMessagesAfterSequence(conversationID string, afterSequence int64, limit int) ([]model.Message, error)
+	CountMessagesByConversation(conversationID string) (int64, error)
 	DeleteMessagesByConversation(conversationID string) error
+
+	SearchMessages(userID, query string, limit int) ([]MessageMatch, error)
+	SearchConversationTitles(userID, query string, limit int) ([]ConversationMatch, error)
+	ListUserMessagesByIDs(userID string, ids []int64) ([]MessageMatch, error)
+	ListMemberConversationIDs(userID string) ([]string, error)
+	ListConversationMemberIDs(conversationID string) ([]string, error)
 }
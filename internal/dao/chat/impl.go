@@ -1,9 +1,12 @@
 package chat
 
 import (
+	"time"
+
 	"github.com/im-core-go/im-core-bot-platform/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type chatDaoImpl struct {
@@ -14,12 +17,43 @@ func NewDao(db *gorm.DB) Dao {
 	return &chatDaoImpl{db: db}
 }
 
-func (c *chatDaoImpl) CreateConversation(conversation model.Conversation) error {
-	return c.db.Create(&conversation).Error
+func (c *chatDaoImpl) CreateConversation(conversation model.Conversation, events ...model.WebhookEvent) error {
+	return c.withOutbox(events, func(tx *gorm.DB) error {
+		return tx.Create(&conversation).Error
+	})
+}
+
+func (c *chatDaoImpl) UpdateConversation(conversationID string, updateMap map[string]interface{}, events ...model.WebhookEvent) error {
+	return c.withOutbox(events, func(tx *gorm.DB) error {
+		return tx.Model(&model.Conversation{}).Where("uuid = ?", conversationID).Updates(updateMap).Error
+	})
+}
+
+// withOutbox runs write and stores the webhook outbox events in the same
+// transaction, so an event exists exactly when its change was committed.
+func (c *chatDaoImpl) withOutbox(events []model.WebhookEvent, write func(tx *gorm.DB) error) error {
+	if len(events) == 0 {
+		return write(c.db)
+	}
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+		return createOutbox(tx, events)
+	})
+}
+
+func createOutbox(tx *gorm.DB, events []model.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
 }
 
-func (c *chatDaoImpl) UpdateConversation(conversationID string, updateMap map[string]interface{}) error {
-	return c.db.Model(&model.Conversation{}).Where("uuid = ?", conversationID).Updates(updateMap).Error
+// UpdateConversationColumns updates without touching updated_at, for
+// organizational changes that should not reorder the conversation list.
+func (c *chatDaoImpl) UpdateConversationColumns(conversationID string, updateMap map[string]interface{}) error {
+	return c.db.Model(&model.Conversation{}).Where("uuid = ?", conversationID).UpdateColumns(updateMap).Error
 }
 
 func (c *chatDaoImpl) GetConversationByID(conversationID string) (*model.Conversation, error) {
@@ -30,16 +64,184 @@ func (c *chatDaoImpl) GetConversationByID(conversationID string) (*model.Convers
 	return &entity, nil
 }
 
-func (c *chatDaoImpl) DeleteConversation(conversationID string) error {
-	return c.db.Where("uuid = ?", conversationID).Delete(&model.Conversation{}).Error
+// DeleteConversation moves the conversation to the trash together with its
+// live messages and attachments. All rows share one deleted_at stamp, which
+// RestoreConversation uses to tell them apart from rows cleared earlier.
+func (c *chatDaoImpl) DeleteConversation(conversationID string, events ...model.WebhookEvent) error {
+	at := time.Now().Unix()
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Message{}).
+			Where("conversation_id = ?", conversationID).
+			UpdateColumn("deleted_at", at).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Attachment{}).
+			Where("conversation_id = ?", conversationID).
+			UpdateColumn("deleted_at", at).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Conversation{}).
+			Where("uuid = ?", conversationID).
+			UpdateColumn("deleted_at", at).Error; err != nil {
+			return err
+		}
+		return createOutbox(tx, events)
+	})
+}
+
+// GetConversationByImportKey includes trashed conversations, which keep
+// their import key until purged.
+func (c *chatDaoImpl) GetConversationByImportKey(userID, importKey string) (*model.Conversation, error) {
+	var entity model.Conversation
+	if err := c.db.Unscoped().Where("user_id = ? AND import_key = ?", userID, importKey).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// ImportConversation creates a conversation together with its messages so a
+// failed import leaves nothing behind. It reports false, writing nothing,
+// when the user already has a conversation with the same import key.
+func (c *chatDaoImpl) ImportConversation(conversation model.Conversation, messages []model.Message) (bool, error) {
+	created := false
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&conversation)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		created = true
+		if len(messages) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&messages, 200).Error
+	})
+	return created && err == nil, err
+}
+
+func (c *chatDaoImpl) GetTrashedConversationByID(conversationID string) (*model.Conversation, error) {
+	var entity model.Conversation
+	err := c.db.Unscoped().
+		Where("uuid = ? AND deleted_at > 0", conversationID).
+		First(&entity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (c *chatDaoImpl) ListTrashedConversationsByUser(userID string, offset, limit int) ([]model.Conversation, int64, error) {
+	var (
+		items []model.Conversation
+		total int64
+	)
+	query := c.db.Unscoped().Model(&model.Conversation{}).Where("user_id = ? AND deleted_at > 0", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Order("deleted_at desc").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// RestoreConversation undeletes the conversation and the rows trashed with
+// it; messages cleared before the deletion stay deleted.
+func (c *chatDaoImpl) RestoreConversation(conversation model.Conversation) error {
+	at := int64(conversation.DeletedAt)
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Message{}).
+			Where("conversation_id = ? AND deleted_at = ?", conversation.UUID, at).
+			UpdateColumn("deleted_at", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Attachment{}).
+			Where("conversation_id = ? AND deleted_at = ?", conversation.UUID, at).
+			UpdateColumn("deleted_at", 0).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&model.Conversation{}).
+			Where("uuid = ? AND deleted_at = ?", conversation.UUID, at).
+			UpdateColumn("deleted_at", 0).Error
+	})
+}
+
+func (c *chatDaoImpl) ListExpiredTrash(before int64, limit int) ([]model.Conversation, error) {
+	var items []model.Conversation
+	query := c.db.Unscoped().
+		Where("deleted_at > 0 AND deleted_at < ?", before).
+		Order("deleted_at asc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) ListAllMessagesByConversation(conversationID string) ([]model.Message, error) {
+	var items []model.Message
+	if err := c.db.Unscoped().Where("conversation_id = ?", conversationID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) ListAllAttachmentsByConversation(conversationID string) ([]model.Attachment, error) {
+	var items []model.Attachment
+	if err := c.db.Unscoped().Where("conversation_id = ?", conversationID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// PurgeConversation hard-deletes the conversation and everything under it.
+func (c *chatDaoImpl) PurgeConversation(conversationID string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("conversation_id = ?", conversationID).Delete(&model.Message{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("conversation_id = ?", conversationID).Delete(&model.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id = ?", conversationID).Delete(&model.ConversationTag{}).Error; err != nil {
+			return err
+		}
+		shares := tx.Unscoped().Model(&model.Share{}).Select("uuid").Where("conversation_id = ?", conversationID)
+		if err := tx.Where("share_id IN (?)", shares).Delete(&model.ShareMessage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("conversation_id = ?", conversationID).Delete(&model.Share{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id = ?", conversationID).Delete(&model.ConversationParticipant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("conversation_id = ?", conversationID).Delete(&model.Reminder{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id = ?", conversationID).Delete(&model.MessageFeedback{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id = ?", conversationID).Delete(&model.ChannelBinding{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("uuid = ?", conversationID).Delete(&model.Conversation{}).Error
+	})
 }
 
-func (c *chatDaoImpl) ListConversationsByUser(userID string, offset, limit int) ([]model.Conversation, int64, error) {
+func (c *chatDaoImpl) ListConversationsByUser(userID string, filter ConversationFilter, offset, limit int) ([]model.Conversation, int64, error) {
 	var (
 		items []model.Conversation
 		total int64
 	)
-	query := c.db.Model(&model.Conversation{}).Where("user_id = ?", userID)
+	query := c.conversationsByUser(userID, filter)
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -49,14 +251,224 @@ func (c *chatDaoImpl) ListConversationsByUser(userID string, offset, limit int)
 	if offset > 0 {
 		query = query.Offset(offset)
 	}
-	if err := query.Order("updated_at desc").Find(&items).Error; err != nil {
+	if err := query.Order("pinned desc, pinned_at desc, updated_at desc, uuid desc").Find(&items).Error; err != nil {
 		return nil, 0, err
 	}
 	return items, total, nil
 }
 
-func (c *chatDaoImpl) CreateMessage(message model.Message) error {
-	return c.db.Create(&message).Error
+// ListConversationsByKey returns the conversations that follow key in list
+// order, or with before set, the ones preceding it. Results are always in
+// list order.
+func (c *chatDaoImpl) ListConversationsByKey(userID string, filter ConversationFilter, key ConversationKey, before bool, limit int) ([]model.Conversation, error) {
+	var items []model.Conversation
+	cmp, order := "<", "pinned desc, pinned_at desc, updated_at desc, uuid desc"
+	if before {
+		cmp, order = ">", "pinned asc, pinned_at asc, updated_at asc, uuid asc"
+	}
+	query := c.conversationsByUser(userID, filter).
+		Where("(pinned, pinned_at, updated_at, uuid) "+cmp+" (?, ?, ?, ?)", key.Pinned, key.PinnedAt, key.UpdatedAt, key.UUID).
+		Order(order)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if before {
+		reverse(items)
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) CountConversationsByUser(userID string, filter ConversationFilter) (int64, error) {
+	var total int64
+	if err := c.conversationsByUser(userID, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (c *chatDaoImpl) conversationsByUser(userID string, filter ConversationFilter) *gorm.DB {
+	member, args := c.memberOf("uuid", "user_id", userID)
+	query := c.db.Model(&model.Conversation{}).
+		Where(member, args...).
+		Where("archived = ?", filter.Archived)
+	if filter.FolderID != "" {
+		query = query.Where("folder_id = ?", filter.FolderID)
+	}
+	if filter.BotID != "" {
+		query = query.Where("bot_id = ?", filter.BotID)
+	}
+	if filter.Tag != "" {
+		query = query.Where("uuid IN (?)", c.db.Model(&model.ConversationTag{}).
+			Select("conversation_id").
+			Where("user_id = ? AND tag = ?", userID, filter.Tag))
+	}
+	if filter.UpdatedFrom > 0 {
+		query = query.Where("updated_at >= ?", filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo > 0 {
+		query = query.Where("updated_at < ?", filter.UpdatedTo)
+	}
+	return query
+}
+
+func (c *chatDaoImpl) CreateFolder(folder model.Folder) error {
+	return c.db.Create(&folder).Error
+}
+
+func (c *chatDaoImpl) GetFolderByID(folderID string) (*model.Folder, error) {
+	var entity model.Folder
+	if err := c.db.Where("uuid = ?", folderID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (c *chatDaoImpl) ListFoldersByUser(userID string) ([]model.Folder, error) {
+	var items []model.Folder
+	if err := c.db.Where("user_id = ?", userID).Order("name asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) UpdateFolder(folderID string, updateMap map[string]interface{}) error {
+	return c.db.Model(&model.Folder{}).Where("uuid = ?", folderID).Updates(updateMap).Error
+}
+
+// DeleteFolder moves the folder's conversations back to the top level before
+// removing the folder itself.
+func (c *chatDaoImpl) DeleteFolder(folderID string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Conversation{}).Where("folder_id = ?", folderID).
+			Update("folder_id", "").Error; err != nil {
+			return err
+		}
+		return tx.Where("uuid = ?", folderID).Delete(&model.Folder{}).Error
+	})
+}
+
+// CreateGroupConversation creates the conversation and its initial members
+// together.
+func (c *chatDaoImpl) CreateGroupConversation(conversation model.Conversation, participants []model.ConversationParticipant, events ...model.WebhookEvent) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&conversation).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&participants).Error; err != nil {
+			return err
+		}
+		return createOutbox(tx, events)
+	})
+}
+
+func (c *chatDaoImpl) GetParticipant(conversationID, userID string) (*model.ConversationParticipant, error) {
+	var entity model.ConversationParticipant
+	if err := c.db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (c *chatDaoImpl) ListParticipants(conversationID string) ([]model.ConversationParticipant, error) {
+	var items []model.ConversationParticipant
+	if err := c.db.Where("conversation_id = ?", conversationID).Order("id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) CountParticipants(conversationID string) (int64, error) {
+	var total int64
+	if err := c.db.Model(&model.ConversationParticipant{}).Where("conversation_id = ?", conversationID).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// AddParticipants inserts new members; users who are already members keep
+// their role and display name.
+func (c *chatDaoImpl) AddParticipants(participants []model.ConversationParticipant) error {
+	if len(participants) == 0 {
+		return nil
+	}
+	return c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&participants).Error
+}
+
+func (c *chatDaoImpl) UpdateParticipant(conversationID, userID string, updateMap map[string]interface{}) error {
+	return c.db.Model(&model.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Updates(updateMap).Error
+}
+
+func (c *chatDaoImpl) RemoveParticipant(conversationID, userID string) error {
+	return c.db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Delete(&model.ConversationParticipant{}).Error
+}
+
+// memberOf builds a condition matching conversations the user created or is
+// a group member of, given the UUID and creator columns of the outer query.
+func (c *chatDaoImpl) memberOf(uuidColumn, ownerColumn, userID string) (string, []interface{}) {
+	members := c.db.Model(&model.ConversationParticipant{}).Select("conversation_id").Where("user_id = ?", userID)
+	return "(" + ownerColumn + " = ? OR " + uuidColumn + " IN (?))", []interface{}{userID, members}
+}
+
+func (c *chatDaoImpl) ReplaceConversationTags(conversationID, userID string, tags []string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("conversation_id = ?", conversationID).Delete(&model.ConversationTag{}).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		rows := make([]model.ConversationTag, 0, len(tags))
+		for _, tag := range tags {
+			rows = append(rows, model.ConversationTag{UserID: userID, ConversationID: conversationID, Tag: tag})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (c *chatDaoImpl) ListTagsByConversations(conversationIDs []string) ([]model.ConversationTag, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+	var items []model.ConversationTag
+	if err := c.db.Where("conversation_id IN ?", conversationIDs).Order("tag asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) ListTagCountsByUser(userID string) ([]TagCount, error) {
+	var items []TagCount
+	err := c.db.Table("conversation_tag AS t").
+		Select("t.tag AS tag, COUNT(*) AS count").
+		Joins("JOIN conversation AS c ON c.uuid = t.conversation_id").
+		Where("t.user_id = ? AND c.deleted_at = 0", userID).
+		Group("t.tag").
+		Order("t.tag asc").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) GetMessageByID(messageID int64) (*model.Message, error) {
+	var entity model.Message
+	if err := c.db.Where("id = ?", messageID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (c *chatDaoImpl) CreateMessage(message model.Message, events ...model.WebhookEvent) error {
+	return c.withOutbox(events, func(tx *gorm.DB) error {
+		return tx.Create(&message).Error
+	})
 }
 
 func (c *chatDaoImpl) ListNonSummaryMessagesAfterSequence(conversationID string, afterSequence int64) ([]model.Message, error) {
@@ -128,6 +540,147 @@ func (c *chatDaoImpl) ListMessagesByConversation(conversationID string, offset,
 	return items, total, nil
 }
 
+// ListMessagesBySequence returns the messages older than sequence, or with
+// before set, the newer ones. Results are always newest first.
+func (c *chatDaoImpl) ListMessagesBySequence(conversationID string, sequence int64, before bool, limit int) ([]model.Message, error) {
+	var items []model.Message
+	query := c.db.Where("conversation_id = ?", conversationID)
+	if before {
+		query = query.Where("sequence > ?", sequence).Order("sequence asc")
+	} else {
+		query = query.Where("sequence < ?", sequence).Order("sequence desc")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if before {
+		reverse(items)
+	}
+	return items, nil
+}
+
+// ListMessagesAfterSequence walks a conversation oldest first, summaries
+// included.
+func (c *chatDaoImpl) ListMessagesAfterSequence(conversationID string, afterSequence int64, limit int) ([]model.Message, error) {
+	var items []model.Message
+	query := c.db.Where("conversation_id = ? AND sequence > ?", conversationID, afterSequence).Order("sequence asc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) CountMessagesByConversation(conversationID string) (int64, error) {
+	var total int64
+	if err := c.db.Model(&model.Message{}).Where("conversation_id = ?", conversationID).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func reverse[T any](items []T) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
+
 func (c *chatDaoImpl) DeleteMessagesByConversation(conversationID string) error {
 	return c.db.Where("conversation_id = ?", conversationID).Delete(&model.Message{}).Error
 }
+
+const matchMessage = "MATCH(m.content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// userMessages selects the live, non-summary messages of the user's live
+// conversations. Every search query starts from it so results can never
+// leak across users.
+func (c *chatDaoImpl) userMessages(userID string) *gorm.DB {
+	member, args := c.memberOf("c.uuid", "c.user_id", userID)
+	return c.db.Table("message AS m").
+		Joins("JOIN conversation AS c ON c.uuid = m.conversation_id").
+		Where(member, args...).
+		Where("c.deleted_at = 0").
+		Where("m.deleted_at = 0 AND m.is_summary = ?", false)
+}
+
+func (c *chatDaoImpl) SearchMessages(userID, query string, limit int) ([]MessageMatch, error) {
+	var items []MessageMatch
+	err := c.userMessages(userID).
+		Select("m.*, c.title AS title, "+matchMessage+" AS score", query).
+		Where(matchMessage, query).
+		Order("score desc").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *chatDaoImpl) SearchConversationTitles(userID, query string, limit int) ([]ConversationMatch, error) {
+	var items []ConversationMatch
+	const match = "MATCH(title) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	member, args := c.memberOf("uuid", "user_id", userID)
+	err := c.db.Model(&model.Conversation{}).
+		Select("*, "+match+" AS score", query).
+		Where(member, args...).
+		Where(match, query).
+		Order("score desc").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListMemberConversationIDs returns the live conversations the user created
+// or is a group member of.
+func (c *chatDaoImpl) ListMemberConversationIDs(userID string) ([]string, error) {
+	var ids []string
+	member, args := c.memberOf("uuid", "user_id", userID)
+	if err := c.db.Model(&model.Conversation{}).Where(member, args...).Pluck("uuid", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListConversationMemberIDs returns the creator of a conversation followed by
+// its other group members.
+func (c *chatDaoImpl) ListConversationMemberIDs(conversationID string) ([]string, error) {
+	var owners []string
+	if err := c.db.Unscoped().Model(&model.Conversation{}).Where("uuid = ?", conversationID).Pluck("user_id", &owners).Error; err != nil {
+		return nil, err
+	}
+	var members []string
+	if err := c.db.Model(&model.ConversationParticipant{}).Where("conversation_id = ?", conversationID).Order("id asc").Pluck("user_id", &members).Error; err != nil {
+		return nil, err
+	}
+	ids := owners
+	for _, id := range members {
+		if len(owners) == 0 || id != owners[0] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (c *chatDaoImpl) ListUserMessagesByIDs(userID string, ids []int64) ([]MessageMatch, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var items []MessageMatch
+	err := c.userMessages(userID).
+		Select("m.*, c.title AS title").
+		Where("m.id IN ?", ids).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -1,17 +1,44 @@
 package dao
 
 import (
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/attachment"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/channel"
 	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/experiment"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/feedback"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/knowledge"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/reminder"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/share"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/vector"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/webhook"
 
 	"gorm.io/gorm"
 )
 
 type Dao struct {
-	ChatDao chat.Dao
+	ChatDao       chat.Dao
+	AttachmentDao attachment.Dao
+	KnowledgeDao  knowledge.Dao
+	VectorDao     vector.Dao
+	ShareDao      share.Dao
+	ChannelDao    channel.Dao
+	WebhookDao    webhook.Dao
+	ReminderDao   reminder.Dao
+	FeedbackDao   feedback.Dao
+	ExperimentDao experiment.Dao
 }
 
 func NewDao(db *gorm.DB) *Dao {
 	return &Dao{
-		ChatDao: chat.NewDao(db),
+		ChatDao:       chat.NewDao(db),
+		AttachmentDao: attachment.NewDao(db),
+		KnowledgeDao:  knowledge.NewDao(db),
+		VectorDao:     vector.NewDao(db),
+		ShareDao:      share.NewDao(db),
+		ChannelDao:    channel.NewDao(db),
+		WebhookDao:    webhook.NewDao(db),
+		ReminderDao:   reminder.NewDao(db),
+		FeedbackDao:   feedback.NewDao(db),
+		ExperimentDao: experiment.NewDao(db),
 	}
 }
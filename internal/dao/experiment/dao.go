@@ -0,0 +1,19 @@
+package experiment
+
+// VariantStat sums up one variant's assistant replies and their ratings.
+type VariantStat struct {
+	Variant          string
+	Replies          int64
+	AvgLatencyMs     float64
+	AvgFirstTokenMs  float64
+	PromptTokens     int64
+	CompletionTokens int64
+	Up               int64
+	Down             int64
+}
+
+type Dao interface {
+	// AggregateVariants groups the experiment's replies by variant; from
+	// and to bound the reply time when set, to exclusive.
+	AggregateVariants(experimentID string, from, to int64) ([]VariantStat, error)
+}
@@ -0,0 +1,45 @@
+package experiment
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type experimentDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &experimentDaoImpl{db: db}
+}
+
+// AggregateVariants joins replies with their ratings summed per message
+// first, so a reply rated by several group members counts once as a reply.
+func (e *experimentDaoImpl) AggregateVariants(experimentID string, from, to int64) ([]VariantStat, error) {
+	ratings := e.db.Model(&model.MessageFeedback{}).
+		Select("message_id, SUM(CASE WHEN rating > 0 THEN 1 ELSE 0 END) AS up, SUM(CASE WHEN rating < 0 THEN 1 ELSE 0 END) AS down").
+		Group("message_id")
+	query := e.db.Model(&model.Message{}).
+		Select(`message.variant AS variant,
+			COUNT(*) AS replies,
+			COALESCE(AVG(NULLIF(message.latency_ms, 0)), 0) AS avg_latency_ms,
+			COALESCE(AVG(NULLIF(message.first_token_ms, 0)), 0) AS avg_first_token_ms,
+			COALESCE(SUM(message.prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(message.completion_tokens), 0) AS completion_tokens,
+			COALESCE(SUM(f.up), 0) AS up,
+			COALESCE(SUM(f.down), 0) AS down`).
+		Joins("LEFT JOIN (?) AS f ON f.message_id = message.id", ratings).
+		Where("message.experiment = ? AND message.role = ?", experimentID, "assistant")
+	if from > 0 {
+		query = query.Where("message.created_at >= ?", from)
+	}
+	if to > 0 {
+		query = query.Where("message.created_at < ?", to)
+	}
+	var items []VariantStat
+	if err := query.Group("message.variant").Order("message.variant").Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
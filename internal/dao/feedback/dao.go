@@ -0,0 +1,42 @@
+package feedback
+
+import "github.com/im-core-go/im-core-bot-platform/internal/model"
+
+// Dimensions the statistics can be broken down by.
+const (
+	GroupByBot           = "bot"
+	GroupByModel         = "model"
+	GroupByPromptVersion = "prompt_version"
+	GroupByDate          = "date"
+)
+
+// StatsFilter narrows AggregateFeedback; zero values do not filter. From
+// and To bound the rating time in unix seconds, To exclusive.
+type StatsFilter struct {
+	BotID         string
+	Model         string
+	PromptVersion string
+	From          int64
+	To            int64
+}
+
+// Stat counts the ratings in one group. Only the grouped dimensions are set;
+// Date is the day the ratings were made, YYYY-MM-DD in UTC.
+type Stat struct {
+	BotID         string
+	Model         string
+	PromptVersion string
+	Date          string
+	Up            int64
+	Down          int64
+}
+
+type Dao interface {
+	// UpsertFeedback creates the user's feedback on the message or replaces
+	// it.
+	UpsertFeedback(feedback model.MessageFeedback) error
+	DeleteFeedback(messageID int64, userID string) error
+	GetFeedback(messageID int64, userID string) (*model.MessageFeedback, error)
+	ListFeedbackByMessages(userID string, messageIDs []int64) ([]model.MessageFeedback, error)
+	AggregateFeedback(filter StatsFilter, groupBy []string) ([]Stat, error)
+}
@@ -0,0 +1,113 @@
+package feedback
+
+import (
+	"fmt"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// groupColumns maps each dimension to the expression it groups by and the
+// column it is read back as.
+var groupColumns = map[string]struct{ expr, alias string }{
+	GroupByBot:           {"bot_id", "bot_id"},
+	GroupByModel:         {"model", "model"},
+	GroupByPromptVersion: {"prompt_version", "prompt_version"},
+	GroupByDate:          {"DATE_FORMAT(DATE_ADD('1970-01-01', INTERVAL created_at SECOND), '%Y-%m-%d')", "date"},
+}
+
+type feedbackDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &feedbackDaoImpl{db: db}
+}
+
+func (f *feedbackDaoImpl) UpsertFeedback(feedback model.MessageFeedback) error {
+	return f.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "message_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"rating", "reasons", "comment", "bot_id", "model", "prompt_version", "updated_at",
+		}),
+	}).Create(&feedback).Error
+}
+
+func (f *feedbackDaoImpl) DeleteFeedback(messageID int64, userID string) error {
+	return f.db.Where("message_id = ? AND user_id = ?", messageID, userID).Delete(&model.MessageFeedback{}).Error
+}
+
+func (f *feedbackDaoImpl) GetFeedback(messageID int64, userID string) (*model.MessageFeedback, error) {
+	var entity model.MessageFeedback
+	if err := f.db.Where("message_id = ? AND user_id = ?", messageID, userID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (f *feedbackDaoImpl) ListFeedbackByMessages(userID string, messageIDs []int64) ([]model.MessageFeedback, error) {
+	var items []model.MessageFeedback
+	if len(messageIDs) == 0 {
+		return items, nil
+	}
+	if err := f.db.Where("user_id = ? AND message_id IN ?", userID, messageIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (f *feedbackDaoImpl) AggregateFeedback(filter StatsFilter, groupBy []string) ([]Stat, error) {
+	selects := make([]string, 0, len(groupBy)+2)
+	groups := make([]string, 0, len(groupBy))
+	for _, g := range groupBy {
+		col, ok := groupColumns[g]
+		if !ok {
+			return nil, fmt.Errorf("unknown group %q", g)
+		}
+		selects = append(selects, col.expr+" AS "+col.alias)
+		groups = append(groups, col.alias)
+	}
+	selects = append(selects,
+		"COALESCE(SUM(CASE WHEN rating > 0 THEN 1 ELSE 0 END), 0) AS up",
+		"COALESCE(SUM(CASE WHEN rating < 0 THEN 1 ELSE 0 END), 0) AS down",
+	)
+
+	query := f.db.Model(&model.MessageFeedback{}).Select(selects)
+	if filter.BotID != "" {
+		query = query.Where("bot_id = ?", filter.BotID)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.PromptVersion != "" {
+		query = query.Where("prompt_version = ?", filter.PromptVersion)
+	}
+	if filter.From > 0 {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if filter.To > 0 {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	for _, g := range groups {
+		query = query.Group(g).Order(g)
+	}
+
+	var rows []struct {
+		BotID         string
+		Model         string
+		PromptVersion string
+		Date          string
+		Up            int64
+		Down          int64
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	stats := make([]Stat, 0, len(rows))
+	for _, r := range rows {
+		stats = append(stats, Stat(r))
+	}
+	return stats, nil
+}
@@ -0,0 +1,24 @@
+package knowledge
+
+import "github.com/im-core-go/im-core-bot-platform/internal/model"
+
+type Dao interface {
+	CreateKnowledgeBase(kb model.KnowledgeBase) error
+	GetKnowledgeBaseByID(kbID string) (*model.KnowledgeBase, error)
+	ListKnowledgeBasesByBot(botID string) ([]model.KnowledgeBase, error)
+	ListKnowledgeBasesByOwner(ownerID string) ([]model.KnowledgeBase, error)
+	DeleteKnowledgeBase(kbID string) error
+
+	CreateDocument(doc model.KnowledgeDocument) error
+	UpdateDocument(docID string, updateMap map[string]interface{}) error
+	GetDocumentByID(docID string) (*model.KnowledgeDocument, error)
+	ListDocumentsByKnowledgeBase(kbID string, offset, limit int) ([]model.KnowledgeDocument, int64, error)
+	DeleteDocument(docID string) error
+	DeleteDocumentsByKnowledgeBase(kbID string) error
+
+	CreateChunks(chunks []model.KnowledgeChunk) error
+	ListChunksByIDs(chunkIDs []int64) ([]model.KnowledgeChunk, error)
+	ListChunkIDsByDocument(docID string) ([]int64, error)
+	DeleteChunksByDocument(docID string) error
+	DeleteChunksByKnowledgeBase(kbID string) error
+}
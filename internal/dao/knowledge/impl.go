@@ -0,0 +1,126 @@
+package knowledge
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type knowledgeDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &knowledgeDaoImpl{db: db}
+}
+
+func (k *knowledgeDaoImpl) CreateKnowledgeBase(kb model.KnowledgeBase) error {
+	return k.db.Create(&kb).Error
+}
+
+func (k *knowledgeDaoImpl) GetKnowledgeBaseByID(kbID string) (*model.KnowledgeBase, error) {
+	var entity model.KnowledgeBase
+	if err := k.db.Where("uuid = ?", kbID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (k *knowledgeDaoImpl) ListKnowledgeBasesByBot(botID string) ([]model.KnowledgeBase, error) {
+	var items []model.KnowledgeBase
+	if err := k.db.Where("bot_id = ?", botID).Order("created_at asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (k *knowledgeDaoImpl) ListKnowledgeBasesByOwner(ownerID string) ([]model.KnowledgeBase, error) {
+	var items []model.KnowledgeBase
+	if err := k.db.Where("owner_id = ?", ownerID).Order("created_at desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (k *knowledgeDaoImpl) DeleteKnowledgeBase(kbID string) error {
+	return k.db.Where("uuid = ?", kbID).Delete(&model.KnowledgeBase{}).Error
+}
+
+func (k *knowledgeDaoImpl) CreateDocument(doc model.KnowledgeDocument) error {
+	return k.db.Create(&doc).Error
+}
+
+func (k *knowledgeDaoImpl) UpdateDocument(docID string, updateMap map[string]interface{}) error {
+	return k.db.Model(&model.KnowledgeDocument{}).Where("uuid = ?", docID).Updates(updateMap).Error
+}
+
+func (k *knowledgeDaoImpl) GetDocumentByID(docID string) (*model.KnowledgeDocument, error) {
+	var entity model.KnowledgeDocument
+	if err := k.db.Where("uuid = ?", docID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (k *knowledgeDaoImpl) ListDocumentsByKnowledgeBase(kbID string, offset, limit int) ([]model.KnowledgeDocument, int64, error) {
+	var (
+		items []model.KnowledgeDocument
+		total int64
+	)
+	query := k.db.Model(&model.KnowledgeDocument{}).Where("knowledge_base_id = ?", kbID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Order("created_at desc").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (k *knowledgeDaoImpl) DeleteDocument(docID string) error {
+	return k.db.Where("uuid = ?", docID).Delete(&model.KnowledgeDocument{}).Error
+}
+
+func (k *knowledgeDaoImpl) DeleteDocumentsByKnowledgeBase(kbID string) error {
+	return k.db.Where("knowledge_base_id = ?", kbID).Delete(&model.KnowledgeDocument{}).Error
+}
+
+func (k *knowledgeDaoImpl) CreateChunks(chunks []model.KnowledgeChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	return k.db.CreateInBatches(chunks, 200).Error
+}
+
+func (k *knowledgeDaoImpl) ListChunksByIDs(chunkIDs []int64) ([]model.KnowledgeChunk, error) {
+	var items []model.KnowledgeChunk
+	if len(chunkIDs) == 0 {
+		return items, nil
+	}
+	if err := k.db.Where("id IN ?", chunkIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (k *knowledgeDaoImpl) ListChunkIDsByDocument(docID string) ([]int64, error) {
+	var ids []int64
+	if err := k.db.Model(&model.KnowledgeChunk{}).Where("document_id = ?", docID).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (k *knowledgeDaoImpl) DeleteChunksByDocument(docID string) error {
+	return k.db.Where("document_id = ?", docID).Delete(&model.KnowledgeChunk{}).Error
+}
+
+func (k *knowledgeDaoImpl) DeleteChunksByKnowledgeBase(kbID string) error {
+	return k.db.Where("knowledge_base_id = ?", kbID).Delete(&model.KnowledgeChunk{}).Error
+}
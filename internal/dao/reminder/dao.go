@@ -0,0 +1,15 @@
+package reminder
+
+import "github.com/im-core-go/im-core-bot-platform/internal/model"
+
+type Dao interface {
+	CreateReminder(reminder model.Reminder) error
+	GetReminder(reminderID string) (*model.Reminder, error)
+	ListReminders(userID, conversationID string, activeOnly bool) ([]model.Reminder, error)
+	CountActiveReminders(userID string) (int64, error)
+	ListDueReminders(now int64, limit int) ([]model.Reminder, error)
+	// ClaimRun applies fields only if the reminder is still active and due
+	// at runAt, and reports whether it did.
+	ClaimRun(reminderID string, runAt int64, fields map[string]interface{}) (bool, error)
+	UpdateReminder(reminderID string, fields map[string]interface{}) error
+}
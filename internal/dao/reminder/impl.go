@@ -0,0 +1,76 @@
+package reminder
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type reminderDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &reminderDaoImpl{db: db}
+}
+
+func (r *reminderDaoImpl) CreateReminder(reminder model.Reminder) error {
+	return r.db.Create(&reminder).Error
+}
+
+func (r *reminderDaoImpl) GetReminder(reminderID string) (*model.Reminder, error) {
+	var entity model.Reminder
+	if err := r.db.Where("uuid = ?", reminderID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// ListReminders lists the user's reminders by next run, optionally narrowed
+// to one conversation.
+func (r *reminderDaoImpl) ListReminders(userID, conversationID string, activeOnly bool) ([]model.Reminder, error) {
+	var items []model.Reminder
+	query := r.db.Where("user_id = ?", userID)
+	if conversationID != "" {
+		query = query.Where("conversation_id = ?", conversationID)
+	}
+	if activeOnly {
+		query = query.Where("status = ?", model.ReminderStatusActive)
+	}
+	if err := query.Order("next_run_at asc, uuid asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *reminderDaoImpl) CountActiveReminders(userID string) (int64, error) {
+	var total int64
+	err := r.db.Model(&model.Reminder{}).
+		Where("user_id = ? AND status = ?", userID, model.ReminderStatusActive).
+		Count(&total).Error
+	return total, err
+}
+
+func (r *reminderDaoImpl) ListDueReminders(now int64, limit int) ([]model.Reminder, error) {
+	var items []model.Reminder
+	query := r.db.Where("status = ? AND next_run_at <= ?", model.ReminderStatusActive, now).
+		Order("next_run_at asc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *reminderDaoImpl) ClaimRun(reminderID string, runAt int64, fields map[string]interface{}) (bool, error) {
+	res := r.db.Model(&model.Reminder{}).
+		Where("uuid = ? AND status = ? AND next_run_at = ?", reminderID, model.ReminderStatusActive, runAt).
+		Updates(fields)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *reminderDaoImpl) UpdateReminder(reminderID string, fields map[string]interface{}) error {
+	return r.db.Model(&model.Reminder{}).Where("uuid = ?", reminderID).Updates(fields).Error
+}
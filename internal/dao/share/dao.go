@@ -0,0 +1,12 @@
+package share
+
+import "github.com/im-core-go/im-core-bot-platform/internal/model"
+
+type Dao interface {
+	CreateShare(share model.Share, messages []model.ShareMessage) error
+	GetShareByID(shareID string) (*model.Share, error)
+	GetShareByToken(token string) (*model.Share, error)
+	ListSharesByUser(userID, conversationID string) ([]model.Share, error)
+	RevokeShare(shareID string, at int64) error
+	ListShareMessages(shareID string) ([]model.ShareMessage, error)
+}
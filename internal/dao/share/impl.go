@@ -0,0 +1,73 @@
+package share
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type shareDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &shareDaoImpl{db: db}
+}
+
+// CreateShare stores the share and its frozen messages together so a share
+// is never visible half-written.
+func (s *shareDaoImpl) CreateShare(share model.Share, messages []model.ShareMessage) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&share).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&messages, 200).Error
+	})
+}
+
+func (s *shareDaoImpl) GetShareByID(shareID string) (*model.Share, error) {
+	var entity model.Share
+	if err := s.db.Where("uuid = ?", shareID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *shareDaoImpl) GetShareByToken(token string) (*model.Share, error) {
+	var entity model.Share
+	if err := s.db.Where("token = ?", token).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// ListSharesByUser lists the user's shares, newest first, optionally
+// narrowed to one conversation.
+func (s *shareDaoImpl) ListSharesByUser(userID, conversationID string) ([]model.Share, error) {
+	var items []model.Share
+	query := s.db.Where("user_id = ?", userID)
+	if conversationID != "" {
+		query = query.Where("conversation_id = ?", conversationID)
+	}
+	if err := query.Order("created_at desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *shareDaoImpl) RevokeShare(shareID string, at int64) error {
+	return s.db.Model(&model.Share{}).
+		Where("uuid = ? AND revoked_at = 0", shareID).
+		Update("revoked_at", at).Error
+}
+
+func (s *shareDaoImpl) ListShareMessages(shareID string) ([]model.ShareMessage, error) {
+	var items []model.ShareMessage
+	if err := s.db.Where("share_id = ?", shareID).Order("position asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,15 @@
+package vector
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
+)
+
+type Dao interface {
+	LoadNamespace(ctx context.Context, namespace string) ([]vector.Record, error)
+	SaveRecords(ctx context.Context, namespace string, records []vector.Record) error
+	DeleteRecords(ctx context.Context, namespace string, ids []string) error
+	DeleteNamespace(ctx context.Context, namespace string) error
+	DeleteScope(ctx context.Context, scope string) error
+}
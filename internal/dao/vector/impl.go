@@ -0,0 +1,84 @@
+package vector
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type vectorDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &vectorDaoImpl{db: db}
+}
+
+func (v *vectorDaoImpl) LoadNamespace(ctx context.Context, namespace string) ([]vector.Record, error) {
+	var rows []model.VectorRecord
+	if err := v.db.WithContext(ctx).Where("namespace = ?", namespace).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	records := make([]vector.Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, vector.Record{ID: row.RecordID, Vector: decodeVector(row.Vector), Scope: row.Scope})
+	}
+	return records, nil
+}
+
+func (v *vectorDaoImpl) SaveRecords(ctx context.Context, namespace string, records []vector.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	rows := make([]model.VectorRecord, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, model.VectorRecord{
+			Namespace: namespace,
+			RecordID:  r.ID,
+			Scope:     r.Scope,
+			Dim:       len(r.Vector),
+			Vector:    encodeVector(r.Vector),
+		})
+	}
+	return v.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "namespace"}, {Name: "record_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scope", "dim", "vector", "updated_at"}),
+	}).CreateInBatches(rows, 200).Error
+}
+
+func (v *vectorDaoImpl) DeleteRecords(ctx context.Context, namespace string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return v.db.WithContext(ctx).Where("namespace = ? AND record_id IN ?", namespace, ids).Delete(&model.VectorRecord{}).Error
+}
+
+func (v *vectorDaoImpl) DeleteNamespace(ctx context.Context, namespace string) error {
+	return v.db.WithContext(ctx).Where("namespace = ?", namespace).Delete(&model.VectorRecord{}).Error
+}
+
+func (v *vectorDaoImpl) DeleteScope(ctx context.Context, scope string) error {
+	return v.db.WithContext(ctx).Where("scope = ?", scope).Delete(&model.VectorRecord{}).Error
+}
+
+func encodeVector(v []float32) []byte {
+	out := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(out[4*i:], math.Float32bits(x))
+	}
+	return out
+}
+
+func decodeVector(b []byte) []float32 {
+	out := make([]float32, len(b)/4)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return out
+}
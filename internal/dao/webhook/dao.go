@@ -0,0 +1,27 @@
+package webhook
+
+import "github.com/im-core-go/im-core-bot-platform/internal/model"
+
+type Dao interface {
+	CreateSubscription(subscription model.WebhookSubscription) error
+	GetSubscription(subscriptionID string) (*model.WebhookSubscription, error)
+	ListSubscriptions() ([]model.WebhookSubscription, error)
+	ListEnabledSubscriptions() ([]model.WebhookSubscription, error)
+	DeleteSubscription(subscriptionID string) error
+
+	ListPendingEvents(limit int) ([]model.WebhookEvent, error)
+	GetEvent(eventID int64) (*model.WebhookEvent, error)
+	ConversationBotID(conversationID string) (string, error)
+	DispatchEvent(eventID int64, botID string, deliveries []model.WebhookDelivery) (bool, error)
+
+	ClaimDueDeliveries(now, leaseUntil int64, limit int) ([]model.WebhookDelivery, error)
+	GetDelivery(deliveryID int64) (*model.WebhookDelivery, error)
+	UpdateDelivery(deliveryID int64, fields map[string]interface{}) error
+	ListDeliveries(filter DeliveryFilter, offset, limit int) ([]model.WebhookDelivery, int64, error)
+}
+
+// DeliveryFilter narrows ListDeliveries; empty fields match everything.
+type DeliveryFilter struct {
+	SubscriptionID string
+	Status         string
+}
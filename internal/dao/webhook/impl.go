@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type webhookDaoImpl struct {
+	db *gorm.DB
+}
+
+func NewDao(db *gorm.DB) Dao {
+	return &webhookDaoImpl{db: db}
+}
+
+func (w *webhookDaoImpl) CreateSubscription(subscription model.WebhookSubscription) error {
+	return w.db.Create(&subscription).Error
+}
+
+func (w *webhookDaoImpl) GetSubscription(subscriptionID string) (*model.WebhookSubscription, error) {
+	var entity model.WebhookSubscription
+	if err := w.db.Where("uuid = ?", subscriptionID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (w *webhookDaoImpl) ListSubscriptions() ([]model.WebhookSubscription, error) {
+	var items []model.WebhookSubscription
+	if err := w.db.Order("created_at desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (w *webhookDaoImpl) ListEnabledSubscriptions() ([]model.WebhookSubscription, error) {
+	var items []model.WebhookSubscription
+	if err := w.db.Where("enabled = ?", true).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (w *webhookDaoImpl) DeleteSubscription(subscriptionID string) error {
+	return w.db.Where("uuid = ?", subscriptionID).Delete(&model.WebhookSubscription{}).Error
+}
+
+func (w *webhookDaoImpl) ListPendingEvents(limit int) ([]model.WebhookEvent, error) {
+	var items []model.WebhookEvent
+	query := w.db.Where("dispatched = ?", false).Order("id asc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (w *webhookDaoImpl) GetEvent(eventID int64) (*model.WebhookEvent, error) {
+	var entity model.WebhookEvent
+	if err := w.db.Where("id = ?", eventID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// ConversationBotID looks through the trash as well, since deletion events
+// are dispatched after the conversation has been soft-deleted. A purged
+// conversation yields an empty bot ID.
+func (w *webhookDaoImpl) ConversationBotID(conversationID string) (string, error) {
+	var botIDs []string
+	err := w.db.Unscoped().Model(&model.Conversation{}).
+		Where("uuid = ?", conversationID).
+		Limit(1).
+		Pluck("bot_id", &botIDs).Error
+	if err != nil || len(botIDs) == 0 {
+		return "", err
+	}
+	return botIDs[0], nil
+}
+
+// DispatchEvent marks the event dispatched and creates its deliveries in
+// one transaction. It reports false, creating nothing, when another worker
+// dispatched the event first.
+func (w *webhookDaoImpl) DispatchEvent(eventID int64, botID string, deliveries []model.WebhookDelivery) (bool, error) {
+	dispatched := false
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.WebhookEvent{}).
+			Where("id = ? AND dispatched = ?", eventID, false).
+			Updates(map[string]interface{}{"dispatched": true, "bot_id": botID})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		dispatched = true
+		if len(deliveries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&deliveries, 200).Error
+	})
+	return dispatched, err
+}
+
+// ClaimDueDeliveries leases due deliveries by moving next_attempt_at to
+// leaseUntil. The update is conditional on the value just read, so each
+// delivery is claimed by one worker; if that worker dies the lease expires
+// and the delivery becomes due again.
+func (w *webhookDaoImpl) ClaimDueDeliveries(now, leaseUntil int64, limit int) ([]model.WebhookDelivery, error) {
+	var due []model.WebhookDelivery
+	query := w.db.Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+		Order("next_attempt_at asc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&due).Error; err != nil {
+		return nil, err
+	}
+	claimed := make([]model.WebhookDelivery, 0, len(due))
+	for _, d := range due {
+		res := w.db.Model(&model.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", d.ID, model.WebhookDeliveryPending, d.NextAttemptAt).
+			Update("next_attempt_at", leaseUntil)
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		if res.RowsAffected == 1 {
+			d.NextAttemptAt = leaseUntil
+			claimed = append(claimed, d)
+		}
+	}
+	return claimed, nil
+}
+
+func (w *webhookDaoImpl) GetDelivery(deliveryID int64) (*model.WebhookDelivery, error) {
+	var entity model.WebhookDelivery
+	if err := w.db.Where("id = ?", deliveryID).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (w *webhookDaoImpl) UpdateDelivery(deliveryID int64, fields map[string]interface{}) error {
+	return w.db.Model(&model.WebhookDelivery{}).Where("id = ?", deliveryID).Updates(fields).Error
+}
+
+func (w *webhookDaoImpl) ListDeliveries(filter DeliveryFilter, offset, limit int) ([]model.WebhookDelivery, int64, error) {
+	query := w.db.Model(&model.WebhookDelivery{})
+	if filter.SubscriptionID != "" {
+		query = query.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []model.WebhookDelivery
+	if err := query.Order("id desc").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
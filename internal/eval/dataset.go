@@ -0,0 +1,102 @@
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Case is one conversation. Its turns are sent in order to a fresh
+// conversation and each reply is scored by the turn's graders; a turn
+// without graders only sets up context.
+type Case struct {
+	ID    string `json:"id" yaml:"id"`
+	BotID string `json:"bot_id" yaml:"bot_id"`
+	Turns []Turn `json:"turns" yaml:"turns"`
+}
+
+type Turn struct {
+	User    string       `json:"user" yaml:"user"`
+	Graders []GraderSpec `json:"graders" yaml:"graders"`
+}
+
+// GraderSpec configures one grader; which fields apply depends on Type.
+type GraderSpec struct {
+	Type string `json:"type" yaml:"type"`
+	// Expected is the reply an exact grader wants, compared after trimming.
+	Expected   string `json:"expected" yaml:"expected"`
+	IgnoreCase bool   `json:"ignore_case" yaml:"ignore_case"`
+	// Pattern is the regex a regex grader wants the reply to match.
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Schema is the JSON schema a json_schema grader validates the reply
+	// against.
+	Schema map[string]any `json:"schema" yaml:"schema"`
+	// Rubric tells a judge grader what a good reply is; Threshold is the
+	// score between 0 and 1 it needs to pass.
+	Rubric    string  `json:"rubric" yaml:"rubric"`
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+}
+
+// LoadDataset reads cases from a YAML file holding a list of cases, or from
+// a JSONL file with one case per line.
+func LoadDataset(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cases []Case
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cases); err != nil {
+			return nil, err
+		}
+	case ".jsonl":
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+			var c Case
+			if err := json.Unmarshal(text, &c); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			cases = append(cases, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported dataset format %q", filepath.Ext(path))
+	}
+	if len(cases) == 0 {
+		return nil, errors.New("empty dataset")
+	}
+	ids := map[string]bool{}
+	for i, c := range cases {
+		if c.ID == "" {
+			return nil, fmt.Errorf("case %d has no id", i+1)
+		}
+		if ids[c.ID] {
+			return nil, fmt.Errorf("duplicate case %q", c.ID)
+		}
+		ids[c.ID] = true
+		if len(c.Turns) == 0 {
+			return nil, fmt.Errorf("case %s has no turns", c.ID)
+		}
+		for j, t := range c.Turns {
+			if strings.TrimSpace(t.User) == "" {
+				return nil, fmt.Errorf("case %s turn %d has no user message", c.ID, j+1)
+			}
+		}
+	}
+	return cases, nil
+}
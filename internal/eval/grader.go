@@ -0,0 +1,148 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	GraderExact      = "exact"
+	GraderRegex      = "regex"
+	GraderJSONSchema = "json_schema"
+	GraderJudge      = "judge"
+)
+
+// Sample is one reply to grade.
+type Sample struct {
+	CaseID   string
+	Turn     int
+	Question string
+	Reply    string
+}
+
+// Grade is a grader's verdict. Score is between 0 and 1; graders without
+// partial credit score 0 or 1.
+type Grade struct {
+	Grader string  `json:"grader"`
+	Pass   bool    `json:"pass"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+type Grader interface {
+	Grade(ctx context.Context, s Sample) (Grade, error)
+}
+
+// GraderFactory builds a grader from its spec, rejecting a spec it cannot
+// run so a broken dataset fails before any model is called.
+type GraderFactory func(spec GraderSpec) (Grader, error)
+
+// Graders maps grader types to factories. Register adds or replaces one.
+type Graders struct {
+	factories map[string]GraderFactory
+}
+
+func NewGraders() *Graders {
+	g := &Graders{factories: map[string]GraderFactory{}}
+	g.Register(GraderExact, newExactGrader)
+	g.Register(GraderRegex, newRegexGrader)
+	g.Register(GraderJSONSchema, newJSONSchemaGrader)
+	return g
+}
+
+func (g *Graders) Register(typ string, factory GraderFactory) {
+	g.factories[typ] = factory
+}
+
+func (g *Graders) Build(spec GraderSpec) (Grader, error) {
+	factory, ok := g.factories[spec.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown grader %q", spec.Type)
+	}
+	return factory(spec)
+}
+
+type exactGrader struct {
+	expected   string
+	ignoreCase bool
+}
+
+func newExactGrader(spec GraderSpec) (Grader, error) {
+	return &exactGrader{expected: strings.TrimSpace(spec.Expected), ignoreCase: spec.IgnoreCase}, nil
+}
+
+func (g *exactGrader) Grade(_ context.Context, s Sample) (Grade, error) {
+	reply := strings.TrimSpace(s.Reply)
+	pass := reply == g.expected || (g.ignoreCase && strings.EqualFold(reply, g.expected))
+	return verdict(GraderExact, pass, ""), nil
+}
+
+type regexGrader struct {
+	re *regexp.Regexp
+}
+
+func newRegexGrader(spec GraderSpec) (Grader, error) {
+	if spec.Pattern == "" {
+		return nil, errors.New("regex grader needs a pattern")
+	}
+	pattern := spec.Pattern
+	if spec.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &regexGrader{re: re}, nil
+}
+
+func (g *regexGrader) Grade(_ context.Context, s Sample) (Grade, error) {
+	return verdict(GraderRegex, g.re.MatchString(s.Reply), ""), nil
+}
+
+type jsonSchemaGrader struct {
+	schema map[string]any
+}
+
+func newJSONSchemaGrader(spec GraderSpec) (Grader, error) {
+	if len(spec.Schema) == 0 {
+		return nil, errors.New("json_schema grader needs a schema")
+	}
+	return &jsonSchemaGrader{schema: spec.Schema}, nil
+}
+
+func (g *jsonSchemaGrader) Grade(_ context.Context, s Sample) (Grade, error) {
+	var v any
+	if err := json.Unmarshal([]byte(stripCodeFence(s.Reply)), &v); err != nil {
+		return verdict(GraderJSONSchema, false, "invalid json: "+err.Error()), nil
+	}
+	if err := validateSchema(g.schema, v, "$"); err != nil {
+		return verdict(GraderJSONSchema, false, err.Error()), nil
+	}
+	return verdict(GraderJSONSchema, true, ""), nil
+}
+
+func verdict(grader string, pass bool, detail string) Grade {
+	g := Grade{Grader: grader, Pass: pass, Detail: detail}
+	if pass {
+		g.Score = 1
+	}
+	return g
+}
+
+// stripCodeFence unwraps a reply the model put in a ``` block.
+func stripCodeFence(reply string) string {
+	s := strings.TrimSpace(reply)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```")
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+}
@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+)
+
+const (
+	defaultJudgeThreshold = 0.7
+
+	judgePrompt = "You grade replies of an assistant. Given a rubric, the user's message and the reply, " +
+		"decide how well the reply meets the rubric. Answer with JSON only: " +
+		`{"score": <number between 0 and 1>, "reason": "<one sentence>"}.`
+)
+
+// JudgeGraders returns the factory of LLM-as-judge graders asking model
+// through completer.
+func JudgeGraders(completer chat.Completer, model string) GraderFactory {
+	return func(spec GraderSpec) (Grader, error) {
+		if strings.TrimSpace(spec.Rubric) == "" {
+			return nil, errors.New("judge grader needs a rubric")
+		}
+		if spec.Threshold < 0 || spec.Threshold > 1 {
+			return nil, errors.New("judge threshold must be between 0 and 1")
+		}
+		threshold := spec.Threshold
+		if threshold == 0 {
+			threshold = defaultJudgeThreshold
+		}
+		return &judgeGrader{completer: completer, model: model, rubric: spec.Rubric, threshold: threshold}, nil
+	}
+}
+
+type judgeGrader struct {
+	completer chat.Completer
+	model     string
+	rubric    string
+	threshold float64
+}
+
+type judgeVerdict struct {
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+func (g *judgeGrader) Grade(ctx context.Context, s Sample) (Grade, error) {
+	content := fmt.Sprintf("Rubric:\n%s\n\nUser message:\n%s\n\nReply:\n%s", g.rubric, s.Question, s.Reply)
+	out, err := g.completer.Complete(ctx, g.model, []chat.Message{
+		{Role: "system", Content: judgePrompt},
+		{Role: "user", Content: content},
+	})
+	if err != nil {
+		return Grade{}, err
+	}
+	v, err := parseJudgeVerdict(out)
+	if err != nil {
+		return Grade{}, err
+	}
+	return Grade{Grader: GraderJudge, Pass: v.Score >= g.threshold, Score: v.Score, Detail: v.Reason}, nil
+}
+
+// parseJudgeVerdict reads the first JSON object in the judge's answer,
+// tolerating prose or a code fence around it.
+func parseJudgeVerdict(out string) (judgeVerdict, error) {
+	start, end := strings.IndexByte(out, '{'), strings.LastIndexByte(out, '}')
+	if start < 0 || end < start {
+		return judgeVerdict{}, fmt.Errorf("judge answered without a verdict: %q", out)
+	}
+	var v judgeVerdict
+	if err := json.Unmarshal([]byte(out[start:end+1]), &v); err != nil {
+		return judgeVerdict{}, fmt.Errorf("parse judge verdict: %w", err)
+	}
+	if v.Score < 0 || v.Score > 1 {
+		return judgeVerdict{}, fmt.Errorf("judge score %v out of range", v.Score)
+	}
+	return v, nil
+}
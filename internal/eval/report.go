@@ -0,0 +1,223 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report compares a candidate configuration with the baseline on the same
+// dataset.
+type Report struct {
+	Dataset     string       `json:"dataset"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Baseline    TargetResult `json:"baseline"`
+	Candidate   TargetResult `json:"candidate"`
+}
+
+// Summary aggregates graded turns; latency and tokens cover every turn
+// that got a reply.
+type Summary struct {
+	Turns            int     `json:"turns"`
+	Graded           int     `json:"graded"`
+	Passed           int     `json:"passed"`
+	Errors           int     `json:"errors"`
+	PassRate         float64 `json:"pass_rate"`
+	AvgScore         float64 `json:"avg_score"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+	AvgFirstTokenMs  float64 `json:"avg_first_token_ms"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+}
+
+func (t TargetResult) Summary() Summary {
+	var (
+		s                 Summary
+		score             float64
+		latency, first    int64
+		replied, firstCnt int
+	)
+	for _, turn := range t.Turns {
+		s.Turns++
+		if turn.Error != "" {
+			s.Errors++
+		} else {
+			replied++
+			latency += turn.LatencyMs
+			if turn.FirstTokenMs > 0 {
+				first += turn.FirstTokenMs
+				firstCnt++
+			}
+			s.PromptTokens += turn.PromptTokens
+			s.CompletionTokens += turn.CompletionTokens
+		}
+		if !turn.Graded {
+			continue
+		}
+		s.Graded++
+		score += turn.Score
+		if turn.Pass {
+			s.Passed++
+		}
+	}
+	if s.Graded > 0 {
+		s.PassRate = float64(s.Passed) / float64(s.Graded)
+		s.AvgScore = score / float64(s.Graded)
+	}
+	if replied > 0 {
+		s.AvgLatencyMs = float64(latency) / float64(replied)
+	}
+	if firstCnt > 0 {
+		s.AvgFirstTokenMs = float64(first) / float64(firstCnt)
+	}
+	return s
+}
+
+// Change is a graded turn whose verdict differs between the configurations.
+type Change struct {
+	CaseID    string
+	Turn      int
+	Baseline  *TurnResult
+	Candidate *TurnResult
+}
+
+// Regression reports whether the candidate fails a turn the baseline
+// passed, including one it never reached.
+func (c Change) Regression() bool {
+	return c.Baseline != nil && c.Baseline.Pass && (c.Candidate == nil || !c.Candidate.Pass)
+}
+
+// Changes lists the graded turns whose verdicts differ, in dataset order.
+func (r *Report) Changes() []Change {
+	type key struct {
+		caseID string
+		turn   int
+	}
+	candidate := map[key]*TurnResult{}
+	for i := range r.Candidate.Turns {
+		t := &r.Candidate.Turns[i]
+		candidate[key{t.CaseID, t.Turn}] = t
+	}
+	var out []Change
+	seen := map[key]bool{}
+	for i := range r.Baseline.Turns {
+		b := &r.Baseline.Turns[i]
+		k := key{b.CaseID, b.Turn}
+		seen[k] = true
+		c := candidate[k]
+		if !b.Graded && (c == nil || !c.Graded) {
+			continue
+		}
+		if c == nil || b.Pass != c.Pass {
+			out = append(out, Change{CaseID: b.CaseID, Turn: b.Turn, Baseline: b, Candidate: c})
+		}
+	}
+	for i := range r.Candidate.Turns {
+		c := &r.Candidate.Turns[i]
+		if !seen[key{c.CaseID, c.Turn}] && c.Graded {
+			out = append(out, Change{CaseID: c.CaseID, Turn: c.Turn, Candidate: c})
+		}
+	}
+	return out
+}
+
+func (r *Report) Regressions() []Change {
+	var out []Change
+	for _, c := range r.Changes() {
+		if c.Regression() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	base, cand := r.Baseline.Summary(), r.Candidate.Summary()
+	fmt.Fprintf(&b, "# Evaluation report\n\n")
+	fmt.Fprintf(&b, "Dataset `%s`, generated %s.\n\n", r.Dataset, r.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "| | %s | %s |\n|---|---|---|\n", targetLabel(r.Baseline), targetLabel(r.Candidate))
+	row := func(name, a, c string) { fmt.Fprintf(&b, "| %s | %s | %s |\n", name, a, c) }
+	row("Model", r.Baseline.Variant.Model, r.Candidate.Variant.Model)
+	row("Prompt version", r.Baseline.Variant.PromptVersion, r.Candidate.Variant.PromptVersion)
+	row("Pass rate", fmt.Sprintf("%.1f%% (%d/%d)", base.PassRate*100, base.Passed, base.Graded),
+		fmt.Sprintf("%.1f%% (%d/%d)", cand.PassRate*100, cand.Passed, cand.Graded))
+	row("Avg score", fmt.Sprintf("%.3f", base.AvgScore), fmt.Sprintf("%.3f", cand.AvgScore))
+	row("Errors", fmt.Sprint(base.Errors), fmt.Sprint(cand.Errors))
+	row("Avg latency", fmt.Sprintf("%.0f ms", base.AvgLatencyMs), fmt.Sprintf("%.0f ms", cand.AvgLatencyMs))
+	row("Avg first token", fmt.Sprintf("%.0f ms", base.AvgFirstTokenMs), fmt.Sprintf("%.0f ms", cand.AvgFirstTokenMs))
+	row("Prompt tokens", fmt.Sprint(base.PromptTokens), fmt.Sprint(cand.PromptTokens))
+	row("Completion tokens", fmt.Sprint(base.CompletionTokens), fmt.Sprint(cand.CompletionTokens))
+
+	changes := r.Changes()
+	fmt.Fprintf(&b, "\n## Changed verdicts\n\n")
+	if len(changes) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString("| Case | Turn | Baseline | Candidate | |\n|---|---|---|---|---|\n")
+		for _, c := range changes {
+			mark := "improved"
+			if c.Regression() {
+				mark = "**regressed**"
+			}
+			fmt.Fprintf(&b, "| %s | %d | %s | %s | %s |\n", c.CaseID, c.Turn, verdictLabel(c.Baseline), verdictLabel(c.Candidate), mark)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Failures\n")
+	writeFailures(&b, targetLabel(r.Baseline), r.Baseline)
+	writeFailures(&b, targetLabel(r.Candidate), r.Candidate)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeFailures(b *strings.Builder, label string, t TargetResult) {
+	fmt.Fprintf(b, "\n### %s\n\n", label)
+	n := 0
+	for _, turn := range t.Turns {
+		if !turn.Graded || turn.Pass {
+			continue
+		}
+		n++
+		fmt.Fprintf(b, "- `%s` turn %d", turn.CaseID, turn.Turn)
+		if turn.Error != "" {
+			fmt.Fprintf(b, ": error: %s\n", turn.Error)
+			continue
+		}
+		b.WriteString("\n")
+		for _, g := range turn.Grades {
+			if g.Pass {
+				continue
+			}
+			fmt.Fprintf(b, "  - %s (%.2f)", g.Grader, g.Score)
+			if g.Detail != "" {
+				fmt.Fprintf(b, ": %s", g.Detail)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if n == 0 {
+		b.WriteString("None.\n")
+	}
+}
+
+func targetLabel(t TargetResult) string {
+	if t.Variant.Name != "" {
+		return t.Variant.Name
+	}
+	return t.Variant.Model
+}
+
+func verdictLabel(t *TurnResult) string {
+	switch {
+	case t == nil:
+		return "not run"
+	case t.Error != "":
+		return "error"
+	case t.Pass:
+		return fmt.Sprintf("pass (%.2f)", t.Score)
+	default:
+		return fmt.Sprintf("fail (%.2f)", t.Score)
+	}
+}
@@ -0,0 +1,182 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+)
+
+// Target is one configuration under evaluation: the chat logic built with
+// Variant's model, sampling settings and system prompt.
+type Target struct {
+	Variant configs.ExperimentVariant
+	Logic   chat.Logic
+}
+
+type TurnResult struct {
+	CaseID           string  `json:"case_id"`
+	Turn             int     `json:"turn"`
+	Question         string  `json:"question"`
+	Reply            string  `json:"reply"`
+	FinishReason     string  `json:"finish_reason,omitempty"`
+	LatencyMs        int64   `json:"latency_ms"`
+	FirstTokenMs     int64   `json:"first_token_ms"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Graded           bool    `json:"graded"`
+	Pass             bool    `json:"pass"`
+	Score            float64 `json:"score"`
+	Grades           []Grade `json:"grades,omitempty"`
+	Error            string  `json:"error,omitempty"`
+}
+
+type TargetResult struct {
+	Variant configs.ExperimentVariant `json:"variant"`
+	Turns   []TurnResult              `json:"turns"`
+}
+
+// Suite is a dataset with its graders built.
+type Suite struct {
+	cases   []Case
+	graders [][][]builtGrader
+}
+
+type builtGrader struct {
+	typ string
+	Grader
+}
+
+func NewSuite(cases []Case, graders *Graders) (*Suite, error) {
+	s := &Suite{cases: cases, graders: make([][][]builtGrader, len(cases))}
+	for i, c := range cases {
+		s.graders[i] = make([][]builtGrader, len(c.Turns))
+		for j, t := range c.Turns {
+			for _, spec := range t.Graders {
+				g, err := graders.Build(spec)
+				if err != nil {
+					return nil, fmt.Errorf("case %s turn %d: %w", c.ID, j+1, err)
+				}
+				s.graders[i][j] = append(s.graders[i][j], builtGrader{typ: spec.Type, Grader: g})
+			}
+		}
+	}
+	return s, nil
+}
+
+// Runner sends a suite's conversations through a target as UserID. Failed
+// turns are recorded rather than aborting the run; the rest of a case is
+// skipped once one of its turns failed.
+type Runner struct {
+	UserID      string
+	TurnTimeout time.Duration
+	// Keep leaves the evaluated conversations in place instead of deleting
+	// them after each case.
+	Keep bool
+}
+
+func (r *Runner) Run(ctx context.Context, suite *Suite, target Target) TargetResult {
+	out := TargetResult{Variant: target.Variant}
+	for i, c := range suite.cases {
+		conversationID := ""
+		for j, t := range c.Turns {
+			res := TurnResult{CaseID: c.ID, Turn: j + 1, Question: t.User}
+			id, err := r.runTurn(ctx, target, c.BotID, conversationID, &res)
+			if id != "" {
+				conversationID = id
+			}
+			if err == nil {
+				r.grade(ctx, suite.graders[i][j], &res)
+			} else {
+				res.Error = err.Error()
+				res.Graded = len(suite.graders[i][j]) > 0
+			}
+			out.Turns = append(out.Turns, res)
+			if err != nil {
+				break
+			}
+		}
+		if conversationID != "" && !r.Keep {
+			if err := target.Logic.DeleteConversation(ctx, &chat.DeleteConversationReq{ConversationID: conversationID}, r.UserID); err != nil {
+				logger.L().Errorf("delete eval conversation %s error: %v", conversationID, err)
+			}
+		}
+	}
+	return out
+}
+
+func (r *Runner) runTurn(ctx context.Context, target Target, botID, conversationID string, res *TurnResult) (string, error) {
+	if r.TurnTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.TurnTimeout)
+		defer cancel()
+	}
+	started := time.Now()
+	stream, conversationID, err := target.Logic.ResponseStream(ctx, &chat.Completion{
+		ConversationID: conversationID,
+		BotID:          botID,
+		Model:          target.Variant.Model,
+		Messages:       []chat.Message{{Role: "user", ContentType: "text", Content: res.Question}},
+		Stream:         true,
+		Cache:          chat.CacheControl{NoLookup: true, NoStore: true},
+	}, r.UserID)
+	if err != nil {
+		return conversationID, err
+	}
+	defer stream.Close()
+
+	var reply strings.Builder
+	for {
+		ev, done, err := stream.Next()
+		if err != nil {
+			return conversationID, err
+		}
+		if ev.Type == chat.EventError {
+			return conversationID, fmt.Errorf("stream error: %s", ev.Delta)
+		}
+		if ev.Type == chat.EventTextDelta {
+			if res.FirstTokenMs == 0 {
+				res.FirstTokenMs = time.Since(started).Milliseconds()
+			}
+			reply.WriteString(ev.Delta)
+		}
+		if done {
+			res.FinishReason = ev.FinishReason
+			if ev.Usage != nil {
+				res.PromptTokens = ev.Usage.PromptTokens
+				res.CompletionTokens = ev.Usage.CompletionTokens
+			}
+			break
+		}
+	}
+	res.LatencyMs = time.Since(started).Milliseconds()
+	res.Reply = reply.String()
+	return conversationID, nil
+}
+
+// grade scores a reply; a turn passes when every grader passes and scores
+// the mean of their scores.
+func (r *Runner) grade(ctx context.Context, graders []builtGrader, res *TurnResult) {
+	if len(graders) == 0 {
+		return
+	}
+	res.Graded = true
+	res.Pass = true
+	sample := Sample{CaseID: res.CaseID, Turn: res.Turn, Question: res.Question, Reply: res.Reply}
+	var total float64
+	for _, g := range graders {
+		grade, err := g.Grade(ctx, sample)
+		if err != nil {
+			grade = Grade{Detail: "grader error: " + err.Error()}
+		}
+		grade.Grader = g.typ
+		res.Grades = append(res.Grades, grade)
+		res.Pass = res.Pass && grade.Pass
+		total += grade.Score
+	}
+	res.Score = total / float64(len(graders))
+}
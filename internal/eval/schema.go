@@ -0,0 +1,178 @@
+package eval
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// validateSchema checks v, decoded from JSON, against the subset of JSON
+// Schema replies are usually held to: type, enum, const, properties,
+// required, additionalProperties, items, min/maxItems, min/maxLength,
+// pattern, minimum and maximum. Other keywords are ignored.
+func validateSchema(schema map[string]any, v any, path string) error {
+	if t, ok := schema["type"]; ok {
+		if !matchesType(t, v) {
+			return fmt.Errorf("%s: want type %v, got %s", path, t, jsonType(v))
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if equalJSON(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, v, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !equalJSON(c, v) {
+		return fmt.Errorf("%s: want %v", path, c)
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		return validateObject(schema, val, path)
+	case []any:
+		if n, ok := number(schema["minItems"]); ok && float64(len(val)) < n {
+			return fmt.Errorf("%s: want at least %v items", path, n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(val)) > n {
+			return fmt.Errorf("%s: want at most %v items", path, n)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(val))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			return fmt.Errorf("%s: want at least %v characters", path, n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			return fmt.Errorf("%s: want at most %v characters", path, n)
+		}
+		if p, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("%s: bad pattern: %w", path, err)
+			}
+			if !re.MatchString(val) {
+				return fmt.Errorf("%s: does not match %s", path, p)
+			}
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && val < n {
+			return fmt.Errorf("%s: want at least %v", path, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && val > n {
+			return fmt.Errorf("%s: want at most %v", path, n)
+		}
+	}
+	return nil
+}
+
+func validateObject(schema map[string]any, obj map[string]any, path string) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing %q", path, name)
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sub, ok := properties[k].(map[string]any)
+		if !ok {
+			if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+				return fmt.Errorf("%s: unexpected property %q", path, k)
+			}
+			continue
+		}
+		if err := validateSchema(sub, obj[k], path+"."+k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchesType(t any, v any) bool {
+	switch tt := t.(type) {
+	case string:
+		return typeIs(tt, v)
+	case []any:
+		for _, x := range tt {
+			if s, ok := x.(string); ok && typeIs(s, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeIs(t string, v any) bool {
+	actual := jsonType(v)
+	if t == "number" && actual == "integer" {
+		return true
+	}
+	return t == actual
+}
+
+func jsonType(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == float64(int64(val)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// number reads a schema keyword, which YAML decodes as int and JSON as
+// float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// equalJSON compares a schema value with a decoded one, treating YAML
+// integers as JSON numbers.
+func equalJSON(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
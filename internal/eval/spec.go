@@ -0,0 +1,66 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"gopkg.in/yaml.v3"
+)
+
+// ExperimentID tags the replies written during an evaluation.
+const ExperimentID = "eval"
+
+// Spec names the two configurations to compare and the judge model. A
+// configuration is an experiment variant: model, sampling settings, a system
+// prompt template and the prompt version it is reported under.
+type Spec struct {
+	Baseline  configs.ExperimentVariant `json:"baseline" yaml:"baseline"`
+	Candidate configs.ExperimentVariant `json:"candidate" yaml:"candidate"`
+	// JudgeModel answers judge graders; it defaults to the baseline model.
+	JudgeModel string `json:"judge_model" yaml:"judge_model"`
+}
+
+func LoadSpec(path string) (Spec, error) {
+	var spec Spec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return spec, err
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return spec, err
+	}
+	if spec.Baseline.Name == "" {
+		spec.Baseline.Name = "baseline"
+	}
+	if spec.Candidate.Name == "" {
+		spec.Candidate.Name = "candidate"
+	}
+	if spec.Baseline.Name == spec.Candidate.Name {
+		return spec, errors.New("baseline and candidate need different names")
+	}
+	for _, v := range []configs.ExperimentVariant{spec.Baseline, spec.Candidate} {
+		if v.Model == "" {
+			return spec, fmt.Errorf("%s has no model", v.Name)
+		}
+	}
+	if spec.JudgeModel == "" {
+		spec.JudgeModel = spec.Baseline.Model
+	}
+	return spec, nil
+}
+
+// ExperimentConfig puts every conversation of every bot on the variant, so
+// the chat logic runs exactly as it would for users assigned to it.
+func ExperimentConfig(v configs.ExperimentVariant) configs.ExperimentConfig {
+	v.Weight = 100
+	return configs.ExperimentConfig{
+		Enabled: true,
+		Experiments: []configs.Experiment{{
+			ID:       ExperimentID,
+			Enabled:  true,
+			Variants: []configs.ExperimentVariant{v},
+		}},
+	}
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/attachment"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ChatServer) UploadAttachment(srv chatv1.ChatService_UploadAttachmentServer) error {
+	first, err := srv.Recv()
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "upload attachment failed: %v", err)
+	}
+	meta := first.GetMeta()
+	if meta == nil {
+		return status.Error(codes.InvalidArgument, "upload attachment failed: first message must carry meta")
+	}
+	in := &attachment.UploadReq{
+		FileName: meta.GetFileName(),
+		MimeType: meta.GetMimeType(),
+	}
+	item, err := s.attachment.Upload(srv.Context(), in, &uploadReader{srv: srv, buf: first.GetChunk()}, meta.GetUserId())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "upload attachment failed: %v", err)
+	}
+	return srv.SendAndClose(toProtoAttachment(item))
+}
+
+func (s *ChatServer) GetAttachment(ctx context.Context, req *chatv1.GetAttachmentReq) (*chatv1.AttachmentItem, error) {
+	in := &attachment.GetReq{AttachmentID: req.GetAttachmentId()}
+	item, err := s.attachment.Get(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "get attachment failed: %v", err)
+	}
+	return toProtoAttachment(item), nil
+}
+
+// uploadReader turns the chunk messages of a client stream into an io.Reader.
+type uploadReader struct {
+	srv chatv1.ChatService_UploadAttachmentServer
+	buf []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.srv.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = req.GetChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func toProtoAttachment(item *attachment.Item) *chatv1.AttachmentItem {
+	return &chatv1.AttachmentItem{
+		AttachmentId:   item.AttachmentID,
+		ConversationId: item.ConversationID,
+		MessageId:      item.MessageID,
+		FileName:       item.FileName,
+		MimeType:       item.MimeType,
+		Size:           item.Size,
+		Status:         item.Status,
+		Error:          item.Error,
+		CreatedAt:      item.CreatedAt,
+	}
+}
+
+func toProtoAttachmentRefs(refs []chat.AttachmentRef) []*chatv1.AttachmentRef {
+	if len(refs) == 0 {
+		return nil
+	}
+	out := make([]*chatv1.AttachmentRef, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, &chatv1.AttachmentRef{
+			AttachmentId: ref.AttachmentID,
+			FileName:     ref.FileName,
+			MimeType:     ref.MimeType,
+			Size:         ref.Size,
+		})
+	}
+	return out
+}
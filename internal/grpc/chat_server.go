@@ -5,8 +5,18 @@ import (
 	"errors"
 	"io"
 
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/attachment"
 	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
 	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/impls/openai"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/experiment"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/export"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/feedback"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/importer"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/knowledge"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/reminder"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/search"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/share"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/webhook"
 	"github.com/im-core-go/im-core-bot-platform/internal/svc"
 	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
 
@@ -17,7 +27,17 @@ import (
 
 type ChatServer struct {
 	chatv1.UnimplementedChatServiceServer
-	logic chat.Logic
+	logic      chat.Logic
+	attachment attachment.Logic
+	knowledge  knowledge.Logic
+	search     search.Logic
+	export     export.Logic
+	importer   importer.Logic
+	share      share.Logic
+	webhook    webhook.Logic
+	reminder   reminder.Logic
+	feedback   feedback.Logic
+	experiment experiment.Logic
 }
 
 func NewChatServer(svcCtx *svc.Context) (*ChatServer, error) {
@@ -25,7 +45,23 @@ func NewChatServer(svcCtx *svc.Context) (*ChatServer, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ChatServer{logic: logic}, nil
+	embedder, err := openai.NewEmbedder(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatServer{
+		logic:      logic,
+		attachment: attachment.NewLogic(svcCtx),
+		knowledge:  knowledge.NewLogic(svcCtx, embedder),
+		search:     search.NewLogic(svcCtx, embedder),
+		export:     export.NewLogic(svcCtx),
+		importer:   importer.NewLogic(svcCtx),
+		share:      share.NewLogic(svcCtx),
+		webhook:    webhook.NewLogic(svcCtx),
+		reminder:   reminder.NewLogic(svcCtx),
+		feedback:   feedback.NewLogic(svcCtx),
+		experiment: experiment.NewLogic(svcCtx),
+	}, nil
 }
 
 func (s *ChatServer) PullModels(ctx context.Context, _ *emptypb.Empty) (*chatv1.ModelListResp, error) {
@@ -46,12 +82,16 @@ func (s *ChatServer) PullModels(ctx context.Context, _ *emptypb.Empty) (*chatv1.
 func (s *ChatServer) CreateConversation(ctx context.Context, req *chatv1.CreateConversationReq) (*chatv1.CreateConversationResp, error) {
 	in := &chat.CreateConversationReq{
 		Model: req.Model,
+		BotID: req.GetBotId(),
 		Message: chat.Message{
-			Role:        req.GetMessage().GetRole(),
-			ContentType: req.GetMessage().GetContentType(),
-			Content:     req.GetMessage().GetContent(),
-			Meta:        req.GetMessage().GetMeta(),
+			Role:          req.GetMessage().GetRole(),
+			ContentType:   req.GetMessage().GetContentType(),
+			Content:       req.GetMessage().GetContent(),
+			Images:        fromProtoImages(req.GetMessage().GetImages()),
+			AttachmentIDs: req.GetMessage().GetAttachmentIds(),
+			Meta:          req.GetMessage().GetMeta(),
 		},
+		Cache: fromProtoCacheControl(req.GetCache()),
 	}
 	resp, err := s.logic.CreateConversation(ctx, in, req.GetUserId())
 	if err != nil {
@@ -66,61 +106,79 @@ func (s *ChatServer) CreateConversation(ctx context.Context, req *chatv1.CreateC
 			Content:     resp.Reply.Content,
 			Meta:        resp.Reply.Meta,
 		},
+		FinishReason: resp.FinishReason,
+		Cached:       resp.Cached,
 	}, nil
 }
 
 func (s *ChatServer) ListConversations(ctx context.Context, req *chatv1.ListConversationsReq) (*chatv1.ListConversationsResp, error) {
 	in := &chat.ListConversationsReq{
-		Page:     int(req.GetPage()),
-		PageSize: int(req.GetPageSize()),
+		Page:         int(req.GetPage()),
+		PageSize:     int(req.GetPageSize()),
+		Cursor:       req.GetCursor(),
+		IncludeTotal: req.GetIncludeTotal(),
+		FolderID:     req.GetFolderId(),
+		Tag:          req.GetTag(),
+		BotID:        req.GetBotId(),
+		Archived:     req.GetArchived(),
+		UpdatedFrom:  req.GetUpdatedFrom(),
+		UpdatedTo:    req.GetUpdatedTo(),
 	}
 	resp, err := s.logic.ListConversations(ctx, in, req.GetUserId())
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "list conversations failed: %v", err)
 	}
 	out := &chatv1.ListConversationsResp{
-		Total:    resp.Total,
-		Page:     int32(resp.Page),
-		PageSize: int32(resp.PageSize),
-		Items:    make([]*chatv1.ConversationItem, 0, len(resp.Items)),
+		Total:      resp.Total,
+		Page:       int32(resp.Page),
+		PageSize:   int32(resp.PageSize),
+		NextCursor: resp.NextCursor,
+		PrevCursor: resp.PrevCursor,
+		Items:      make([]*chatv1.ConversationItem, 0, len(resp.Items)),
 	}
 	for _, item := range resp.Items {
-		out.Items = append(out.Items, &chatv1.ConversationItem{
-			ConversationId: item.ConversationID,
-			Title:          item.Title,
-			CreatedAt:      item.CreatedAt,
-			UpdatedAt:      item.UpdatedAt,
-		})
+		out.Items = append(out.Items, toProtoConversation(item))
 	}
 	return out, nil
 }
 
 func (s *ChatServer) ListMessages(ctx context.Context, req *chatv1.ListMessagesReq) (*chatv1.ListMessagesResp, error) {
 	in := &chat.ListMessagesReq{
-		ConversationID: req.GetConversationId(),
-		Page:           int(req.GetPage()),
-		PageSize:       int(req.GetPageSize()),
+		ConversationID:   req.GetConversationId(),
+		Page:             int(req.GetPage()),
+		PageSize:         int(req.GetPageSize()),
+		Cursor:           req.GetCursor(),
+		IncludeTotal:     req.GetIncludeTotal(),
+		IncludeReasoning: req.GetIncludeReasoning(),
 	}
 	resp, err := s.logic.ListMessages(ctx, in, req.GetUserId())
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "list messages failed: %v", err)
 	}
 	out := &chatv1.ListMessagesResp{
-		Total:    resp.Total,
-		Page:     int32(resp.Page),
-		PageSize: int32(resp.PageSize),
-		Items:    make([]*chatv1.MessageItem, 0, len(resp.Items)),
+		Total:      resp.Total,
+		Page:       int32(resp.Page),
+		PageSize:   int32(resp.PageSize),
+		NextCursor: resp.NextCursor,
+		PrevCursor: resp.PrevCursor,
+		Items:      make([]*chatv1.MessageItem, 0, len(resp.Items)),
 	}
 	for _, item := range resp.Items {
 		out.Items = append(out.Items, &chatv1.MessageItem{
 			Id:          item.ID,
 			Sequence:    item.Sequence,
+			UserId:      item.UserID,
 			Role:        item.Role,
 			ContentType: item.ContentType,
 			Content:     item.Content,
+			Images:      toProtoImages(item.Images),
+			Attachments: toProtoAttachmentRefs(item.Attachments),
+			Reasoning:   item.Reasoning,
 			Meta:        item.Meta,
 			IsSummary:   item.IsSummary,
 			CreatedAt:   item.CreatedAt,
+			Model:       item.Model,
+			Feedback:    toProtoMessageFeedback(item.Feedback),
 		})
 	}
 	return out, nil
@@ -132,12 +190,7 @@ func (s *ChatServer) GetConversation(ctx context.Context, req *chatv1.GetConvers
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "get conversation failed: %v", err)
 	}
-	return &chatv1.ConversationItem{
-		ConversationId: resp.ConversationID,
-		Title:          resp.Title,
-		CreatedAt:      resp.CreatedAt,
-		UpdatedAt:      resp.UpdatedAt,
-	}, nil
+	return toProtoConversation(*resp), nil
 }
 
 func (s *ChatServer) UpdateConversationTitle(ctx context.Context, req *chatv1.UpdateConversationTitleReq) (*emptypb.Empty, error) {
@@ -167,22 +220,60 @@ func (s *ChatServer) ClearMessages(ctx context.Context, req *chatv1.ClearMessage
 	return &emptypb.Empty{}, nil
 }
 
+func (s *ChatServer) ListTrash(ctx context.Context, req *chatv1.ListTrashReq) (*chatv1.ListConversationsResp, error) {
+	in := &chat.ListTrashReq{
+		Page:     int(req.GetPage()),
+		PageSize: int(req.GetPageSize()),
+	}
+	resp, err := s.logic.ListTrash(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list trash failed: %v", err)
+	}
+	out := &chatv1.ListConversationsResp{
+		Total:    resp.Total,
+		Page:     int32(resp.Page),
+		PageSize: int32(resp.PageSize),
+		Items:    make([]*chatv1.ConversationItem, 0, len(resp.Items)),
+	}
+	for _, item := range resp.Items {
+		out.Items = append(out.Items, toProtoConversation(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) RestoreConversation(ctx context.Context, req *chatv1.RestoreConversationReq) (*emptypb.Empty, error) {
+	in := &chat.RestoreConversationReq{ConversationID: req.GetConversationId()}
+	if err := s.logic.RestoreConversation(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "restore conversation failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
 func (s *ChatServer) Stream(req *chatv1.Completion, srv chatv1.ChatService_StreamServer) error {
 	if req == nil {
 		return status.Error(codes.InvalidArgument, "missing request")
 	}
 	in := &chat.Completion{
-		ConversationID: req.GetConversationId(),
-		Model:          req.GetModel(),
-		Stream:         req.GetStream(),
-		Messages:       make([]chat.Message, 0, len(req.GetMessages())),
+		ConversationID:   req.GetConversationId(),
+		BotID:            req.GetBotId(),
+		Model:            req.GetModel(),
+		Stream:           req.GetStream(),
+		IncludeReasoning: req.GetIncludeReasoning(),
+		Mode:             toCompletionMode(req.GetMode()),
+		ImageSize:        req.GetImageSize(),
+		ImageCount:       int(req.GetImageCount()),
+		Cache:            fromProtoCacheControl(req.GetCache()),
+		Messages:         make([]chat.Message, 0, len(req.GetMessages())),
 	}
 	for _, m := range req.GetMessages() {
 		in.Messages = append(in.Messages, chat.Message{
-			Role:        m.GetRole(),
-			ContentType: m.GetContentType(),
-			Content:     m.GetContent(),
-			Meta:        m.GetMeta(),
+			Role:          m.GetRole(),
+			ContentType:   m.GetContentType(),
+			Content:       m.GetContent(),
+			Images:        fromProtoImages(m.GetImages()),
+			AttachmentIDs: m.GetAttachmentIds(),
+			Meta:          m.GetMeta(),
+			Mentions:      m.GetMentions(),
 		})
 	}
 	stream, conversationID, err := s.logic.ResponseStream(srv.Context(), in, req.GetUserId())
@@ -211,19 +302,86 @@ func (s *ChatServer) Stream(req *chatv1.Completion, srv chatv1.ChatService_Strea
 	}
 }
 
+func fromProtoImages(images []*chatv1.Image) []chat.Image {
+	if len(images) == 0 {
+		return nil
+	}
+	out := make([]chat.Image, 0, len(images))
+	for _, img := range images {
+		out = append(out, chat.Image{
+			URL:      img.GetUrl(),
+			Data:     img.GetData(),
+			MimeType: img.GetMimeType(),
+		})
+	}
+	return out
+}
+
+func toProtoImages(images []chat.Image) []*chatv1.Image {
+	if len(images) == 0 {
+		return nil
+	}
+	out := make([]*chatv1.Image, 0, len(images))
+	for _, img := range images {
+		out = append(out, toProtoImage(img))
+	}
+	return out
+}
+
+func toProtoImage(img chat.Image) *chatv1.Image {
+	return &chatv1.Image{
+		Url:      img.URL,
+		MimeType: img.MimeType,
+		BlobKey:  img.BlobKey,
+	}
+}
+
+func toCompletionMode(m chatv1.CompletionMode) chat.CompletionMode {
+	switch m {
+	case chatv1.CompletionMode_COMPLETION_MODE_IMAGE:
+		return chat.CompletionModeImage
+	default:
+		return chat.CompletionModeChat
+	}
+}
+
+func fromProtoCacheControl(c *chatv1.CacheControl) chat.CacheControl {
+	return chat.CacheControl{
+		NoLookup: c.GetNoLookup(),
+		NoStore:  c.GetNoStore(),
+	}
+}
+
 func toProtoStreamEvent(ev chat.StreamEvent) *chatv1.StreamEvent {
-	return &chatv1.StreamEvent{
+	out := &chatv1.StreamEvent{
 		Type:           toProtoStreamEventType(ev.Type),
 		Delta:          ev.Delta,
 		ConversationId: ev.ConversationID,
 		Title:          ev.Title,
+		FinishReason:   ev.FinishReason,
+		Cached:         ev.Cached,
+	}
+	if ev.Image != nil {
+		out.Image = toProtoImage(*ev.Image)
+	}
+	for _, c := range ev.Citations {
+		out.Citations = append(out.Citations, &chatv1.Citation{
+			KnowledgeBaseId: c.KnowledgeBaseID,
+			DocumentId:      c.DocumentID,
+			DocumentTitle:   c.DocumentTitle,
+			ChunkIndex:      int32(c.ChunkIndex),
+			Score:           c.Score,
+		})
 	}
+	return out
 }
 
 func toProtoStreamEventType(t chat.StreamEventType) chatv1.StreamEventType {
 	switch t {
 	case chat.EventTextDelta:
 		return chatv1.StreamEventType_STREAM_EVENT_TYPE_TEXT_DELTA
+	case chat.EventReasoningDelta:
+		return chatv1.StreamEventType_STREAM_EVENT_TYPE_REASONING_DELTA
 	case chat.EventImage:
 		return chatv1.StreamEventType_STREAM_EVENT_TYPE_IMAGE
 	case chat.EventDone:
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/experiment"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ChatServer) GetExperimentResults(ctx context.Context, req *chatv1.GetExperimentResultsReq) (*chatv1.GetExperimentResultsResp, error) {
+	in := &experiment.ResultsReq{
+		ExperimentID: req.GetExperimentId(),
+		From:         req.GetFrom(),
+		To:           req.GetTo(),
+	}
+	resp, err := s.experiment.GetResults(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "get experiment results failed: %v", err)
+	}
+	out := &chatv1.GetExperimentResultsResp{
+		ExperimentId: resp.ExperimentID,
+		Variants:     make([]*chatv1.ExperimentVariantResult, 0, len(resp.Variants)),
+	}
+	for _, v := range resp.Variants {
+		out.Variants = append(out.Variants, &chatv1.ExperimentVariantResult{
+			Variant:             v.Variant,
+			Weight:              int32(v.Weight),
+			Model:               v.Model,
+			PromptVersion:       v.PromptVersion,
+			Replies:             v.Replies,
+			AvgLatencyMs:        v.AvgLatencyMs,
+			AvgFirstTokenMs:     v.AvgFirstTokenMs,
+			PromptTokens:        v.PromptTokens,
+			CompletionTokens:    v.CompletionTokens,
+			AvgCompletionTokens: v.AvgCompletionTokens,
+			Up:                  v.Up,
+			Down:                v.Down,
+			Satisfaction:        v.Satisfaction,
+		})
+	}
+	return out, nil
+}
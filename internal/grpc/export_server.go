@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/export"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const exportChunkSize = 64 << 10
+
+func (s *ChatServer) ExportConversation(req *chatv1.ExportConversationReq, srv chatv1.ChatService_ExportConversationServer) error {
+	in := &export.ExportReq{
+		ConversationID: req.GetConversationId(),
+		Format:         toExportFormat(req.GetFormat()),
+	}
+	out, err := s.export.ExportConversation(srv.Context(), in, req.GetUserId())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "export conversation failed: %v", err)
+	}
+	return sendExport(out, srv.Send)
+}
+
+func (s *ChatServer) ExportAllConversations(req *chatv1.ExportAllConversationsReq, srv chatv1.ChatService_ExportAllConversationsServer) error {
+	in := &export.ExportAllReq{Format: toExportFormat(req.GetFormat())}
+	out, err := s.export.ExportAll(srv.Context(), in, req.GetUserId())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "export conversations failed: %v", err)
+	}
+	return sendExport(out, srv.Send)
+}
+
+// sendExport streams the export in fixed-size chunks. The first chunk carries
+// the file name and MIME type.
+func sendExport(out *export.Export, send func(*chatv1.ExportChunk) error) error {
+	w := &chunkWriter{
+		send: send,
+		first: &chatv1.ExportChunk{
+			FileName: out.FileName,
+			MimeType: out.MimeType,
+		},
+	}
+	if err := out.Write(w); err != nil {
+		return status.Errorf(codes.Internal, "export failed: %v", err)
+	}
+	return w.flush()
+}
+
+type chunkWriter struct {
+	send  func(*chatv1.ExportChunk) error
+	first *chatv1.ExportChunk
+	buf   []byte
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		take := min(exportChunkSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:take]...)
+		p = p[take:]
+		if len(w.buf) == exportChunkSize {
+			if err := w.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (w *chunkWriter) flush() error {
+	if len(w.buf) == 0 && w.first == nil {
+		return nil
+	}
+	chunk := &chatv1.ExportChunk{}
+	if w.first != nil {
+		chunk, w.first = w.first, nil
+	}
+	chunk.Data = w.buf
+	w.buf = make([]byte, 0, exportChunkSize)
+	return w.send(chunk)
+}
+
+func toExportFormat(f chatv1.ExportFormat) export.Format {
+	switch f {
+	case chatv1.ExportFormat_EXPORT_FORMAT_JSON:
+		return export.FormatJSON
+	case chatv1.ExportFormat_EXPORT_FORMAT_HTML:
+		return export.FormatHTML
+	default:
+		return export.FormatMarkdown
+	}
+}
@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/feedback"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ChatServer) RateMessage(ctx context.Context, req *chatv1.RateMessageReq) (*chatv1.RateMessageResp, error) {
+	in := &feedback.RateMessageReq{
+		MessageID: req.GetMessageId(),
+		Rating:    int(req.GetRating()),
+		Reasons:   req.GetReasons(),
+		Comment:   req.GetComment(),
+	}
+	item, err := s.feedback.RateMessage(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "rate message failed: %v", err)
+	}
+	out := &chatv1.RateMessageResp{}
+	if item != nil {
+		out.Feedback = &chatv1.MessageFeedback{
+			Rating:    int32(item.Rating),
+			Reasons:   item.Reasons,
+			Comment:   item.Comment,
+			UpdatedAt: item.UpdatedAt,
+		}
+	}
+	return out, nil
+}
+
+func (s *ChatServer) GetFeedbackStats(ctx context.Context, req *chatv1.GetFeedbackStatsReq) (*chatv1.GetFeedbackStatsResp, error) {
+	in := &feedback.FeedbackStatsReq{
+		BotID:         req.GetBotId(),
+		Model:         req.GetModel(),
+		PromptVersion: req.GetPromptVersion(),
+		From:          req.GetFrom(),
+		To:            req.GetTo(),
+		GroupBy:       req.GetGroupBy(),
+	}
+	resp, err := s.feedback.GetFeedbackStats(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "get feedback stats failed: %v", err)
+	}
+	out := &chatv1.GetFeedbackStatsResp{Items: make([]*chatv1.FeedbackStat, 0, len(resp.Items))}
+	for _, item := range resp.Items {
+		out.Items = append(out.Items, &chatv1.FeedbackStat{
+			BotId:         item.BotID,
+			Model:         item.Model,
+			PromptVersion: item.PromptVersion,
+			Date:          item.Date,
+			Up:            item.Up,
+			Down:          item.Down,
+			Total:         item.Total,
+			Satisfaction:  item.Satisfaction,
+		})
+	}
+	return out, nil
+}
+
+func toProtoMessageFeedback(item *chat.MessageFeedback) *chatv1.MessageFeedback {
+	if item == nil {
+		return nil
+	}
+	return &chatv1.MessageFeedback{
+		Rating:    int32(item.Rating),
+		Reasons:   item.Reasons,
+		Comment:   item.Comment,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
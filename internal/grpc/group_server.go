@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *ChatServer) CreateGroupConversation(ctx context.Context, req *chatv1.CreateGroupConversationReq) (*chatv1.ConversationItem, error) {
+	in := &chat.CreateGroupConversationReq{
+		BotID:        req.GetBotId(),
+		Title:        req.GetTitle(),
+		DisplayName:  req.GetDisplayName(),
+		Participants: fromProtoParticipants(req.GetParticipants()),
+	}
+	item, err := s.logic.CreateGroupConversation(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "create group conversation failed: %v", err)
+	}
+	return toProtoConversation(*item), nil
+}
+
+func (s *ChatServer) ListParticipants(ctx context.Context, req *chatv1.ListParticipantsReq) (*chatv1.ListParticipantsResp, error) {
+	in := &chat.ListParticipantsReq{ConversationID: req.GetConversationId()}
+	items, err := s.logic.ListParticipants(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list participants failed: %v", err)
+	}
+	return toProtoParticipants(items), nil
+}
+
+func (s *ChatServer) AddParticipants(ctx context.Context, req *chatv1.AddParticipantsReq) (*chatv1.ListParticipantsResp, error) {
+	in := &chat.AddParticipantsReq{
+		ConversationID: req.GetConversationId(),
+		Participants:   fromProtoParticipants(req.GetParticipants()),
+	}
+	items, err := s.logic.AddParticipants(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "add participants failed: %v", err)
+	}
+	return toProtoParticipants(items), nil
+}
+
+func (s *ChatServer) RemoveParticipant(ctx context.Context, req *chatv1.RemoveParticipantReq) (*emptypb.Empty, error) {
+	in := &chat.RemoveParticipantReq{
+		ConversationID: req.GetConversationId(),
+		UserID:         req.GetParticipantUserId(),
+	}
+	if err := s.logic.RemoveParticipant(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "remove participant failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ChatServer) SetParticipantRole(ctx context.Context, req *chatv1.SetParticipantRoleReq) (*emptypb.Empty, error) {
+	in := &chat.SetParticipantRoleReq{
+		ConversationID: req.GetConversationId(),
+		UserID:         req.GetParticipantUserId(),
+		Role:           req.GetRole(),
+	}
+	if err := s.logic.SetParticipantRole(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "set participant role failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func fromProtoParticipants(items []*chatv1.Participant) []chat.Participant {
+	out := make([]chat.Participant, 0, len(items))
+	for _, p := range items {
+		out = append(out, chat.Participant{UserID: p.GetUserId(), DisplayName: p.GetDisplayName()})
+	}
+	return out
+}
+
+func toProtoParticipants(items []chat.ParticipantItem) *chatv1.ListParticipantsResp {
+	out := &chatv1.ListParticipantsResp{Items: make([]*chatv1.ParticipantItem, 0, len(items))}
+	for _, item := range items {
+		out.Items = append(out.Items, &chatv1.ParticipantItem{
+			UserId:      item.UserID,
+			DisplayName: item.DisplayName,
+			Role:        item.Role,
+			JoinedAt:    item.JoinedAt,
+		})
+	}
+	return out
+}
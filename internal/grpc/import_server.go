@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/importer"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ImportConversations reads an export file from a client stream: the first
+// message carries meta, the rest carry chunks of the file.
+func (s *ChatServer) ImportConversations(srv chatv1.ChatService_ImportConversationsServer) error {
+	first, err := srv.Recv()
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "import conversations failed: %v", err)
+	}
+	meta := first.GetMeta()
+	if meta == nil {
+		return status.Error(codes.InvalidArgument, "import conversations failed: first message must carry meta")
+	}
+	in := &importer.ImportReq{
+		Format: toImportFormat(meta.GetFormat()),
+		BotID:  meta.GetBotId(),
+	}
+	r := &importReader{srv: srv, buf: first.GetChunk()}
+	resp, err := s.importer.ImportConversations(srv.Context(), in, r, meta.GetUserId())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "import conversations failed: %v", err)
+	}
+	out := &chatv1.ImportConversationsResp{
+		Format:   string(resp.Format),
+		Imported: int32(resp.Imported),
+		Skipped:  int32(resp.Skipped),
+		Failed:   int32(resp.Failed),
+		Items:    make([]*chatv1.ImportItem, 0, len(resp.Items)),
+	}
+	for _, item := range resp.Items {
+		out.Items = append(out.Items, &chatv1.ImportItem{
+			Index:          int32(item.Index),
+			SourceId:       item.SourceID,
+			Title:          item.Title,
+			ConversationId: item.ConversationID,
+			Status:         item.Status,
+			Error:          item.Error,
+			MessageCount:   int32(item.MessageCount),
+		})
+	}
+	return srv.SendAndClose(out)
+}
+
+type importReader struct {
+	srv chatv1.ChatService_ImportConversationsServer
+	buf []byte
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.srv.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = req.GetChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func toImportFormat(f chatv1.ImportFormat) importer.Format {
+	switch f {
+	case chatv1.ImportFormat_IMPORT_FORMAT_CHATGPT:
+		return importer.FormatChatGPT
+	case chatv1.ImportFormat_IMPORT_FORMAT_NATIVE:
+		return importer.FormatNative
+	case chatv1.ImportFormat_IMPORT_FORMAT_GENERIC:
+		return importer.FormatGeneric
+	default:
+		return importer.FormatAuto
+	}
+}
@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/knowledge"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *ChatServer) CreateKnowledgeBase(ctx context.Context, req *chatv1.CreateKnowledgeBaseReq) (*chatv1.KnowledgeBaseItem, error) {
+	in := &knowledge.CreateKnowledgeBaseReq{
+		BotID:       req.GetBotId(),
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+	}
+	item, err := s.knowledge.CreateKnowledgeBase(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "create knowledge base failed: %v", err)
+	}
+	return toProtoKnowledgeBase(*item), nil
+}
+
+func (s *ChatServer) ListKnowledgeBases(ctx context.Context, req *chatv1.ListKnowledgeBasesReq) (*chatv1.ListKnowledgeBasesResp, error) {
+	in := &knowledge.ListKnowledgeBasesReq{BotID: req.GetBotId()}
+	items, err := s.knowledge.ListKnowledgeBases(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list knowledge bases failed: %v", err)
+	}
+	out := &chatv1.ListKnowledgeBasesResp{Items: make([]*chatv1.KnowledgeBaseItem, 0, len(items))}
+	for _, item := range items {
+		out.Items = append(out.Items, toProtoKnowledgeBase(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) DeleteKnowledgeBase(ctx context.Context, req *chatv1.DeleteKnowledgeBaseReq) (*emptypb.Empty, error) {
+	in := &knowledge.DeleteKnowledgeBaseReq{KnowledgeBaseID: req.GetKnowledgeBaseId()}
+	if err := s.knowledge.DeleteKnowledgeBase(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "delete knowledge base failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ChatServer) IngestDocument(ctx context.Context, req *chatv1.IngestDocumentReq) (*chatv1.DocumentItem, error) {
+	in := &knowledge.IngestDocumentReq{
+		KnowledgeBaseID: req.GetKnowledgeBaseId(),
+		Title:           req.GetTitle(),
+		Source:          req.GetSource(),
+		Content:         req.GetContent(),
+		AttachmentID:    req.GetAttachmentId(),
+	}
+	item, err := s.knowledge.IngestDocument(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "ingest document failed: %v", err)
+	}
+	return toProtoDocument(*item), nil
+}
+
+func (s *ChatServer) ListDocuments(ctx context.Context, req *chatv1.ListDocumentsReq) (*chatv1.ListDocumentsResp, error) {
+	in := &knowledge.ListDocumentsReq{
+		KnowledgeBaseID: req.GetKnowledgeBaseId(),
+		Page:            int(req.GetPage()),
+		PageSize:        int(req.GetPageSize()),
+	}
+	resp, err := s.knowledge.ListDocuments(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list documents failed: %v", err)
+	}
+	out := &chatv1.ListDocumentsResp{
+		Total:    resp.Total,
+		Page:     int32(resp.Page),
+		PageSize: int32(resp.PageSize),
+		Items:    make([]*chatv1.DocumentItem, 0, len(resp.Items)),
+	}
+	for _, item := range resp.Items {
+		out.Items = append(out.Items, toProtoDocument(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) DeleteDocument(ctx context.Context, req *chatv1.DeleteDocumentReq) (*emptypb.Empty, error) {
+	in := &knowledge.DeleteDocumentReq{DocumentID: req.GetDocumentId()}
+	if err := s.knowledge.DeleteDocument(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "delete document failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toProtoKnowledgeBase(item knowledge.KnowledgeBaseItem) *chatv1.KnowledgeBaseItem {
+	return &chatv1.KnowledgeBaseItem{
+		KnowledgeBaseId: item.KnowledgeBaseID,
+		BotId:           item.BotID,
+		Name:            item.Name,
+		Description:     item.Description,
+		CreatedAt:       item.CreatedAt,
+		UpdatedAt:       item.UpdatedAt,
+	}
+}
+
+func toProtoDocument(item knowledge.DocumentItem) *chatv1.DocumentItem {
+	return &chatv1.DocumentItem{
+		DocumentId:      item.DocumentID,
+		KnowledgeBaseId: item.KnowledgeBaseID,
+		Title:           item.Title,
+		Source:          item.Source,
+		AttachmentId:    item.AttachmentID,
+		Status:          item.Status,
+		Error:           item.Error,
+		ChunkCount:      int32(item.ChunkCount),
+		CreatedAt:       item.CreatedAt,
+		UpdatedAt:       item.UpdatedAt,
+	}
+}
@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *ChatServer) PinConversation(ctx context.Context, req *chatv1.PinConversationReq) (*emptypb.Empty, error) {
+	in := &chat.PinConversationReq{
+		ConversationID: req.GetConversationId(),
+		Pinned:         req.GetPinned(),
+	}
+	if err := s.logic.PinConversation(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "pin conversation failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ChatServer) ArchiveConversation(ctx context.Context, req *chatv1.ArchiveConversationReq) (*emptypb.Empty, error) {
+	in := &chat.ArchiveConversationReq{
+		ConversationID: req.GetConversationId(),
+		Archived:       req.GetArchived(),
+	}
+	if err := s.logic.ArchiveConversation(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "archive conversation failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ChatServer) MoveConversation(ctx context.Context, req *chatv1.MoveConversationReq) (*emptypb.Empty, error) {
+	in := &chat.MoveConversationReq{
+		ConversationID: req.GetConversationId(),
+		FolderID:       req.GetFolderId(),
+	}
+	if err := s.logic.MoveConversation(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "move conversation failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ChatServer) SetConversationTags(ctx context.Context, req *chatv1.SetConversationTagsReq) (*chatv1.SetConversationTagsResp, error) {
+	in := &chat.SetConversationTagsReq{
+		ConversationID: req.GetConversationId(),
+		Tags:           req.GetTags(),
+	}
+	tags, err := s.logic.SetConversationTags(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "set conversation tags failed: %v", err)
+	}
+	return &chatv1.SetConversationTagsResp{Tags: tags}, nil
+}
+
+func (s *ChatServer) ListTags(ctx context.Context, req *chatv1.ListTagsReq) (*chatv1.ListTagsResp, error) {
+	items, err := s.logic.ListTags(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list tags failed: %v", err)
+	}
+	out := &chatv1.ListTagsResp{Items: make([]*chatv1.TagItem, 0, len(items))}
+	for _, item := range items {
+		out.Items = append(out.Items, &chatv1.TagItem{Tag: item.Tag, Count: item.Count})
+	}
+	return out, nil
+}
+
+func (s *ChatServer) CreateFolder(ctx context.Context, req *chatv1.CreateFolderReq) (*chatv1.FolderItem, error) {
+	in := &chat.CreateFolderReq{Name: req.GetName()}
+	item, err := s.logic.CreateFolder(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "create folder failed: %v", err)
+	}
+	return toProtoFolder(*item), nil
+}
+
+func (s *ChatServer) ListFolders(ctx context.Context, req *chatv1.ListFoldersReq) (*chatv1.ListFoldersResp, error) {
+	items, err := s.logic.ListFolders(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list folders failed: %v", err)
+	}
+	out := &chatv1.ListFoldersResp{Items: make([]*chatv1.FolderItem, 0, len(items))}
+	for _, item := range items {
+		out.Items = append(out.Items, toProtoFolder(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) RenameFolder(ctx context.Context, req *chatv1.RenameFolderReq) (*emptypb.Empty, error) {
+	in := &chat.RenameFolderReq{
+		FolderID: req.GetFolderId(),
+		Name:     req.GetName(),
+	}
+	if err := s.logic.RenameFolder(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "rename folder failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ChatServer) DeleteFolder(ctx context.Context, req *chatv1.DeleteFolderReq) (*emptypb.Empty, error) {
+	in := &chat.DeleteFolderReq{FolderID: req.GetFolderId()}
+	if err := s.logic.DeleteFolder(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "delete folder failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toProtoConversation(item chat.ConversationItem) *chatv1.ConversationItem {
+	return &chatv1.ConversationItem{
+		ConversationId: item.ConversationID,
+		BotId:          item.BotID,
+		Kind:           item.Kind,
+		Title:          item.Title,
+		Model:          item.Model,
+		FolderId:       item.FolderID,
+		Pinned:         item.Pinned,
+		Archived:       item.Archived,
+		Tags:           item.Tags,
+		CreatedAt:      item.CreatedAt,
+		UpdatedAt:      item.UpdatedAt,
+		DeletedAt:      item.DeletedAt,
+	}
+}
+
+func toProtoFolder(item chat.FolderItem) *chatv1.FolderItem {
+	return &chatv1.FolderItem{
+		FolderId:  item.FolderID,
+		Name:      item.Name,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/reminder"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *ChatServer) CreateReminder(ctx context.Context, req *chatv1.CreateReminderReq) (*chatv1.ReminderItem, error) {
+	in := &reminder.CreateReminderReq{
+		ConversationID: req.GetConversationId(),
+		Message:        req.GetMessage(),
+		RunAt:          req.GetRunAt(),
+		At:             req.GetAt(),
+		Cron:           req.GetCron(),
+		Timezone:       req.GetTimezone(),
+	}
+	item, err := s.reminder.CreateReminder(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "create reminder failed: %v", err)
+	}
+	return toProtoReminder(*item), nil
+}
+
+func (s *ChatServer) ListReminders(ctx context.Context, req *chatv1.ListRemindersReq) (*chatv1.ListRemindersResp, error) {
+	in := &reminder.ListRemindersReq{
+		ConversationID:  req.GetConversationId(),
+		IncludeInactive: req.GetIncludeInactive(),
+	}
+	items, err := s.reminder.ListReminders(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list reminders failed: %v", err)
+	}
+	out := &chatv1.ListRemindersResp{Items: make([]*chatv1.ReminderItem, 0, len(items))}
+	for _, item := range items {
+		out.Items = append(out.Items, toProtoReminder(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) CancelReminder(ctx context.Context, req *chatv1.CancelReminderReq) (*emptypb.Empty, error) {
+	in := &reminder.CancelReminderReq{ReminderID: req.GetReminderId()}
+	if err := s.reminder.CancelReminder(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cancel reminder failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toProtoReminder(item reminder.ReminderItem) *chatv1.ReminderItem {
+	return &chatv1.ReminderItem{
+		ReminderId:     item.ReminderID,
+		ConversationId: item.ConversationID,
+		Message:        item.Message,
+		Kind:           item.Kind,
+		Cron:           item.Cron,
+		Timezone:       item.Timezone,
+		Status:         item.Status,
+		NextRunAt:      item.NextRunAt,
+		LastRunAt:      item.LastRunAt,
+		RunCount:       int32(item.RunCount),
+		CreatedAt:      item.CreatedAt,
+	}
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/search"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ChatServer) SearchConversations(ctx context.Context, req *chatv1.SearchConversationsReq) (*chatv1.SearchConversationsResp, error) {
+	in := &search.SearchReq{
+		Query: req.GetQuery(),
+		Mode:  toSearchMode(req.GetMode()),
+		Limit: int(req.GetLimit()),
+	}
+	resp, err := s.search.Search(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "search conversations failed: %v", err)
+	}
+	out := &chatv1.SearchConversationsResp{Items: make([]*chatv1.SearchHit, 0, len(resp.Items))}
+	for _, hit := range resp.Items {
+		out.Items = append(out.Items, &chatv1.SearchHit{
+			ConversationId:    hit.ConversationID,
+			ConversationTitle: hit.ConversationTitle,
+			MessageId:         hit.MessageID,
+			Sequence:          hit.Sequence,
+			Role:              hit.Role,
+			Snippet:           hit.Snippet,
+			Score:             hit.Score,
+			CreatedAt:         hit.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func toSearchMode(m chatv1.SearchMode) search.Mode {
+	switch m {
+	case chatv1.SearchMode_SEARCH_MODE_SEMANTIC:
+		return search.ModeSemantic
+	default:
+		return search.ModeKeyword
+	}
+}
@@ -0,0 +1,104 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/share"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *ChatServer) ShareConversation(ctx context.Context, req *chatv1.ShareConversationReq) (*chatv1.ShareItem, error) {
+	in := &share.ShareConversationReq{
+		ConversationID:   req.GetConversationId(),
+		ExpiresInSeconds: req.GetExpiresInSeconds(),
+	}
+	item, err := s.share.ShareConversation(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, shareStatus(err, "share conversation failed")
+	}
+	return toProtoShare(*item), nil
+}
+
+func (s *ChatServer) ListShares(ctx context.Context, req *chatv1.ListSharesReq) (*chatv1.ListSharesResp, error) {
+	in := &share.ListSharesReq{ConversationID: req.GetConversationId()}
+	items, err := s.share.ListShares(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, shareStatus(err, "list shares failed")
+	}
+	out := &chatv1.ListSharesResp{Items: make([]*chatv1.ShareItem, 0, len(items))}
+	for _, item := range items {
+		out.Items = append(out.Items, toProtoShare(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) RevokeShare(ctx context.Context, req *chatv1.RevokeShareReq) (*emptypb.Empty, error) {
+	in := &share.RevokeShareReq{ShareID: req.GetShareId()}
+	if err := s.share.RevokeShare(ctx, in, req.GetUserId()); err != nil {
+		return nil, shareStatus(err, "revoke share failed")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetSharedConversation is the public read path for share links; it carries
+// no user_id.
+func (s *ChatServer) GetSharedConversation(ctx context.Context, req *chatv1.GetSharedConversationReq) (*chatv1.SharedConversation, error) {
+	in := &share.GetSharedConversationReq{Token: req.GetToken()}
+	resp, err := s.share.GetSharedConversation(ctx, in)
+	if errors.Is(err, share.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "shared conversation not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get shared conversation failed: %v", err)
+	}
+	out := &chatv1.SharedConversation{
+		Title:     resp.Title,
+		SharedAt:  resp.SharedAt,
+		ExpiresAt: resp.ExpiresAt,
+		Messages:  make([]*chatv1.SharedMessage, 0, len(resp.Messages)),
+	}
+	for _, msg := range resp.Messages {
+		item := &chatv1.SharedMessage{
+			Role:        msg.Role,
+			ContentType: msg.ContentType,
+			Content:     msg.Content,
+			CreatedAt:   msg.CreatedAt,
+		}
+		for _, img := range msg.Images {
+			item.Images = append(item.Images, &chatv1.Image{Url: img.URL, MimeType: img.MimeType})
+		}
+		out.Messages = append(out.Messages, item)
+	}
+	return out, nil
+}
+
+// shareStatus maps share errors to gRPC codes; anything else is reported as
+// a bad request.
+func shareStatus(err error, msg string) error {
+	switch {
+	case errors.Is(err, share.ErrNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	case errors.Is(err, share.ErrForbidden):
+		return status.Errorf(codes.PermissionDenied, "%s: %v", msg, err)
+	default:
+		return status.Errorf(codes.InvalidArgument, "%s: %v", msg, err)
+	}
+}
+
+func toProtoShare(item share.ShareItem) *chatv1.ShareItem {
+	return &chatv1.ShareItem{
+		ShareId:        item.ShareID,
+		ConversationId: item.ConversationID,
+		Token:          item.Token,
+		Title:          item.Title,
+		MessageCount:   int32(item.MessageCount),
+		ExpiresAt:      item.ExpiresAt,
+		RevokedAt:      item.RevokedAt,
+		CreatedAt:      item.CreatedAt,
+	}
+}
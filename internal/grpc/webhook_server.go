@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/webhook"
+	chatv1 "github.com/im-core-go/im-core-proto/gen/bot/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *ChatServer) CreateWebhook(ctx context.Context, req *chatv1.CreateWebhookReq) (*chatv1.WebhookItem, error) {
+	in := &webhook.CreateWebhookReq{
+		BotID:      req.GetBotId(),
+		URL:        req.GetUrl(),
+		EventTypes: req.GetEventTypes(),
+	}
+	item, err := s.webhook.CreateWebhook(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "create webhook failed: %v", err)
+	}
+	return toProtoWebhook(*item), nil
+}
+
+func (s *ChatServer) ListWebhooks(ctx context.Context, req *chatv1.ListWebhooksReq) (*chatv1.ListWebhooksResp, error) {
+	items, err := s.webhook.ListWebhooks(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list webhooks failed: %v", err)
+	}
+	out := &chatv1.ListWebhooksResp{Items: make([]*chatv1.WebhookItem, 0, len(items))}
+	for _, item := range items {
+		out.Items = append(out.Items, toProtoWebhook(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) DeleteWebhook(ctx context.Context, req *chatv1.DeleteWebhookReq) (*emptypb.Empty, error) {
+	in := &webhook.DeleteWebhookReq{WebhookID: req.GetWebhookId()}
+	if err := s.webhook.DeleteWebhook(ctx, in, req.GetUserId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "delete webhook failed: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ChatServer) ListWebhookDeliveries(ctx context.Context, req *chatv1.ListWebhookDeliveriesReq) (*chatv1.ListWebhookDeliveriesResp, error) {
+	in := &webhook.ListDeliveriesReq{
+		WebhookID: req.GetWebhookId(),
+		Status:    req.GetStatus(),
+		Page:      int(req.GetPage()),
+		PageSize:  int(req.GetPageSize()),
+	}
+	resp, err := s.webhook.ListDeliveries(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "list webhook deliveries failed: %v", err)
+	}
+	out := &chatv1.ListWebhookDeliveriesResp{
+		Total:    resp.Total,
+		Page:     int32(resp.Page),
+		PageSize: int32(resp.PageSize),
+		Items:    make([]*chatv1.WebhookDeliveryItem, 0, len(resp.Items)),
+	}
+	for _, item := range resp.Items {
+		out.Items = append(out.Items, toProtoWebhookDelivery(item))
+	}
+	return out, nil
+}
+
+func (s *ChatServer) ReplayWebhookDelivery(ctx context.Context, req *chatv1.ReplayWebhookDeliveryReq) (*chatv1.WebhookDeliveryItem, error) {
+	in := &webhook.ReplayDeliveryReq{DeliveryID: req.GetDeliveryId()}
+	item, err := s.webhook.ReplayDelivery(ctx, in, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "replay webhook delivery failed: %v", err)
+	}
+	return toProtoWebhookDelivery(*item), nil
+}
+
+func toProtoWebhook(item webhook.WebhookItem) *chatv1.WebhookItem {
+	return &chatv1.WebhookItem{
+		WebhookId:  item.WebhookID,
+		BotId:      item.BotID,
+		Url:        item.URL,
+		EventTypes: item.EventTypes,
+		Enabled:    item.Enabled,
+		Secret:     item.Secret,
+		CreatedAt:  item.CreatedAt,
+	}
+}
+
+func toProtoWebhookDelivery(item webhook.DeliveryItem) *chatv1.WebhookDeliveryItem {
+	return &chatv1.WebhookDeliveryItem{
+		DeliveryId:     item.DeliveryID,
+		WebhookId:      item.WebhookID,
+		EventId:        item.EventID,
+		EventType:      item.EventType,
+		Status:         item.Status,
+		Attempts:       int32(item.Attempts),
+		NextAttemptAt:  item.NextAttemptAt,
+		LastStatusCode: int32(item.LastStatusCode),
+		LastError:      item.LastError,
+		DeliveredAt:    item.DeliveredAt,
+		CreatedAt:      item.CreatedAt,
+		UpdatedAt:      item.UpdatedAt,
+	}
+}
@@ -0,0 +1,354 @@
+package im
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/channel"
+	chatdao "github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/impls/openai"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+)
+
+const (
+	defaultInboundStream  = "im:inbound"
+	defaultOutboundStream = "im:outbound"
+	defaultGroup          = "bot-platform"
+	defaultWorkers        = 4
+	defaultBatchSize      = 16
+	defaultClaimIdle      = 5 * time.Minute
+	defaultEditInterval   = 800 * time.Millisecond
+	defaultReplyTimeout   = 3 * time.Minute
+	defaultOutboundMaxLen = 100000
+	defaultDedupTTL       = 7 * 24 * time.Hour
+	retryDelay            = time.Second
+)
+
+var errInProgress = errors.New("event is being handled by another consumer")
+
+// Adapter drives bots from IM message events: it reads events from a
+// Source, runs them through the chat logic as if the sender had called
+// Stream, and publishes the reply to a Sink, editing it in place while it
+// streams. Events of one channel are handled in order by the same worker.
+// Delivery is at least once: an event whose consumer died mid-reply is
+// answered again under the same reply ID.
+type Adapter struct {
+	source       Source
+	sink         Sink
+	logic        chat.Logic
+	bindings     channel.Dao
+	chats        chatdao.Dao
+	dedup        *dedup
+	members      sync.Map
+	workers      int
+	batch        int
+	editInterval time.Duration
+	replyTimeout time.Duration
+	defaultModel string
+}
+
+func NewAdapter(ctx context.Context, svcCtx *svc.Context) (*Adapter, error) {
+	conf := svcCtx.Config.IMConf
+	logic, err := openai.NewChatLogic(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	a := &Adapter{
+		logic:        logic,
+		bindings:     svcCtx.Dao.ChannelDao,
+		chats:        svcCtx.Dao.ChatDao,
+		workers:      orDefault(conf.Workers, defaultWorkers),
+		batch:        orDefault(conf.BatchSize, defaultBatchSize),
+		editInterval: durationOr(time.Duration(conf.EditIntervalMs)*time.Millisecond, defaultEditInterval),
+		replyTimeout: durationOr(time.Duration(conf.ReplyTimeoutSeconds)*time.Second, defaultReplyTimeout),
+		defaultModel: conf.DefaultModel,
+	}
+	claimIdle := durationOr(time.Duration(conf.ClaimIdleSeconds)*time.Second, defaultClaimIdle)
+	a.dedup = &dedup{
+		rdb:     svcCtx.Infra.Redis,
+		doneTTL: durationOr(time.Duration(conf.DedupTTLHours)*time.Hour, defaultDedupTTL),
+		// The lock outlives a reply so only a crashed consumer's events
+		// are picked up again.
+		lockTTL: a.replyTimeout + time.Minute,
+	}
+
+	switch conf.Transport {
+	case "", "redis":
+		consumer := conf.Consumer
+		if consumer == "" {
+			host, _ := os.Hostname()
+			consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+		}
+		source, err := newRedisSource(ctx, svcCtx.Infra.Redis,
+			stringOr(conf.InboundStream, defaultInboundStream),
+			stringOr(conf.Group, defaultGroup),
+			consumer, int64(a.batch), claimIdle)
+		if err != nil {
+			return nil, err
+		}
+		a.source = source
+		a.sink = newRedisSink(svcCtx)
+	default:
+		return nil, fmt.Errorf("unsupported im transport %q", conf.Transport)
+	}
+	return a, nil
+}
+
+// Run consumes events until ctx is cancelled. Unacknowledged events are
+// redelivered by the source, so stopping mid-reply is safe.
+func (a *Adapter) Run(ctx context.Context) {
+	queues := make([]chan Delivery, a.workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan Delivery, a.batch)
+		wg.Add(1)
+		go func(q <-chan Delivery) {
+			defer wg.Done()
+			for d := range q {
+				a.process(ctx, d)
+			}
+		}(queues[i])
+	}
+	defer func() {
+		for _, q := range queues {
+			close(q)
+		}
+		wg.Wait()
+	}()
+
+	for ctx.Err() == nil {
+		deliveries, err := a.source.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.L().Errorf("receive im events error: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+		for _, d := range deliveries {
+			queues[a.shard(d)] <- d
+		}
+	}
+}
+
+func (a *Adapter) shard(d Delivery) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(d.Event.channelKey()))
+	return int(h.Sum32() % uint32(a.workers))
+}
+
+func (a *Adapter) process(ctx context.Context, d Delivery) {
+	if err := a.handle(ctx, d); err != nil {
+		if !errors.Is(err, errInProgress) && ctx.Err() == nil {
+			logger.L().Errorf("handle im event %s error: %v", d.Event.EventID, err)
+		}
+		return
+	}
+	if err := a.source.Ack(ctx, d); err != nil {
+		logger.L().Errorf("ack im event %s error: %v", d.Event.EventID, err)
+	}
+}
+
+// handle returns nil once the event may be acknowledged: it was answered,
+// was answered before, or can never be answered. Any other error leaves the
+// event pending for redelivery.
+func (a *Adapter) handle(ctx context.Context, d Delivery) error {
+	if d.Err != nil {
+		logger.L().Errorf("drop undecodable im event %s: %v", d.ID, d.Err)
+		return nil
+	}
+	ev := d.Event
+	if err := validate(ev); err != nil {
+		logger.L().Errorf("drop invalid im event %s: %v", d.ID, err)
+		return nil
+	}
+	done, err := a.dedup.done(ctx, ev.EventID)
+	if err != nil || done {
+		return err
+	}
+	locked, err := a.dedup.lock(ctx, ev.EventID)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return errInProgress
+	}
+	if err := a.reply(ctx, ev); err != nil {
+		a.dedup.unlock(context.Background(), ev.EventID)
+		return err
+	}
+	return a.dedup.finish(ctx, ev.EventID)
+}
+
+// reply answers one event. Chat failures are reported to the channel as a
+// failed reply; only failures to publish are returned.
+func (a *Adapter) reply(ctx context.Context, ev InboundEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, a.replyTimeout)
+	defer cancel()
+
+	out := &replyWriter{sink: a.sink, interval: a.editInterval, ev: OutboundEvent{
+		ReplyID:   "reply:" + ev.EventID,
+		InReplyTo: ev.EventID,
+		Platform:  ev.Platform,
+		ChannelID: ev.ChannelID,
+		ThreadID:  ev.ThreadID,
+		BotID:     ev.BotID,
+	}}
+	if err := a.stream(ctx, ev, out); err != nil {
+		if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ctx.Err()
+		}
+		return out.fail(context.Background(), err)
+	}
+	return nil
+}
+
+func (a *Adapter) stream(ctx context.Context, ev InboundEvent, out *replyWriter) error {
+	conversationID, err := a.conversationFor(ctx, ev)
+	if err != nil {
+		return err
+	}
+	model := ev.Model
+	if model == "" {
+		model = a.defaultModel
+	}
+	stream, conversationID, err := a.logic.ResponseStream(ctx, &chat.Completion{
+		ConversationID: conversationID,
+		BotID:          ev.BotID,
+		Model:          model,
+		Stream:         true,
+		Messages: []chat.Message{{
+			Role:        "user",
+			ContentType: "text",
+			Content:     ev.Text,
+			Mentions:    ev.Mentions,
+		}},
+	}, ev.UserID)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	out.ev.ConversationID = conversationID
+	if ev.ChannelType != ChannelTypeGroup {
+		if _, err := a.bind(ev, conversationID); err != nil {
+			return err
+		}
+	}
+
+	for {
+		chunk, done, err := stream.Next()
+		if err != nil {
+			return err
+		}
+		switch chunk.Type {
+		case chat.EventTextDelta:
+			if err := out.append(ctx, chunk.Delta); err != nil {
+				return err
+			}
+		case chat.EventImage:
+			if chunk.Image != nil && chunk.Image.URL != "" {
+				out.ev.Images = append(out.ev.Images, chunk.Image.URL)
+			}
+		}
+		if done {
+			out.ev.FinishReason = chunk.FinishReason
+			return out.complete(ctx)
+		}
+	}
+}
+
+// replyWriter turns text deltas into throttled in-place edits.
+type replyWriter struct {
+	sink      Sink
+	interval  time.Duration
+	ev        OutboundEvent
+	text      strings.Builder
+	published int
+	last      time.Time
+}
+
+func (w *replyWriter) append(ctx context.Context, delta string) error {
+	w.text.WriteString(delta)
+	if time.Since(w.last) < w.interval || w.text.Len() == w.published {
+		return nil
+	}
+	return w.publish(ctx, ReplyUpdated, "")
+}
+
+// complete publishes the final text. A bot that chose not to answer, such
+// as an unmentioned bot in a group, produces no text and no reply; a
+// filtered reply is still completed so the IM side can say why.
+func (w *replyWriter) complete(ctx context.Context) error {
+	if w.text.Len() == 0 && len(w.ev.Images) == 0 && w.ev.FinishReason != chat.FinishReasonContentFilter {
+		return nil
+	}
+	return w.publish(ctx, ReplyCompleted, "")
+}
+
+func (w *replyWriter) fail(ctx context.Context, err error) error {
+	return w.publish(ctx, ReplyFailed, err.Error())
+}
+
+func (w *replyWriter) publish(ctx context.Context, typ, errMsg string) error {
+	ev := w.ev
+	ev.Type = typ
+	ev.Seq++
+	ev.Text = w.text.String()
+	ev.Error = errMsg
+	ev.SentAt = time.Now().Unix()
+	if err := w.sink.Publish(ctx, ev); err != nil {
+		return err
+	}
+	w.ev.Seq = ev.Seq
+	w.published = w.text.Len()
+	w.last = time.Now()
+	return nil
+}
+
+func validate(ev InboundEvent) error {
+	switch {
+	case ev.EventID == "":
+		return errors.New("missing event_id")
+	case ev.Platform == "" || ev.ChannelID == "":
+		return errors.New("missing platform or channel_id")
+	case ev.UserID == "":
+		return errors.New("missing user_id")
+	case strings.TrimSpace(ev.Text) == "":
+		return errors.New("empty text")
+	}
+	return nil
+}
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func durationOr(v, def time.Duration) time.Duration {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func stringOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
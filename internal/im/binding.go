@@ -0,0 +1,113 @@
+package im
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// conversationFor returns the conversation bound to the event's channel, or
+// "" for a direct channel that has none yet; the chat logic creates that one
+// with the first reply and bind records it afterwards. Group channels get a
+// group conversation owned by a synthetic channel user, and every sender is
+// added as a participant so prompts can label speakers. A binding whose
+// conversation was trashed or purged is dropped and the channel starts over.
+func (a *Adapter) conversationFor(ctx context.Context, ev InboundEvent) (string, error) {
+	binding, err := a.liveBinding(ev)
+	if err != nil {
+		return "", err
+	}
+	if ev.ChannelType != ChannelTypeGroup {
+		if binding == nil {
+			return "", nil
+		}
+		return binding.ConversationID, nil
+	}
+
+	owner := channelOwner(ev)
+	if binding == nil {
+		item, err := a.logic.CreateGroupConversation(ctx, &chat.CreateGroupConversationReq{
+			BotID:        ev.BotID,
+			Participants: []chat.Participant{{UserID: ev.UserID, DisplayName: ev.DisplayName}},
+		}, owner)
+		if err != nil {
+			return "", err
+		}
+		if binding, err = a.bind(ev, item.ConversationID); err != nil {
+			return "", err
+		}
+		a.members.Store(binding.ConversationID+"\x00"+ev.UserID, true)
+	}
+	if err := a.ensureMember(ctx, binding.ConversationID, owner, ev); err != nil {
+		return "", err
+	}
+	return binding.ConversationID, nil
+}
+
+// liveBinding returns the channel's binding, or nil when there is none or
+// its conversation no longer exists.
+func (a *Adapter) liveBinding(ev InboundEvent) (*model.ChannelBinding, error) {
+	binding, err := a.bindings.GetBinding(ev.Platform, ev.ChannelID, ev.ThreadID, ev.BotID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = a.chats.GetConversationByID(binding.ConversationID)
+	if err == nil {
+		return binding, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := a.bindings.DeleteBinding(binding.ID); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// bind records the conversation for the channel and returns the binding
+// that won if another consumer bound the channel first.
+func (a *Adapter) bind(ev InboundEvent, conversationID string) (*model.ChannelBinding, error) {
+	err := a.bindings.CreateBinding(model.ChannelBinding{
+		Platform:       ev.Platform,
+		ChannelID:      ev.ChannelID,
+		ThreadID:       ev.ThreadID,
+		BotID:          ev.BotID,
+		ConversationID: conversationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.bindings.GetBinding(ev.Platform, ev.ChannelID, ev.ThreadID, ev.BotID)
+}
+
+func (a *Adapter) ensureMember(ctx context.Context, conversationID, owner string, ev InboundEvent) error {
+	key := conversationID + "\x00" + ev.UserID
+	if _, ok := a.members.Load(key); ok {
+		return nil
+	}
+	_, err := a.logic.AddParticipants(ctx, &chat.AddParticipantsReq{
+		ConversationID: conversationID,
+		Participants:   []chat.Participant{{UserID: ev.UserID, DisplayName: ev.DisplayName}},
+	}, owner)
+	if err != nil {
+		return err
+	}
+	a.members.Store(key, true)
+	return nil
+}
+
+// channelOwner is the synthetic user that owns a group channel's
+// conversation. It is hashed to fit the 36-character user ID columns.
+func channelOwner(ev InboundEvent) string {
+	sum := sha256.Sum256([]byte(ev.Platform + "\x00" + ev.ChannelID))
+	return "im:" + hex.EncodeToString(sum[:])[:32]
+}
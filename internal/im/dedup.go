@@ -0,0 +1,44 @@
+package im
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	doneKeyPrefix = "im:event:done:"
+	lockKeyPrefix = "im:event:lock:"
+)
+
+// dedup makes handling idempotent on event ID. An event is marked done only
+// after its reply was published; the lock keeps two consumers from working
+// on a redelivered event at the same time and expires so a crashed
+// consumer's events are retried.
+type dedup struct {
+	rdb     *redis.Client
+	doneTTL time.Duration
+	lockTTL time.Duration
+}
+
+func (d *dedup) done(ctx context.Context, eventID string) (bool, error) {
+	n, err := d.rdb.Exists(ctx, doneKeyPrefix+eventID).Result()
+	return n > 0, err
+}
+
+func (d *dedup) lock(ctx context.Context, eventID string) (bool, error) {
+	return d.rdb.SetNX(ctx, lockKeyPrefix+eventID, 1, d.lockTTL).Result()
+}
+
+func (d *dedup) finish(ctx context.Context, eventID string) error {
+	pipe := d.rdb.TxPipeline()
+	pipe.Set(ctx, doneKeyPrefix+eventID, 1, d.doneTTL)
+	pipe.Del(ctx, lockKeyPrefix+eventID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (d *dedup) unlock(ctx context.Context, eventID string) {
+	_ = d.rdb.Del(ctx, lockKeyPrefix+eventID).Err()
+}
@@ -0,0 +1,60 @@
+package im
+
+const (
+	ChannelTypeDirect = "direct"
+	ChannelTypeGroup  = "group"
+)
+
+// InboundEvent is a message posted in an IM channel. EventID must be unique
+// per message; redelivered events carry the same ID.
+type InboundEvent struct {
+	EventID     string   `json:"event_id"`
+	Platform    string   `json:"platform"`
+	ChannelID   string   `json:"channel_id"`
+	ChannelType string   `json:"channel_type"`
+	ThreadID    string   `json:"thread_id,omitempty"`
+	UserID      string   `json:"user_id"`
+	DisplayName string   `json:"display_name,omitempty"`
+	BotID       string   `json:"bot_id,omitempty"`
+	Text        string   `json:"text"`
+	Mentions    []string `json:"mentions,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	SentAt      int64    `json:"sent_at,omitempty"`
+}
+
+const (
+	// ReplyUpdated carries the reply text so far; the IM side edits the
+	// message identified by ReplyID in place.
+	ReplyUpdated = "reply.updated"
+	// ReplyCompleted carries the final text and ends the reply.
+	ReplyCompleted = "reply.completed"
+	ReplyFailed    = "reply.failed"
+	// MessagePosted is a message the bot posts on its own, such as a fired
+	// reminder. It answers no inbound event, so InReplyTo is empty.
+	MessagePosted = "message.posted"
+)
+
+// OutboundEvent is one step of a bot reply. ReplyID is derived from the
+// inbound event ID, so a reply redone after redelivery edits the same IM
+// message instead of posting a second one.
+type OutboundEvent struct {
+	Type           string   `json:"type"`
+	ReplyID        string   `json:"reply_id"`
+	InReplyTo      string   `json:"in_reply_to"`
+	Seq            int      `json:"seq"`
+	Platform       string   `json:"platform"`
+	ChannelID      string   `json:"channel_id"`
+	ThreadID       string   `json:"thread_id,omitempty"`
+	BotID          string   `json:"bot_id,omitempty"`
+	ConversationID string   `json:"conversation_id,omitempty"`
+	Text           string   `json:"text,omitempty"`
+	Images         []string `json:"images,omitempty"`
+	Error          string   `json:"error,omitempty"`
+	// FinishReason is content_filter when moderation cut the reply short.
+	FinishReason string `json:"finish_reason,omitempty"`
+	SentAt       int64  `json:"sent_at"`
+}
+
+func (e InboundEvent) channelKey() string {
+	return e.Platform + "\x00" + e.ChannelID + "\x00" + e.ThreadID + "\x00" + e.BotID
+}
@@ -0,0 +1,53 @@
+package im
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/channel"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+)
+
+// Notifier posts messages the bot sends on its own to every IM channel
+// bound to the conversation they belong to.
+type Notifier struct {
+	sink     Sink
+	bindings channel.Dao
+}
+
+func NewNotifier(svcCtx *svc.Context) (*Notifier, error) {
+	switch transport := svcCtx.Config.IMConf.Transport; transport {
+	case "", "redis":
+		return &Notifier{sink: newRedisSink(svcCtx), bindings: svcCtx.Dao.ChannelDao}, nil
+	default:
+		return nil, fmt.Errorf("unsupported im transport %q", transport)
+	}
+}
+
+// Post publishes text as a MessagePosted event. messageID identifies the
+// message on the bot side and keeps the ReplyID stable if the post is
+// retried. Conversations without bindings are skipped.
+func (n *Notifier) Post(ctx context.Context, conversationID, messageID, text string) error {
+	bindings, err := n.bindings.ListBindingsByConversation(conversationID)
+	if err != nil {
+		return err
+	}
+	for _, b := range bindings {
+		err := n.sink.Publish(ctx, OutboundEvent{
+			Type:           MessagePosted,
+			ReplyID:        "message:" + messageID,
+			Platform:       b.Platform,
+			ChannelID:      b.ChannelID,
+			ThreadID:       b.ThreadID,
+			BotID:          b.BotID,
+			ConversationID: conversationID,
+			Text:           text,
+			SentAt:         time.Now().Unix(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
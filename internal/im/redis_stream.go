@@ -0,0 +1,131 @@
+package im
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	payloadField = "event"
+	receiveBlock = 5 * time.Second
+)
+
+// redisSource reads a Redis stream through a consumer group. Entries left
+// pending by a crashed consumer are reclaimed once idle for claimIdle.
+type redisSource struct {
+	rdb       *redis.Client
+	stream    string
+	group     string
+	consumer  string
+	count     int64
+	claimIdle time.Duration
+	claimFrom string
+}
+
+func newRedisSource(ctx context.Context, rdb *redis.Client, stream, group, consumer string, count int64, claimIdle time.Duration) (*redisSource, error) {
+	err := rdb.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("create consumer group %s on %s: %w", group, stream, err)
+	}
+	return &redisSource{
+		rdb:       rdb,
+		stream:    stream,
+		group:     group,
+		consumer:  consumer,
+		count:     count,
+		claimIdle: claimIdle,
+		claimFrom: "0-0",
+	}, nil
+}
+
+func (s *redisSource) Receive(ctx context.Context) ([]Delivery, error) {
+	claimed, next, err := s.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   s.stream,
+		Group:    s.group,
+		Consumer: s.consumer,
+		MinIdle:  s.claimIdle,
+		Start:    s.claimFrom,
+		Count:    s.count,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	s.claimFrom = next
+	if len(claimed) > 0 {
+		return decodeMessages(claimed), nil
+	}
+
+	streams, err := s.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.consumer,
+		Streams:  []string{s.stream, ">"},
+		Count:    s.count,
+		Block:    receiveBlock,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Delivery
+	for _, st := range streams {
+		out = append(out, decodeMessages(st.Messages)...)
+	}
+	return out, nil
+}
+
+func (s *redisSource) Ack(ctx context.Context, d Delivery) error {
+	return s.rdb.XAck(ctx, s.stream, s.group, d.ID).Err()
+}
+
+func decodeMessages(messages []redis.XMessage) []Delivery {
+	out := make([]Delivery, 0, len(messages))
+	for _, msg := range messages {
+		d := Delivery{ID: msg.ID}
+		raw, ok := msg.Values[payloadField].(string)
+		if !ok {
+			d.Err = fmt.Errorf("missing %q field", payloadField)
+		} else if err := json.Unmarshal([]byte(raw), &d.Event); err != nil {
+			d.Err = err
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+type redisSink struct {
+	rdb    *redis.Client
+	stream string
+	maxLen int64
+}
+
+func newRedisSink(svcCtx *svc.Context) *redisSink {
+	conf := svcCtx.Config.IMConf
+	maxLen := conf.OutboundMaxLen
+	if maxLen <= 0 {
+		maxLen = defaultOutboundMaxLen
+	}
+	return &redisSink{rdb: svcCtx.Infra.Redis, stream: stringOr(conf.OutboundStream, defaultOutboundStream), maxLen: maxLen}
+}
+
+func (s *redisSink) Publish(ctx context.Context, ev OutboundEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return s.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{payloadField: string(b)},
+	}).Err()
+}
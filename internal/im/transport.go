@@ -0,0 +1,25 @@
+package im
+
+import "context"
+
+// Delivery is an inbound event read from a Source. Err is set when the
+// payload could not be decoded; such deliveries are acknowledged and
+// dropped.
+type Delivery struct {
+	ID    string
+	Event InboundEvent
+	Err   error
+}
+
+// Source delivers inbound events at least once. A delivery that is not
+// acknowledged is handed out again after a timeout, possibly to another
+// consumer.
+type Source interface {
+	Receive(ctx context.Context) ([]Delivery, error)
+	Ack(ctx context.Context, d Delivery) error
+}
+
+// Sink publishes reply events for the IM system.
+type Sink interface {
+	Publish(ctx context.Context, ev OutboundEvent) error
+}
@@ -0,0 +1,140 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+	"github.com/im-core-go/im-core-bot-platform/pkg/lease"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
+)
+
+const (
+	purgeLeaseKey        = "purge:leader"
+	defaultRetentionDays = 30
+	defaultPurgeInterval = time.Hour
+	defaultPurgeBatch    = 100
+)
+
+// Purger hard-deletes conversations that have been in the trash longer than
+// the retention period, along with their messages, attachments, stored
+// blobs and search vectors. Like ReminderWorker it runs on every replica,
+// but only the holder of a Redis lease purges.
+type Purger struct {
+	dao       chat.Dao
+	blob      blob.Store
+	index     vector.Index
+	lease     *lease.Lease
+	retention time.Duration
+	interval  time.Duration
+	batch     int
+}
+
+func NewPurger(svcCtx *svc.Context) *Purger {
+	conf := svcCtx.Config.TrashConf
+	p := &Purger{
+		dao:       svcCtx.Dao.ChatDao,
+		blob:      svcCtx.Infra.Blob,
+		index:     svcCtx.VectorIndex,
+		retention: time.Duration(conf.RetentionDays) * 24 * time.Hour,
+		interval:  time.Duration(conf.PurgeIntervalMinutes) * time.Minute,
+		batch:     conf.PurgeBatchSize,
+	}
+	if p.retention <= 0 {
+		p.retention = defaultRetentionDays * 24 * time.Hour
+	}
+	if p.interval <= 0 {
+		p.interval = defaultPurgeInterval
+	}
+	if p.batch <= 0 {
+		p.batch = defaultPurgeBatch
+	}
+	// The leader renews on every tick, so the lease must outlast a few.
+	p.lease = lease.New(svcCtx.Infra.Redis, purgeLeaseKey, 3*p.interval)
+	return p
+}
+
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	defer func() { _ = p.lease.Release(context.Background()) }()
+	for {
+		leader, err := p.lease.Acquire(ctx)
+		if err != nil {
+			logger.L().Errorf("purge lease error: %v", err)
+		} else if leader {
+			if n, err := p.PurgeOnce(ctx); err != nil {
+				logger.L().Errorf("purge trash error: %v", err)
+			} else if n > 0 {
+				logger.L().Infof("purged %d trashed conversations", n)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce purges expired conversations batch by batch until none are left
+// and returns how many were removed.
+func (p *Purger) PurgeOnce(ctx context.Context) (int, error) {
+	before := time.Now().Add(-p.retention).Unix()
+	purged := 0
+	for {
+		items, err := p.dao.ListExpiredTrash(before, p.batch)
+		if err != nil {
+			return purged, err
+		}
+		for _, item := range items {
+			if err := p.purge(ctx, item); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(items) < p.batch || ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context, conversation model.Conversation) error {
+	messages, err := p.dao.ListAllMessagesByConversation(conversation.UUID)
+	if err != nil {
+		return err
+	}
+	attachments, err := p.dao.ListAllAttachmentsByConversation(conversation.UUID)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for _, msg := range messages {
+		for _, img := range memory.DecodeImages(msg) {
+			if img.BlobKey != "" {
+				keys = append(keys, img.BlobKey)
+			}
+		}
+	}
+	for _, a := range attachments {
+		if a.BlobKey != "" {
+			keys = append(keys, a.BlobKey)
+		}
+	}
+	for _, key := range keys {
+		if err := p.blob.Delete(ctx, key); err != nil && !errors.Is(err, blob.ErrNotFound) {
+			return err
+		}
+	}
+	if err := p.index.DeleteScope(ctx, conversation.UUID); err != nil {
+		return err
+	}
+	return p.dao.PurgeConversation(conversation.UUID)
+}
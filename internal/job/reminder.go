@@ -0,0 +1,205 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/reminder"
+	"github.com/im-core-go/im-core-bot-platform/internal/im"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	remindlogic "github.com/im-core-go/im-core-bot-platform/internal/logic/reminder"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/lease"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	reminderLeaseKey      = "reminder:leader"
+	defaultReminderPoll   = 10 * time.Second
+	defaultReminderBatch  = 100
+	defaultReminderLease  = 30 * time.Second
+	reminderRetryDelay    = time.Minute
+	reminderTrashedDelay  = time.Hour
+	reminderNotifyTimeout = 10 * time.Second
+)
+
+// ReminderWorker fires due reminders. It runs on every replica, but only
+// the holder of a Redis lease fires, so each reminder is posted once. A
+// fired reminder becomes a bot message in its conversation, is announced
+// through the webhook outbox and, when the conversation is bound to IM
+// channels, posted there as well.
+type ReminderWorker struct {
+	dao      reminder.Dao
+	chat     chat.Dao
+	memory   memory.Manager
+	notifier *im.Notifier
+	lease    *lease.Lease
+	interval time.Duration
+	batch    int
+}
+
+func NewReminderWorker(svcCtx *svc.Context) (*ReminderWorker, error) {
+	conf := svcCtx.Config.ReminderConf
+	w := &ReminderWorker{
+		dao:  svcCtx.Dao.ReminderDao,
+		chat: svcCtx.Dao.ChatDao,
+		memory: memory.NewManager(
+			svcCtx.Dao.ChatDao,
+			func() int64 { return svcCtx.Utils.SnowFlake.Generate().Int64() },
+			func() string { return svcCtx.Utils.UUID.New() },
+			memory.WithWebhookOutbox(svcCtx.Config.WebhookConf.Enabled),
+		),
+		interval: time.Duration(conf.PollIntervalSeconds) * time.Second,
+		batch:    conf.BatchSize,
+	}
+	if w.interval <= 0 {
+		w.interval = defaultReminderPoll
+	}
+	if w.batch <= 0 {
+		w.batch = defaultReminderBatch
+	}
+	ttl := time.Duration(conf.LeaseSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultReminderLease
+	}
+	// The leader renews on every tick, so the lease must outlast a few.
+	if ttl < 3*w.interval {
+		ttl = 3 * w.interval
+	}
+	w.lease = lease.New(svcCtx.Infra.Redis, reminderLeaseKey, ttl)
+	if svcCtx.Config.IMConf.Enabled {
+		notifier, err := im.NewNotifier(svcCtx)
+		if err != nil {
+			return nil, err
+		}
+		w.notifier = notifier
+	}
+	return w, nil
+}
+
+func (w *ReminderWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	defer func() { _ = w.lease.Release(context.Background()) }()
+	for {
+		leader, err := w.lease.Acquire(ctx)
+		if err != nil {
+			logger.L().Errorf("reminder lease error: %v", err)
+		} else if leader {
+			if n, err := w.FireOnce(ctx); err != nil {
+				logger.L().Errorf("fire reminders error: %v", err)
+			} else if n > 0 {
+				logger.L().Infof("fired %d reminders", n)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FireOnce fires one batch of due reminders and returns how many were
+// posted. Reminders missed while no worker ran fire once, not once per
+// missed run.
+func (w *ReminderWorker) FireOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	items, err := w.dao.ListDueReminders(now.Unix(), w.batch)
+	if err != nil {
+		return 0, err
+	}
+	fired := 0
+	for _, item := range items {
+		ok, err := w.fire(ctx, item, now)
+		if err != nil {
+			return fired, err
+		}
+		if ok {
+			fired++
+		}
+		if ctx.Err() != nil {
+			return fired, ctx.Err()
+		}
+	}
+	return fired, nil
+}
+
+// skipMissing handles a reminder whose conversation is gone. A trashed
+// conversation may still be restored, so its reminders wait for the next
+// run; purging deletes them, and any left behind are cancelled here.
+func (w *ReminderWorker) skipMissing(r model.Reminder, now time.Time) error {
+	_, err := w.chat.GetTrashedConversationByID(r.ConversationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return w.dao.UpdateReminder(r.UUID, map[string]interface{}{
+			"status": model.ReminderStatusCancelled,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	next := now.Add(reminderTrashedDelay)
+	if r.Kind == model.ReminderKindCron {
+		if n, err := remindlogic.NextRun(r.CronExpr, r.Timezone, now); err == nil {
+			next = n
+		}
+	}
+	return w.dao.UpdateReminder(r.UUID, map[string]interface{}{
+		"next_run_at": next.Unix(),
+	})
+}
+
+func (w *ReminderWorker) fire(ctx context.Context, r model.Reminder, now time.Time) (bool, error) {
+	if _, err := w.chat.GetConversationByID(r.ConversationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, w.skipMissing(r, now)
+		}
+		return false, err
+	}
+
+	fields := map[string]interface{}{
+		"last_run_at": now.Unix(),
+		"run_count":   gorm.Expr("run_count + 1"),
+	}
+	if r.Kind == model.ReminderKindCron {
+		next, err := remindlogic.NextRun(r.CronExpr, r.Timezone, now)
+		if err != nil {
+			logger.L().Errorf("reminder %s has no next run: %v", r.UUID, err)
+			fields["status"] = model.ReminderStatusDone
+		} else {
+			fields["next_run_at"] = next.Unix()
+		}
+	} else {
+		fields["status"] = model.ReminderStatusDone
+	}
+	// Claiming the run before posting keeps a reminder from being posted
+	// twice; a post that fails is rescheduled below.
+	claimed, err := w.dao.ClaimRun(r.UUID, r.NextRunAt, fields)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	msg, err := w.memory.SaveReminderMessage(ctx, r)
+	if err != nil {
+		logger.L().Errorf("post reminder %s error: %v", r.UUID, err)
+		return false, w.dao.UpdateReminder(r.UUID, map[string]interface{}{
+			"status":      model.ReminderStatusActive,
+			"next_run_at": now.Add(reminderRetryDelay).Unix(),
+			"run_count":   gorm.Expr("run_count - 1"),
+		})
+	}
+	if w.notifier != nil {
+		nctx, cancel := context.WithTimeout(ctx, reminderNotifyTimeout)
+		if err := w.notifier.Post(nctx, r.ConversationID, strconv.FormatInt(msg.ID, 10), msg.Content); err != nil {
+			logger.L().Errorf("notify reminder %s error: %v", r.UUID, err)
+		}
+		cancel()
+	}
+	return true, nil
+}
@@ -0,0 +1,303 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/webhook"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	signer "github.com/im-core-go/im-core-bot-platform/pkg/webhook"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWebhookAttempts = 10
+	defaultWebhookPoll     = 5 * time.Second
+	defaultWebhookBatch    = 50
+	defaultWebhookTimeout  = 10 * time.Second
+	webhookBackoffBase     = 10 * time.Second
+	webhookBackoffMax      = 6 * time.Hour
+	webhookErrorMaxLen     = 500
+)
+
+// WebhookEnvelope is the JSON body posted to subscribers.
+type WebhookEnvelope struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	CreatedAt      int64           `json:"created_at"`
+	BotID          string          `json:"bot_id"`
+	ConversationID string          `json:"conversation_id"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// WebhookWorker drains the webhook outbox into per-subscription deliveries
+// and sends due deliveries, retrying failures with exponential backoff
+// until MaxAttempts, after which the delivery is dead-lettered.
+type WebhookWorker struct {
+	dao         webhook.Dao
+	newID       func() int64
+	client      *http.Client
+	maxAttempts int
+	interval    time.Duration
+	batch       int
+	timeout     time.Duration
+}
+
+func NewWebhookWorker(svcCtx *svc.Context) *WebhookWorker {
+	conf := svcCtx.Config.WebhookConf
+	w := &WebhookWorker{
+		dao:         svcCtx.Dao.WebhookDao,
+		newID:       func() int64 { return svcCtx.Utils.SnowFlake.Generate().Int64() },
+		maxAttempts: conf.MaxAttempts,
+		interval:    time.Duration(conf.PollIntervalSeconds) * time.Second,
+		batch:       conf.BatchSize,
+		timeout:     time.Duration(conf.TimeoutSeconds) * time.Second,
+	}
+	if w.maxAttempts <= 0 {
+		w.maxAttempts = defaultWebhookAttempts
+	}
+	if w.interval <= 0 {
+		w.interval = defaultWebhookPoll
+	}
+	if w.batch <= 0 {
+		w.batch = defaultWebhookBatch
+	}
+	if w.timeout <= 0 {
+		w.timeout = defaultWebhookTimeout
+	}
+	w.client = &http.Client{Timeout: w.timeout}
+	return w
+}
+
+func (w *WebhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.DispatchOnce(ctx); err != nil {
+			logger.L().Errorf("webhook dispatch error: %v", err)
+		}
+		if err := w.DeliverOnce(ctx); err != nil {
+			logger.L().Errorf("webhook delivery error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce fans pending outbox events out to the subscriptions that
+// match them.
+func (w *WebhookWorker) DispatchOnce(ctx context.Context) error {
+	for {
+		events, err := w.dao.ListPendingEvents(w.batch)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		subscriptions, err := w.dao.ListEnabledSubscriptions()
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := w.dispatch(event, subscriptions); err != nil {
+				return err
+			}
+		}
+		if len(events) < w.batch || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *WebhookWorker) dispatch(event model.WebhookEvent, subscriptions []model.WebhookSubscription) error {
+	botID := event.BotID
+	if botID == "" && event.ConversationID != "" {
+		var err error
+		if botID, err = w.dao.ConversationBotID(event.ConversationID); err != nil {
+			return err
+		}
+	}
+	now := time.Now().Unix()
+	var deliveries []model.WebhookDelivery
+	for _, sub := range subscriptions {
+		if !subscribed(sub, botID, event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			ID:             w.newID(),
+			SubscriptionID: sub.UUID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	_, err := w.dao.DispatchEvent(event.ID, botID, deliveries)
+	return err
+}
+
+func subscribed(sub model.WebhookSubscription, botID, eventType string) bool {
+	if sub.BotID != "" && sub.BotID != botID {
+		return false
+	}
+	types := sub.Types()
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliverOnce sends every delivery that is currently due.
+func (w *WebhookWorker) DeliverOnce(ctx context.Context) error {
+	for {
+		now := time.Now()
+		// The lease outlasts one request so a slow send is not picked up
+		// twice, and expires soon enough to recover from a crashed worker.
+		lease := now.Add(2*w.timeout + time.Minute).Unix()
+		items, err := w.dao.ClaimDueDeliveries(now.Unix(), lease, w.batch)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := w.deliver(ctx, item); err != nil {
+				return err
+			}
+		}
+		if len(items) < w.batch || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *WebhookWorker) deliver(ctx context.Context, delivery model.WebhookDelivery) error {
+	sub, err := w.dao.GetSubscription(delivery.SubscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return w.dead(delivery, delivery.Attempts, 0, "subscription deleted")
+	}
+	if err != nil {
+		return err
+	}
+	if !sub.Enabled {
+		return w.dead(delivery, delivery.Attempts, 0, "subscription disabled")
+	}
+	event, err := w.dao.GetEvent(delivery.EventID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return w.dead(delivery, delivery.Attempts, 0, "event not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	code, sendErr := w.send(ctx, *sub, *event, delivery.ID)
+	attempts := delivery.Attempts + 1
+	if sendErr == nil {
+		return w.dao.UpdateDelivery(delivery.ID, map[string]interface{}{
+			"status":           model.WebhookDeliveryDelivered,
+			"attempts":         attempts,
+			"last_status_code": code,
+			"last_error":       "",
+			"delivered_at":     time.Now().Unix(),
+		})
+	}
+	if attempts >= w.maxAttempts {
+		return w.dead(delivery, attempts, code, sendErr.Error())
+	}
+	return w.dao.UpdateDelivery(delivery.ID, map[string]interface{}{
+		"attempts":         attempts,
+		"last_status_code": code,
+		"last_error":       truncateError(sendErr.Error()),
+		"next_attempt_at":  time.Now().Add(webhookBackoff(attempts)).Unix(),
+	})
+}
+
+func (w *WebhookWorker) dead(delivery model.WebhookDelivery, attempts, code int, reason string) error {
+	logger.L().Errorf("webhook delivery %d dead-lettered: %s", delivery.ID, reason)
+	return w.dao.UpdateDelivery(delivery.ID, map[string]interface{}{
+		"status":           model.WebhookDeliveryDead,
+		"attempts":         attempts,
+		"last_status_code": code,
+		"last_error":       truncateError(reason),
+	})
+}
+
+// send posts the signed envelope and treats any 2xx response as success.
+func (w *WebhookWorker) send(ctx context.Context, sub model.WebhookSubscription, event model.WebhookEvent, deliveryID int64) (int, error) {
+	data := json.RawMessage("{}")
+	if event.Data != nil && *event.Data != "" {
+		data = json.RawMessage(*event.Data)
+	}
+	body, err := json.Marshal(WebhookEnvelope{
+		ID:             strconv.FormatInt(event.ID, 10),
+		Type:           event.Type,
+		CreatedAt:      event.CreatedAt,
+		BotID:          event.BotID,
+		ConversationID: event.ConversationID,
+		Data:           data,
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signer.HeaderEvent, event.Type)
+	req.Header.Set(signer.HeaderID, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(signer.HeaderSignature, signer.Sign(sub.Secret, time.Now().Unix(), body))
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff doubles the wait after each failed attempt, starting at
+// webhookBackoffBase and capped at webhookBackoffMax.
+func webhookBackoff(attempts int) time.Duration {
+	d := webhookBackoffBase
+	for i := 1; i < attempts && d < webhookBackoffMax; i++ {
+		d *= 2
+	}
+	if d > webhookBackoffMax {
+		d = webhookBackoffMax
+	}
+	return d
+}
+
+// truncateError cuts s to the error column without splitting a rune.
+func truncateError(s string) string {
+	if len(s) <= webhookErrorMaxLen {
+		return s
+	}
+	n := webhookErrorMaxLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,11 @@
+package attachment
+
+import (
+	"context"
+	"io"
+)
+
+type Logic interface {
+	Upload(ctx context.Context, req *UploadReq, r io.Reader, userID string) (*Item, error)
+	Get(ctx context.Context, req *GetReq, userID string) (*Item, error)
+}
@@ -0,0 +1,140 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/attachment"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+	"github.com/im-core-go/im-core-bot-platform/pkg/extract"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+)
+
+const (
+	defaultMaxBytes = 20 << 20
+	maxErrorLen     = 512
+)
+
+type logicImpl struct {
+	dao      attachment.Dao
+	blob     blob.Store
+	newUUID  func() string
+	maxBytes int64
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	maxBytes := svcCtx.Config.AttachmentConf.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+	return &logicImpl{
+		dao:      svcCtx.Dao.AttachmentDao,
+		blob:     svcCtx.Infra.Blob,
+		newUUID:  func() string { return svcCtx.Utils.UUID.New() },
+		maxBytes: maxBytes,
+	}
+}
+
+func (l *logicImpl) Upload(ctx context.Context, req *UploadReq, r io.Reader, userID string) (*Item, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	fileName := path.Base(strings.TrimSpace(strings.ReplaceAll(req.FileName, "\\", "/")))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return nil, errors.New("missing file_name")
+	}
+	data, err := io.ReadAll(io.LimitReader(r, l.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty file")
+	}
+	if int64(len(data)) > l.maxBytes {
+		return nil, fmt.Errorf("file too large: max %d bytes", l.maxBytes)
+	}
+
+	id := l.newUUID()
+	mimeType := extract.DetectType(fileName, req.MimeType, data)
+	key := "attachments/" + userID + "/" + id + strings.ToLower(filepath.Ext(fileName))
+	if _, err := l.blob.Put(ctx, key, bytes.NewReader(data), mimeType); err != nil {
+		return nil, err
+	}
+
+	entity := model.Attachment{
+		UUID:     id,
+		UserID:   userID,
+		FileName: fileName,
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+		BlobKey:  key,
+		Status:   model.AttachmentStatusReady,
+	}
+	text, err := extract.Text(mimeType, data)
+	switch {
+	case errors.Is(err, extract.ErrUnsupported):
+		entity.Status = model.AttachmentStatusUnsupported
+	case err != nil:
+		logger.L().Errorf("extract attachment %s error: %v", id, err)
+		entity.Status = model.AttachmentStatusFailed
+		entity.Error = truncate(err.Error(), maxErrorLen)
+	case strings.TrimSpace(text) == "":
+		entity.Status = model.AttachmentStatusFailed
+		entity.Error = "no text found"
+	default:
+		entity.Text = text
+	}
+	if err := l.dao.CreateAttachment(entity); err != nil {
+		_ = l.blob.Delete(ctx, key)
+		return nil, err
+	}
+	return toItem(&entity), nil
+}
+
+func (l *logicImpl) Get(ctx context.Context, req *GetReq, userID string) (*Item, error) {
+	if req.AttachmentID == "" {
+		return nil, errors.New("missing attachment_id")
+	}
+	entity, err := l.dao.GetAttachmentByID(req.AttachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && entity.UserID != userID {
+		return nil, errors.New("forbidden")
+	}
+	return toItem(entity), nil
+}
+
+func toItem(entity *model.Attachment) *Item {
+	return &Item{
+		AttachmentID:   entity.UUID,
+		ConversationID: entity.ConversationID,
+		MessageID:      entity.MessageID,
+		FileName:       entity.FileName,
+		MimeType:       entity.MimeType,
+		Size:           entity.Size,
+		Status:         entity.Status,
+		Error:          entity.Error,
+		CreatedAt:      entity.CreatedAt,
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,22 @@
+package attachment
+
+type UploadReq struct {
+	FileName string
+	MimeType string
+}
+
+type GetReq struct {
+	AttachmentID string
+}
+
+type Item struct {
+	AttachmentID   string
+	ConversationID string
+	MessageID      int64
+	FileName       string
+	MimeType       string
+	Size           int64
+	Status         string
+	Error          string
+	CreatedAt      int64
+}
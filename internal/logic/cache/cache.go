@@ -0,0 +1,280 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	entryKeyPrefix = "chat:cache:entry:"
+	indexKeyPrefix = "chat:cache:index:"
+
+	defaultTTL                = 24 * time.Hour
+	defaultSimilarity         = 0.92
+	defaultMaxSemanticEntries = 500
+)
+
+const (
+	HitExact    = "exact"
+	HitSemantic = "semantic"
+)
+
+// Scope separates entries that must not answer each other: another bot,
+// another model or different system prompts give different replies.
+type Scope struct {
+	BotID  string
+	Model  string
+	System []string
+}
+
+func (s Scope) hash() string {
+	return digest(append([]string{s.BotID, s.Model}, s.System...)...)
+}
+
+// Entry is a cached reply. Vector is the normalized embedding of the
+// question, kept only when semantic matching is on.
+type Entry struct {
+	Question  string          `json:"question"`
+	Content   string          `json:"content"`
+	Reasoning string          `json:"reasoning,omitempty"`
+	Citations []chat.Citation `json:"citations,omitempty"`
+	Vector    []float32       `json:"vector,omitempty"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+// Reply is what Store keeps of a completed answer.
+type Reply struct {
+	Content   string
+	Reasoning string
+	Citations []chat.Citation
+}
+
+type Hit struct {
+	Kind  string
+	Score float32
+	Entry Entry
+}
+
+// Query is one question looked up and, on a miss, stored under the same
+// keys; it carries the embedding so the question is embedded once.
+type Query struct {
+	scope      string
+	key        string
+	normalized string
+	vector     []float32
+}
+
+// Cache keeps replies in Redis. Exact entries expire on their own; each
+// scope's semantic index is a sorted set of entry keys scored by expiry,
+// trimmed on read and write.
+type Cache struct {
+	conf     configs.CacheConfig
+	rdb      redis.Cmdable
+	embedder chat.Embedder
+	bots     map[string]bool
+}
+
+// New returns nil when the cache is disabled.
+func New(conf configs.CacheConfig, rdb redis.Cmdable, embedder chat.Embedder) (*Cache, error) {
+	if !conf.Enabled {
+		return nil, nil
+	}
+	if rdb == nil {
+		return nil, errors.New("response cache needs redis")
+	}
+	if conf.SimilarityThreshold < 0 || conf.SimilarityThreshold > 1 {
+		return nil, errors.New("cache similarity threshold must be between 0 and 1")
+	}
+	if conf.Semantic && embedder == nil {
+		return nil, errors.New("semantic cache needs an embedder")
+	}
+	c := &Cache{conf: conf, rdb: rdb, embedder: embedder}
+	if len(conf.Bots) > 0 {
+		c.bots = make(map[string]bool, len(conf.Bots))
+		for _, id := range conf.Bots {
+			c.bots[id] = true
+		}
+	}
+	return c, nil
+}
+
+// Covers reports whether replies of the bot are cached. A nil cache covers
+// nothing.
+func (c *Cache) Covers(botID string) bool {
+	return c != nil && (c.bots == nil || c.bots[botID])
+}
+
+// NewQuery prepares a lookup; nil when the question is empty after
+// normalization.
+func (c *Cache) NewQuery(scope Scope, question string) *Query {
+	normalized := Normalize(question)
+	if normalized == "" {
+		return nil
+	}
+	s := scope.hash()
+	return &Query{scope: s, key: digest(s, normalized), normalized: normalized}
+}
+
+// Lookup returns the exact entry for the query or, with semantic matching
+// on, the most similar one above the threshold; nil on a miss.
+func (c *Cache) Lookup(ctx context.Context, q *Query) (*Hit, error) {
+	raw, err := c.rdb.Get(ctx, entryKeyPrefix+q.key).Bytes()
+	switch {
+	case err == nil:
+		var e Entry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, err
+		}
+		return &Hit{Kind: HitExact, Score: 1, Entry: e}, nil
+	case !errors.Is(err, redis.Nil):
+		return nil, err
+	}
+	if !c.conf.Semantic {
+		return nil, nil
+	}
+	return c.lookupSimilar(ctx, q)
+}
+
+func (c *Cache) lookupSimilar(ctx context.Context, q *Query) (*Hit, error) {
+	index := indexKeyPrefix + q.scope
+	now := time.Now()
+	if err := c.rdb.ZRemRangeByScore(ctx, index, "-inf", strconv.FormatInt(now.Unix(), 10)).Err(); err != nil {
+		return nil, err
+	}
+	keys, err := c.rdb.ZRevRange(ctx, index, 0, int64(c.maxSemanticEntries()-1)).Result()
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	if err := c.embed(ctx, q); err != nil {
+		return nil, err
+	}
+	entryKeys := make([]string, len(keys))
+	for i, k := range keys {
+		entryKeys[i] = entryKeyPrefix + k
+	}
+	values, err := c.rdb.MGet(ctx, entryKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	var best *Hit
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(s), &e); err != nil || len(e.Vector) != len(q.vector) {
+			continue
+		}
+		score := vector.Dot(q.vector, e.Vector)
+		if float64(score) >= c.similarityThreshold() && (best == nil || score > best.Score) {
+			best = &Hit{Kind: HitSemantic, Score: score, Entry: e}
+		}
+	}
+	if best != nil {
+		best.Entry.Vector = nil
+	}
+	return best, nil
+}
+
+// Store caches a reply to the query and, with semantic matching on, adds it
+// to the scope's index.
+func (c *Cache) Store(ctx context.Context, q *Query, reply Reply) error {
+	if strings.TrimSpace(reply.Content) == "" {
+		return nil
+	}
+	if c.conf.Semantic {
+		if err := c.embed(ctx, q); err != nil {
+			return err
+		}
+	}
+	ttl := c.ttl()
+	now := time.Now()
+	raw, err := json.Marshal(Entry{
+		Question:  q.normalized,
+		Content:   reply.Content,
+		Reasoning: reply.Reasoning,
+		Citations: reply.Citations,
+		Vector:    q.vector,
+		CreatedAt: now.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, entryKeyPrefix+q.key, raw, ttl)
+	if c.conf.Semantic {
+		index := indexKeyPrefix + q.scope
+		pipe.ZAdd(ctx, index, redis.Z{Score: float64(now.Add(ttl).Unix()), Member: q.key})
+		pipe.ZRemRangeByRank(ctx, index, 0, int64(-c.maxSemanticEntries()-1))
+		pipe.Expire(ctx, index, ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *Cache) embed(ctx context.Context, q *Query) error {
+	if q.vector != nil {
+		return nil
+	}
+	vectors, err := c.embedder.Embed(ctx, []string{q.normalized})
+	if err != nil {
+		return err
+	}
+	if len(vectors) == 0 || len(vectors[0]) == 0 {
+		return errors.New("empty question embedding")
+	}
+	q.vector = vector.Normalize(vectors[0])
+	return nil
+}
+
+func (c *Cache) ttl() time.Duration {
+	if c.conf.TTLSeconds > 0 {
+		return time.Duration(c.conf.TTLSeconds) * time.Second
+	}
+	return defaultTTL
+}
+
+func (c *Cache) similarityThreshold() float64 {
+	if c.conf.SimilarityThreshold > 0 {
+		return c.conf.SimilarityThreshold
+	}
+	return defaultSimilarity
+}
+
+func (c *Cache) maxSemanticEntries() int {
+	if c.conf.MaxSemanticEntries > 0 {
+		return c.conf.MaxSemanticEntries
+	}
+	return defaultMaxSemanticEntries
+}
+
+// Normalize folds case, collapses whitespace and drops trailing
+// punctuation, so trivially different spellings of a question share a key.
+func Normalize(question string) string {
+	s := strings.Join(strings.Fields(strings.ToLower(question)), " ")
+	return strings.TrimRightFunc(s, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+func digest(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -11,6 +11,31 @@ type Logic interface {
 	UpdateConversationTitle(ctx context.Context, req *UpdateConversationTitleReq, userID string) error
 	DeleteConversation(ctx context.Context, req *DeleteConversationReq, userID string) error
 	ClearMessages(ctx context.Context, req *ClearMessagesReq, userID string) error
+	ListTrash(ctx context.Context, req *ListTrashReq, userID string) (*ListConversationsResp, error)
+	RestoreConversation(ctx context.Context, req *RestoreConversationReq, userID string) error
+	PinConversation(ctx context.Context, req *PinConversationReq, userID string) error
+	ArchiveConversation(ctx context.Context, req *ArchiveConversationReq, userID string) error
+	MoveConversation(ctx context.Context, req *MoveConversationReq, userID string) error
+	SetConversationTags(ctx context.Context, req *SetConversationTagsReq, userID string) ([]string, error)
+	ListTags(ctx context.Context, userID string) ([]TagItem, error)
+	CreateFolder(ctx context.Context, req *CreateFolderReq, userID string) (*FolderItem, error)
+	ListFolders(ctx context.Context, userID string) ([]FolderItem, error)
+	RenameFolder(ctx context.Context, req *RenameFolderReq, userID string) error
+	DeleteFolder(ctx context.Context, req *DeleteFolderReq, userID string) error
+	CreateGroupConversation(ctx context.Context, req *CreateGroupConversationReq, userID string) (*ConversationItem, error)
+	ListParticipants(ctx context.Context, req *ListParticipantsReq, userID string) ([]ParticipantItem, error)
+	AddParticipants(ctx context.Context, req *AddParticipantsReq, userID string) ([]ParticipantItem, error)
+	RemoveParticipant(ctx context.Context, req *RemoveParticipantReq, userID string) error
+	SetParticipantRole(ctx context.Context, req *SetParticipantRoleReq, userID string) error
 	PullModules(ctx context.Context) (*ModelListResp, error)
 	BuildUserSystemPrompt(ctx context.Context, userID string) (string, error)
 }
+
+type Embedder interface {
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+}
+
+// Completer runs one non-streaming completion outside any conversation.
+type Completer interface {
+	Complete(ctx context.Context, model string, messages []Message) (string, error)
+}
@@ -0,0 +1,227 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+// Prefix starts a command message.
+const Prefix = "/"
+
+// ErrUsage is returned by argument parsers for malformed arguments; the
+// router answers with the command's usage instead of failing the request.
+var ErrUsage = errors.New("invalid arguments")
+
+// ArgParser turns the text after the command name into the value passed to
+// the handler as Call.Args.
+type ArgParser func(raw string) (any, error)
+
+// Handler runs a command and returns the events streamed back in place of
+// a model reply. A done event is appended unless the handler ends with one.
+type Handler func(ctx context.Context, call *Call) ([]chat.StreamEvent, error)
+
+type Command struct {
+	// Name is matched case-insensitively, without the leading slash.
+	Name string
+	// Usage shows the arguments, e.g. "<model>"; empty for none.
+	Usage       string
+	Description string
+	// OwnerOnly limits the command to the conversation owner; in direct
+	// conversations that is the user.
+	OwnerOnly bool
+	Args      ArgParser
+	Handle    Handler
+}
+
+// Call is one invocation of a command.
+type Call struct {
+	Command      *Command
+	Args         any
+	UserID       string
+	Role         string
+	Model        string
+	Conversation *model.Conversation
+}
+
+// Policy reports whether a bot accepts a command.
+type Policy func(botID, name string) bool
+
+func AllowAll(string, string) bool { return true }
+
+// ConfigPolicy enables commands per bot from the command config: a bot
+// listed in Bots accepts exactly those commands, other bots accept
+// DefaultCommands, or everything when that is empty.
+func ConfigPolicy(conf configs.CommandConfig) Policy {
+	normalize := func(names []string) map[string]bool {
+		set := make(map[string]bool, len(names))
+		for _, n := range names {
+			set[strings.ToLower(strings.TrimPrefix(strings.TrimSpace(n), Prefix))] = true
+		}
+		return set
+	}
+	defaults := normalize(conf.DefaultCommands)
+	bots := make(map[string]map[string]bool, len(conf.Bots))
+	for botID, names := range conf.Bots {
+		bots[botID] = normalize(names)
+	}
+	return func(botID, name string) bool {
+		if set, ok := bots[botID]; ok {
+			return set[name]
+		}
+		return len(defaults) == 0 || defaults[name]
+	}
+}
+
+// Router matches command messages to registered commands. Messages that are
+// not commands, or name a command the bot does not accept, are left for the
+// model.
+type Router struct {
+	policy   Policy
+	commands map[string]*Command
+	order    []string
+}
+
+func NewRouter(policy Policy) *Router {
+	if policy == nil {
+		policy = AllowAll
+	}
+	return &Router{policy: policy, commands: make(map[string]*Command)}
+}
+
+func (r *Router) Register(cmd Command) error {
+	name := strings.ToLower(cmd.Name)
+	if !validName(name) {
+		return fmt.Errorf("invalid command name %q", cmd.Name)
+	}
+	if cmd.Handle == nil {
+		return fmt.Errorf("command %q has no handler", name)
+	}
+	if _, ok := r.commands[name]; ok {
+		return fmt.Errorf("command %q already registered", name)
+	}
+	cmd.Name = name
+	r.commands[name] = &cmd
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Parse splits a command message into its lower-cased name and raw
+// arguments. "/etc/hosts" and "/ alone" are not commands.
+func Parse(text string) (name, args string, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, Prefix) {
+		return "", "", false
+	}
+	text = text[len(Prefix):]
+	name, args, _ = strings.Cut(text, " ")
+	if i := strings.IndexAny(name, "\t\n"); i >= 0 {
+		name, args = name[:i], text[i+1:]
+	}
+	name = strings.ToLower(name)
+	if !validName(name) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(args), true
+}
+
+// Match returns the command a message invokes on the bot, if any.
+func (r *Router) Match(botID, text string) (*Command, string, bool) {
+	name, args, ok := Parse(text)
+	if !ok {
+		return nil, "", false
+	}
+	cmd, ok := r.commands[name]
+	if !ok || !r.policy(botID, name) {
+		return nil, "", false
+	}
+	return cmd, args, true
+}
+
+// Run parses the arguments and runs the command. Usage and permission
+// problems are answered in the reply rather than returned as errors.
+func (r *Router) Run(ctx context.Context, cmd *Command, rawArgs string, call Call) ([]chat.StreamEvent, error) {
+	call.Command = cmd
+	if cmd.OwnerOnly && call.Role != model.ParticipantRoleOwner {
+		return Reply(fmt.Sprintf("%s%s can only be used by the conversation owner.", Prefix, cmd.Name)), nil
+	}
+	if cmd.Args != nil {
+		args, err := cmd.Args(rawArgs)
+		if errors.Is(err, ErrUsage) {
+			return Reply("Usage: " + r.usage(cmd)), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		call.Args = args
+	} else if rawArgs != "" {
+		return Reply("Usage: " + r.usage(cmd)), nil
+	}
+	return cmd.Handle(ctx, &call)
+}
+
+// Help lists the commands the bot accepts, one per line, in registration
+// order.
+func (r *Router) Help(botID string) string {
+	var b strings.Builder
+	b.WriteString("Available commands:")
+	for _, name := range r.order {
+		if !r.policy(botID, name) {
+			continue
+		}
+		cmd := r.commands[name]
+		b.WriteString("\n")
+		b.WriteString(r.usage(cmd))
+		if cmd.Description != "" {
+			b.WriteString(" - ")
+			b.WriteString(cmd.Description)
+		}
+	}
+	return b.String()
+}
+
+func (r *Router) usage(cmd *Command) string {
+	if cmd.Usage == "" {
+		return Prefix + cmd.Name
+	}
+	return Prefix + cmd.Name + " " + cmd.Usage
+}
+
+// Reply is the events of a plain text answer.
+func Reply(text string) []chat.StreamEvent {
+	return []chat.StreamEvent{{Type: chat.EventTextDelta, Delta: text}}
+}
+
+// Text parses free-form arguments. With required set, empty arguments are
+// a usage error; maxLen of 0 means unlimited.
+func Text(required bool, maxLen int) ArgParser {
+	return func(raw string) (any, error) {
+		if required && raw == "" {
+			return nil, ErrUsage
+		}
+		if maxLen > 0 && len([]rune(raw)) > maxLen {
+			return nil, ErrUsage
+		}
+		return raw, nil
+	}
+}
+
+func validName(name string) bool {
+	if name == "" || len(name) > 32 {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package openai
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+func (l *logicImpl) listMessageAttachments(messages []model.Message) (map[int64][]chat.AttachmentRef, error) {
+	ids := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == "user" {
+			ids = append(ids, msg.ID)
+		}
+	}
+	items, err := l.svcCtx.Dao.AttachmentDao.ListAttachmentsByMessages(ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64][]chat.AttachmentRef, len(items))
+	for _, item := range items {
+		out[item.MessageID] = append(out[item.MessageID], chat.AttachmentRef{
+			AttachmentID: item.UUID,
+			FileName:     item.FileName,
+			MimeType:     item.MimeType,
+			Size:         item.Size,
+		})
+	}
+	return out, nil
+}
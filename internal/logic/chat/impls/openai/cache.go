@@ -0,0 +1,181 @@
+package openai
+
+import (
+	"context"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/cache"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/experiment"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/pii"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	"time"
+)
+
+const (
+	defaultReplayChunk = 20
+	cacheStoreTimeout  = 30 * time.Second
+	finishReasonStop   = "stop"
+)
+
+func newResponseCache(svcCtx *svc.Context, embedder chat.Embedder) (*cache.Cache, error) {
+	return cache.New(svcCtx.Config.CacheConf, svcCtx.Infra.Redis, embedder)
+}
+
+// cacheRequest is what decides whether and under which key a reply is
+// cached. Eligible is cleared by the caller for input that must always
+// reach the model: images, attachments, or input moderation or injection
+// screening flagged.
+type cacheRequest struct {
+	ConversationID string
+	BotID          string
+	Model          string
+	Question       string
+	SystemPrompt   string
+	Assignment     *experiment.Assignment
+	Control        chat.CacheControl
+	Eligible       bool
+}
+
+// cacheQuery returns nil when the request bypasses the cache. Only the
+// first question of a conversation is cached: the key does not cover
+// earlier turns, so a later reply could carry one user's history to
+// another. Questions carrying personal data are never cached either.
+func (l *logicImpl) cacheQuery(ctx context.Context, session *pii.Session, r cacheRequest) *cache.Query {
+	if !r.Eligible || !l.cache.Covers(r.BotID) || (r.Control.NoLookup && r.Control.NoStore) {
+		return nil
+	}
+	// The question itself is already saved.
+	if n, err := l.memory.CountMessages(ctx, r.ConversationID); err != nil || n != 1 {
+		return nil
+	}
+	if redacted, err := session.Redact(r.Question); err != nil || redacted != r.Question {
+		return nil
+	}
+	scope := cache.Scope{BotID: r.BotID, Model: r.Model, System: []string{r.SystemPrompt}}
+	if a := r.Assignment; a != nil {
+		scope.System = append(scope.System, a.ExperimentID+"/"+a.Variant.Name)
+	}
+	return l.cache.NewQuery(scope, r.Question)
+}
+
+// lookupCache treats cache errors as misses; the cache only saves calls.
+func (l *logicImpl) lookupCache(ctx context.Context, query *cache.Query, control chat.CacheControl) *cache.Hit {
+	if query == nil || control.NoLookup {
+		return nil
+	}
+	hit, err := l.cache.Lookup(ctx, query)
+	if err != nil {
+		logger.L().Errorf("response cache lookup error: %v", err)
+		return nil
+	}
+	return hit
+}
+
+// storeCache caches a completed reply in the background. Replies that were
+// cut short, filtered, flagged or written from redacted data are not cached.
+func (l *logicImpl) storeCache(query *cache.Query, control chat.CacheControl, session *pii.Session, meta messageMeta, reply cache.Reply, finishReason string, flagged bool) {
+	if query == nil || control.NoStore || flagged || session.Redacted() || (finishReason != "" && finishReason != finishReasonStop) {
+		return
+	}
+	if _, ok := meta["injection"]; ok {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cacheStoreTimeout)
+		defer cancel()
+		if err := l.cache.Store(ctx, query, reply); err != nil {
+			logger.L().Errorf("response cache store error: %v", err)
+		}
+	}()
+}
+
+// cacheMeta marks a reply as served from the cache.
+func cacheMeta(hit *cache.Hit) messageMeta {
+	meta := messageMeta{"cache": map[string]any{
+		"hit":   hit.Kind,
+		"score": hit.Score,
+	}}
+	if len(hit.Entry.Citations) > 0 {
+		meta["citations"] = hit.Entry.Citations
+	}
+	return meta
+}
+
+func (l *logicImpl) replayChunkChars() int {
+	if n := l.svcCtx.Config.CacheConf.ReplayChunkChars; n > 0 {
+		return n
+	}
+	return defaultReplayChunk
+}
+
+// newCachedStream replays a cached reply as deltas of chunk runes, reasoning
+// first, so clients handle it like a live one.
+func newCachedStream(entry cache.Entry, chunk int) chat.MessageStream {
+	var events []chat.StreamEvent
+	events = appendDeltas(events, chat.EventReasoningDelta, entry.Reasoning, chunk)
+	events = appendDeltas(events, chat.EventTextDelta, entry.Content, chunk)
+	events = append(events, chat.StreamEvent{Type: chat.EventDone, Cached: true})
+	return newStaticStream(events...)
+}
+
+func appendDeltas(events []chat.StreamEvent, typ chat.StreamEventType, text string, chunk int) []chat.StreamEvent {
+	runes := []rune(text)
+	for start := 0; start < len(runes); start += chunk {
+		end := min(start+chunk, len(runes))
+		events = append(events, chat.StreamEvent{Type: typ, Delta: string(runes[start:end])})
+	}
+	return events
+}
+
+// replayCached streams a cached reply and saves it to the conversation like
+// a live one.
+func (l *logicImpl) replayCached(ctx context.Context, req *chat.Completion, userID string, hit *cache.Hit) chat.MessageStream {
+	meta := cacheMeta(hit)
+	started := time.Now()
+	var stream chat.MessageStream
+	stream = newPersistedStream(newCachedStream(hit.Entry, l.replayChunkChars()), req.IncludeReasoning, func(result streamResult) error {
+		in := l.replyInput(nil, req.Model, started, result.FirstTokenAt, nil)
+		in.Content = result.Content
+		in.Reasoning = result.Reasoning
+		in.Meta = meta.String()
+		reply, err := l.memory.SaveAssistantMessage(ctx, req.ConversationID, in)
+		if err != nil {
+			return err
+		}
+		l.indexMessages(reply)
+		if title, ok := l.generateTitle(req.ConversationID, req.Model); ok {
+			l.setStreamTitle(stream, title)
+		}
+		return nil
+	})
+	l.setStreamContext(req.ConversationID, hit.Entry.Citations, stream)
+	return stream
+}
+
+// replyFromCache answers CreateConversation with a cached reply.
+func (l *logicImpl) replyFromCache(ctx context.Context, conversationID, userID, modelName string, userMsg model.Message, hit *cache.Hit) (*chat.CreateConversationResp, error) {
+	meta := cacheMeta(hit).String()
+	in := l.replyInput(nil, modelName, time.Now(), time.Time{}, nil)
+	in.Content = hit.Entry.Content
+	in.Reasoning = hit.Entry.Reasoning
+	in.Meta = meta
+	replyMsg, err := l.memory.SaveAssistantMessage(ctx, conversationID, in)
+	if err != nil {
+		return nil, err
+	}
+	l.indexMessages(userMsg, replyMsg)
+	l.generateTitleAsync(conversationID, modelName)
+
+	return &chat.CreateConversationResp{
+		ConversationID: conversationID,
+		Title:          "New",
+		Reply: chat.Message{
+			Role:        "assistant",
+			ContentType: "text",
+			Content:     hit.Entry.Content,
+			Meta:        meta,
+		},
+		Cached: true,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCacheQueryOnlyFirstTurn(t *testing.T) {
+	mem := &fakeMemory{counts: map[string]int64{
+		"fresh":     1,
+		"history-a": 5,
+		"history-b": 3,
+	}}
+	l, _ := newTestLogic(t, configs.Config{}, mem)
+	// Nothing reaches Redis: these requests never get as far as a lookup.
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	t.Cleanup(func() { _ = rdb.Close() })
+	c, err := cache.New(configs.CacheConfig{Enabled: true}, rdb, nil)
+	if err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	l.cache = c
+
+	request := func(conversationID string) cacheRequest {
+		return cacheRequest{
+			ConversationID: conversationID,
+			BotID:          "bot-1",
+			Model:          testModel,
+			Question:       "What did I just say?",
+			Eligible:       true,
+		}
+	}
+	if q := l.cacheQuery(context.Background(), nil, request("fresh")); q == nil {
+		t.Error("first question of a conversation not cached")
+	}
+	// Two conversations asking the same follow-up must neither read the
+	// other's reply nor leave theirs behind.
+	for _, id := range []string{"history-a", "history-b"} {
+		if q := l.cacheQuery(context.Background(), nil, request(id)); q != nil {
+			t.Errorf("%s: follow-up question got a cache query", id)
+		}
+	}
+	mem.err = errNotFound
+	if q := l.cacheQuery(context.Background(), nil, request("fresh")); q != nil {
+		t.Error("cache query when the history could not be counted")
+	}
+}
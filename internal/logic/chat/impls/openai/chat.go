@@ -6,8 +6,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	chatdao "github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/cache"
 	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/command"
 	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/experiment"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/export"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/injection"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/knowledge"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/moderation"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/pii"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/reminder"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/search"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
 	"github.com/im-core-go/im-core-bot-platform/internal/svc"
 	http2 "github.com/im-core-go/im-core-bot-platform/pkg/http"
 	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
@@ -15,15 +27,29 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 )
 
 type logicImpl struct {
-	svcCtx  *svc.Context
-	utils   *utils.Utils
-	urls    *urls
-	authKey string
-	headers map[string]string
-	memory  memory.Manager
+	svcCtx    *svc.Context
+	utils     *utils.Utils
+	urls      *urls
+	authKey   string
+	headers   map[string]string
+	memory    memory.Manager
+	images    *imagePolicy
+	knowledge knowledge.Logic
+	search    search.Logic
+	export    export.Logic
+	reminders reminder.Logic
+	commands  *command.Router
+	moderator moderation.Moderator
+	pii       *pii.Engine
+	injection *injection.Heuristics
+	// experiments is nil when A/B experiments are disabled.
+	experiments *experiment.Assigner
+	// cache is nil when the response cache is disabled.
+	cache *cache.Cache
 }
 
 const (
@@ -31,53 +57,114 @@ const (
 	defaultPageSize   = 20
 	maxPageSize       = 100
 	titleMessageLimit = 4
+	indexTimeout      = 30 * time.Second
 )
 
 type completionMessage struct {
 	Role    string `json:"role"`
-	Content string `json:"content"`
+	Content any    `json:"content"`
 }
 
 type completionRequest struct {
 	Model    string              `json:"model"`
 	Messages []completionMessage `json:"messages"`
 	Stream   bool                `json:"stream"`
+	generationOptions
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// generationOptions are the sampling settings an experiment variant may
+// override; zero values leave the provider defaults.
+type generationOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type completionResponse struct {
 	Choices []struct {
 		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
+			Role string `json:"role"`
+			completionDelta
 		} `json:"message"`
 	} `json:"choices"`
+	Usage *completionUsage `json:"usage"`
 }
 
-func NewChatLogic(svcCtx *svc.Context) (chat.Logic, error) {
+func loadCredentials(svcCtx *svc.Context) (string, map[string]string, error) {
 	key := os.Getenv("OPENAI_KEY")
 	if key == "" {
-		return nil, errors.New("empty openai key")
+		return "", nil, errors.New("empty openai key")
 	}
 	baseURL := svcCtx.Config.LLMRequestConf.OpenAI.BaseURL
 	if baseURL == "" {
-		return nil, errors.New("empty openai base url")
+		return "", nil, errors.New("empty openai base url")
 	}
 	headers := map[string]string{
 		"Authorization": "Bearer " + key,
 		"Content-Type":  "application/json",
 	}
-	return &logicImpl{
+	return baseURL, headers, nil
+}
+
+func NewChatLogic(svcCtx *svc.Context) (chat.Logic, error) {
+	baseURL, headers, err := loadCredentials(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	u := newURLs(baseURL)
+	piiEngine, err := newPIIEngine(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	embedder := newEmbedder(svcCtx, u, headers, piiEngine)
+	moderator, err := newModerator(svcCtx, u, headers)
+	if err != nil {
+		return nil, err
+	}
+	heuristics, err := newInjectionHeuristics(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	experiments, err := newExperimentAssigner(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	responseCache, err := newResponseCache(svcCtx, embedder)
+	if err != nil {
+		return nil, err
+	}
+	l := &logicImpl{
 		svcCtx:  svcCtx,
 		utils:   svcCtx.Utils,
-		urls:    newURLs(baseURL),
-		authKey: "Bearer " + key,
+		urls:    u,
+		authKey: headers["Authorization"],
 		headers: headers,
 		memory: memory.NewManager(
 			svcCtx.Dao.ChatDao,
 			func() int64 { return svcCtx.Utils.SnowFlake.Generate().Int64() },
 			func() string { return svcCtx.Utils.UUID.New() },
+			memory.WithReasoningInPrompt(svcCtx.Config.LLMRequestConf.OpenAI.IncludeReasoningInPrompt),
+			memory.WithAttachments(svcCtx.Dao.AttachmentDao, svcCtx.Config.AttachmentConf.PromptTokenBudget),
+			memory.WithWebhookOutbox(svcCtx.Config.WebhookConf.Enabled),
 		),
-	}, nil
+		images:      newImagePolicy(svcCtx.Config.LLMRequestConf.OpenAI, svcCtx.Config.ImageInputConf),
+		knowledge:   knowledge.NewLogic(svcCtx, embedder),
+		search:      search.NewLogic(svcCtx, embedder),
+		export:      export.NewLogic(svcCtx),
+		reminders:   reminder.NewLogic(svcCtx),
+		moderator:   moderator,
+		pii:         piiEngine,
+		injection:   heuristics,
+		experiments: experiments,
+		cache:       responseCache,
+	}
+	l.commands = l.newCommandRouter()
+	return l, nil
 }
 
 func (l *logicImpl) ResponseStream(ctx context.Context, req *chat.Completion, userID string) (chat.MessageStream, string, error) {
@@ -88,54 +175,210 @@ func (l *logicImpl) ResponseStream(ctx context.Context, req *chat.Completion, us
 		return nil, "", errors.New("empty message")
 	}
 
-	conversationID, err := l.memory.EnsureConversation(ctx, userID, req.ConversationID)
+	lastInput := req.Messages[len(req.Messages)-1]
+	if req.Mode != chat.CompletionModeImage {
+		stream, handled, err := l.runCommand(ctx, req, lastInput, userID)
+		if err != nil {
+			return nil, "", err
+		}
+		if handled {
+			return stream, req.ConversationID, nil
+		}
+	}
+	if req.Mode == chat.CompletionModeImage && strings.TrimSpace(lastInput.Content) == "" {
+		return nil, "", errors.New("empty image prompt")
+	}
+	images, err := l.images.buildImageParts(req.Model, lastInput.Images)
 	if err != nil {
 		return nil, "", err
 	}
-	req.ConversationID = conversationID
 
-	lastInput := req.Messages[len(req.Messages)-1]
-	userMsg, err := l.memory.SaveUserMessage(ctx, req.ConversationID, memory.MessageInput{
-		Role:        lastInput.Role,
-		ContentType: lastInput.ContentType,
-		Content:     lastInput.Content,
-		Meta:        lastInput.Meta,
-	})
+	// A new conversation is only created once its first message passed the
+	// checks below, so a blocked one leaves nothing behind.
+	conversation := &model.Conversation{BotID: req.BotID, Kind: model.ConversationKindDirect}
+	if req.ConversationID != "" {
+		if conversation, err = l.memory.EnsureConversation(ctx, userID, req.ConversationID, req.BotID); err != nil {
+			return nil, "", err
+		}
+		req.BotID = conversation.BotID
+	}
+	var assignment *experiment.Assignment
+	if req.Mode != chat.CompletionModeImage {
+		assignment = l.assignExperiment(conversation, userID)
+	}
+	modelName := req.Model
+	if conversation.Model != "" {
+		modelName = conversation.Model
+	} else if assignment != nil && assignment.Variant.Model != "" {
+		modelName = assignment.Variant.Model
+	}
+	if req.Mode != chat.CompletionModeImage && modelName != req.Model {
+		req.Model = modelName
+		if images, err = l.images.buildImageParts(req.Model, lastInput.Images); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Group messages not addressed to the bot are only stored, so they get
+	// the required moderation but not the injection classifier.
+	addressed := l.addressed(conversation, lastInput)
+	session := l.piiSession(req.BotID)
+	policy := l.moderationPolicy(req.BotID)
+	content, inputRecord, blocked, err := l.moderateInput(ctx, session, policy.Input, lastInput.Content)
 	if err != nil {
 		return nil, "", err
 	}
+	guard := l.injectionGuard(req.BotID, req.Model, session)
+	var inputDetection injection.Detection
+	injected := false
+	if addressed && !blocked {
+		inputDetection, injected = guard.screenInput(ctx, req.ConversationID, content)
+	}
+	if blocked || injected {
+		if blocked {
+			logInputBlocked(req.ConversationID, userID, inputRecord)
+		}
+		return newStaticStream(chat.StreamEvent{
+			Type:           chat.EventDone,
+			ConversationID: req.ConversationID,
+			FinishReason:   chat.FinishReasonContentFilter,
+		}), req.ConversationID, nil
+	}
+	if req.ConversationID == "" {
+		if conversation, err = l.memory.EnsureConversation(ctx, userID, "", req.BotID); err != nil {
+			return nil, "", err
+		}
+		req.ConversationID = conversation.UUID
+	}
 
-	promptMessages, err := l.memory.BuildPrompt(ctx, req.ConversationID, userMsg, req.Model, func(ctx context.Context, modelName string, messages []memory.PromptMessage) (string, error) {
-		return l.doCompletionFromPrompt(ctx, modelName, messages)
+	if err := l.storeUploads(ctx, req.ConversationID, images); err != nil {
+		return nil, "", err
+	}
+	userMsg, err := l.memory.SaveUserMessage(ctx, req.ConversationID, memory.MessageInput{
+		Role:          lastInput.Role,
+		ContentType:   lastInput.ContentType,
+		Content:       content,
+		Images:        images,
+		AttachmentIDs: lastInput.AttachmentIDs,
+		UserID:        userID,
+		Meta:          lastInput.Meta,
+		Moderation:    inputRecord.String(),
 	})
 	if err != nil {
 		return nil, "", err
 	}
+	l.indexMessages(userMsg)
+
+	if !addressed {
+		return newStaticStream(chat.StreamEvent{Type: chat.EventDone, ConversationID: req.ConversationID}), req.ConversationID, nil
+	}
+
+	if req.Mode == chat.CompletionModeImage {
+		prompt, err := session.Redact(userMsg.Content)
+		if err != nil {
+			return nil, "", err
+		}
+		stream, err := l.imageResponseStream(ctx, req, prompt)
+		if err != nil {
+			return nil, "", err
+		}
+		return stream, req.ConversationID, nil
+	}
+
 	systemPrompt, err := l.BuildUserSystemPrompt(ctx, userID)
 	if err != nil {
 		return nil, "", err
 	}
+	query := l.cacheQuery(ctx, session, cacheRequest{
+		ConversationID: req.ConversationID,
+		BotID:          req.BotID,
+		Model:          req.Model,
+		Question:       userMsg.Content,
+		SystemPrompt:   systemPrompt,
+		Assignment:     assignment,
+		Control:        req.Cache,
+		Eligible:       len(images) == 0 && len(lastInput.AttachmentIDs) == 0 && inputRecord.Action == moderation.ActionNone && !inputDetection.Flagged,
+	})
+	if hit := l.lookupCache(ctx, query, req.Cache); hit != nil {
+		return l.replayCached(ctx, req, userID, hit), req.ConversationID, nil
+	}
+
+	promptMessages, err := l.memory.BuildPrompt(ctx, req.ConversationID, userMsg, req.Model, l.redactedSummarizer(session))
+	if err != nil {
+		return nil, "", err
+	}
 	if systemPrompt != "" {
 		promptMessages = append([]memory.PromptMessage{{Role: "system", Content: systemPrompt}}, promptMessages...)
 	}
+	if promptMessages, err = withVariantPrompt(assignment, req.BotID, req.Model, promptMessages); err != nil {
+		return nil, "", err
+	}
+	meta := messageMeta{}
+	references, citations, private := l.retrieveContext(ctx, req.BotID, userID, userMsg.Content, guard)
+	if private {
+		// The reply draws on the user's own knowledge base; caching it
+		// would replay it to everyone else asking the same.
+		query = nil
+	}
+	if references != nil {
+		promptMessages = insertBeforeLast(promptMessages, *references)
+		meta["citations"] = citations
+	}
+	promptMessages = guard.guardPrompt(promptMessages, inputDetection)
+	guard.record(meta)
+	if promptMessages, err = redactPrompt(session, promptMessages); err != nil {
+		return nil, "", err
+	}
+	promptMessages = withPIIInstruction(session, promptMessages)
+	logRedactions(session, req.ConversationID)
 
-	sr, err := l.doStreamCompletion(ctx, req.Model, promptMessages)
+	started := time.Now()
+	sr, err := l.doStreamCompletion(ctx, req.Model, promptMessages, variantOptions(assignment))
 	if err != nil {
 		return nil, "", err
 	}
 
-	stream := newOpenAIChatCompletionsStream(sr)
+	// Output is moderated while still redacted, then restored for the user.
+	var stream chat.MessageStream = newOpenAIChatCompletionsStream(sr, l.svcCtx.Config.LLMRequestConf.OpenAI.StreamUsage)
+	var moderated *moderatedStream
+	if policy.Output != moderation.ActionNone {
+		moderated = newModeratedStream(stream, l.outputChunkChars(), func(text string) (string, moderation.Record, bool) {
+			return l.moderate(ctx, policy.Output, text)
+		})
+		stream = moderated
+	}
+	if session.Redacted() {
+		stream = newRestoredStream(stream, session)
+	}
 	var streamWithStore chat.MessageStream
-	streamWithStore = newPersistedStream(stream, func(content string) error {
-		if err := l.memory.SaveAssistantMessage(ctx, req.ConversationID, content); err != nil {
+	streamWithStore = newPersistedStream(stream, req.IncludeReasoning, func(result streamResult) error {
+		var outputRecord moderation.Record
+		if moderated != nil {
+			outputRecord = moderated.Record()
+		}
+		in := l.replyInput(assignment, req.Model, started, result.FirstTokenAt, result.Usage)
+		in.Content = result.Content
+		in.Reasoning = result.Reasoning
+		in.Meta = meta.String()
+		in.Moderation = outputRecord.String()
+		reply, err := l.memory.SaveAssistantMessage(ctx, req.ConversationID, in)
+		if err != nil {
 			return err
 		}
+		l.indexMessages(reply)
+		if result.Finished {
+			l.storeCache(query, req.Cache, session, meta, cache.Reply{
+				Content:   result.Content,
+				Reasoning: result.Reasoning,
+				Citations: citations,
+			}, result.FinishReason, outputRecord.Action != moderation.ActionNone)
+		}
 		if title, ok := l.generateTitle(req.ConversationID, req.Model); ok {
 			l.setStreamTitle(streamWithStore, title)
 		}
 		return nil
 	})
-	l.setStreamContext(req.ConversationID, streamWithStore)
+	l.setStreamContext(req.ConversationID, citations, streamWithStore)
 	return streamWithStore, req.ConversationID, nil
 }
 
@@ -169,41 +412,134 @@ func (l *logicImpl) CreateConversation(ctx context.Context, req *chat.CreateConv
 	if req.Model == "" {
 		return nil, errors.New("missing model")
 	}
-	if strings.TrimSpace(req.Message.Content) == "" {
+	if strings.TrimSpace(req.Message.Content) == "" && len(req.Message.Images) == 0 && len(req.Message.AttachmentIDs) == 0 {
 		return nil, errors.New("empty message")
 	}
+	images, err := l.images.buildImageParts(req.Model, req.Message.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything that can refuse the message runs before the conversation
+	// is created, so a blocked first message leaves nothing behind.
+	assignment := l.assignExperiment(&model.Conversation{BotID: req.BotID}, userID)
+	if assignment != nil && assignment.Variant.Model != "" && assignment.Variant.Model != req.Model {
+		req.Model = assignment.Variant.Model
+		if images, err = l.images.buildImageParts(req.Model, req.Message.Images); err != nil {
+			return nil, err
+		}
+	}
 
-	conversationID, err := l.memory.EnsureConversation(ctx, userID, "")
+	session := l.piiSession(req.BotID)
+	policy := l.moderationPolicy(req.BotID)
+	content, inputRecord, blocked, err := l.moderateInput(ctx, session, policy.Input, req.Message.Content)
 	if err != nil {
 		return nil, err
 	}
+	if blocked {
+		logInputBlocked("", userID, inputRecord)
+		return nil, errContentFiltered
+	}
+	guard := l.injectionGuard(req.BotID, req.Model, session)
+	inputDetection, injected := guard.screenInput(ctx, "", content)
+	if injected {
+		return nil, errContentFiltered
+	}
 
+	conversation, err := l.memory.EnsureConversation(ctx, userID, "", req.BotID)
+	if err != nil {
+		return nil, err
+	}
+	conversationID := conversation.UUID
+
+	if err := l.storeUploads(ctx, conversationID, images); err != nil {
+		return nil, err
+	}
 	userMsg, err := l.memory.SaveUserMessage(ctx, conversationID, memory.MessageInput{
-		Role:        req.Message.Role,
-		ContentType: req.Message.ContentType,
-		Content:     req.Message.Content,
-		Meta:        req.Message.Meta,
+		Role:          req.Message.Role,
+		ContentType:   req.Message.ContentType,
+		Content:       content,
+		Images:        images,
+		AttachmentIDs: req.Message.AttachmentIDs,
+		UserID:        userID,
+		Meta:          req.Message.Meta,
+		Moderation:    inputRecord.String(),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	prompt := []memory.PromptMessage{{Role: userMsg.Role, Content: userMsg.Content}}
 	systemPrompt, err := l.BuildUserSystemPrompt(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	query := l.cacheQuery(ctx, session, cacheRequest{
+		ConversationID: conversationID,
+		BotID:          conversation.BotID,
+		Model:          req.Model,
+		Question:       userMsg.Content,
+		SystemPrompt:   systemPrompt,
+		Assignment:     assignment,
+		Control:        req.Cache,
+		Eligible:       len(images) == 0 && len(req.Message.AttachmentIDs) == 0 && inputRecord.Action == moderation.ActionNone && !inputDetection.Flagged,
+	})
+	if hit := l.lookupCache(ctx, query, req.Cache); hit != nil {
+		return l.replyFromCache(ctx, conversationID, userID, req.Model, userMsg, hit)
+	}
+
+	prompt, err := l.memory.BuildPrompt(ctx, conversationID, userMsg, req.Model, l.redactedSummarizer(session))
+	if err != nil {
+		return nil, err
+	}
 	if systemPrompt != "" {
 		prompt = append([]memory.PromptMessage{{Role: "system", Content: systemPrompt}}, prompt...)
 	}
-	reply, err := l.doCompletionFromPrompt(ctx, req.Model, prompt)
+	if prompt, err = withVariantPrompt(assignment, conversation.BotID, req.Model, prompt); err != nil {
+		return nil, err
+	}
+	prompt = guard.guardPrompt(prompt, inputDetection)
+	if prompt, err = redactPrompt(session, prompt); err != nil {
+		return nil, err
+	}
+	prompt = withPIIInstruction(session, prompt)
+	logRedactions(session, conversationID)
+	messages, err := l.visionMessages(ctx, req.Model, prompt)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	reply, usage, err := l.doCompletionRequest(ctx, completionRequest{
+		Model:             req.Model,
+		Messages:          messages,
+		generationOptions: variantOptions(assignment),
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := l.memory.SaveAssistantMessage(ctx, conversationID, reply); err != nil {
+	replyContent, outputRecord, blocked := l.moderate(ctx, policy.Output, reply.Content)
+	replyContent = session.Restore(replyContent)
+	finishReason := ""
+	if blocked {
+		finishReason = chat.FinishReasonContentFilter
+	}
+
+	meta := messageMeta{}
+	guard.record(meta)
+	in := l.replyInput(assignment, req.Model, started, time.Time{}, usage)
+	in.Content = replyContent
+	in.Reasoning = reply.reasoningText()
+	in.Meta = meta.String()
+	in.Moderation = outputRecord.String()
+	replyMsg, err := l.memory.SaveAssistantMessage(ctx, conversationID, in)
+	if err != nil {
 		return nil, err
 	}
+	l.indexMessages(userMsg, replyMsg)
+	l.storeCache(query, req.Cache, session, meta, cache.Reply{
+		Content:   replyContent,
+		Reasoning: in.Reasoning,
+	}, finishReason, outputRecord.Action != moderation.ActionNone)
 
 	l.generateTitleAsync(conversationID, req.Model)
 
@@ -213,8 +549,9 @@ func (l *logicImpl) CreateConversation(ctx context.Context, req *chat.CreateConv
 		Reply: chat.Message{
 			Role:        "assistant",
 			ContentType: "text",
-			Content:     reply,
+			Content:     replyContent,
 		},
+		FinishReason: finishReason,
 	}, nil
 }
 
@@ -222,45 +559,70 @@ func (l *logicImpl) ListConversations(ctx context.Context, req *chat.ListConvers
 	if userID == "" {
 		return nil, errors.New("missing user")
 	}
+	filter := chatdao.ConversationFilter{
+		FolderID:    req.FolderID,
+		Tag:         strings.ToLower(strings.TrimSpace(req.Tag)),
+		BotID:       req.BotID,
+		Archived:    req.Archived,
+		UpdatedFrom: req.UpdatedFrom,
+		UpdatedTo:   req.UpdatedTo,
+	}
+	if req.Cursor != "" {
+		return l.listConversationsByCursor(ctx, req, userID, filter)
+	}
 	page, pageSize := normalizePaging(req.Page, req.PageSize)
 	offset := (page - 1) * pageSize
 
-	items, total, err := l.memory.ListConversations(ctx, userID, offset, pageSize)
+	items, total, err := l.memory.ListConversations(ctx, userID, filter, offset, pageSize)
 	if err != nil {
 		return nil, err
 	}
-	respItems := make([]chat.ConversationItem, 0, len(items))
-	for _, item := range items {
-		respItems = append(respItems, chat.ConversationItem{
-			ConversationID: item.UUID,
-			Title:          item.Title,
-			CreatedAt:      item.CreatedAt,
-			UpdatedAt:      item.UpdatedAt,
-		})
+	respItems, err := l.toConversationItems(ctx, items)
+	if err != nil {
+		return nil, err
 	}
-	return &chat.ListConversationsResp{
+	resp := &chat.ListConversationsResp{
 		Total:    total,
 		Page:     page,
 		PageSize: pageSize,
 		Items:    respItems,
-	}, nil
+	}
+	if len(items) > 0 && int64(offset+len(items)) < total {
+		resp.NextCursor = l.conversationCursor(cursorNext, items[len(items)-1])
+	}
+	if len(items) > 0 && offset > 0 {
+		resp.PrevCursor = l.conversationCursor(cursorPrev, items[0])
+	}
+	return resp, nil
+}
+
+func (l *logicImpl) toConversationItems(ctx context.Context, items []model.Conversation) ([]chat.ConversationItem, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.UUID)
+	}
+	tags, err := l.memory.ListConversationTags(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	respItems := make([]chat.ConversationItem, 0, len(items))
+	for _, item := range items {
+		respItems = append(respItems, toConversationItem(item, tags[item.UUID]))
+	}
+	return respItems, nil
 }
 
 func (l *logicImpl) ListMessages(ctx context.Context, req *chat.ListMessagesReq, userID string) (*chat.ListMessagesResp, error) {
 	if userID == "" {
 		return nil, errors.New("missing user")
 	}
-	if req.ConversationID == "" {
-		return nil, errors.New("missing conversation_id")
-	}
-	conversation, err := l.memory.GetConversation(ctx, req.ConversationID)
-	if err != nil {
+	if _, _, err := l.memberConversation(ctx, req.ConversationID, userID); err != nil {
 		return nil, err
 	}
-	if conversation.UserID != userID {
-		return nil, errors.New("forbidden")
-	}
 
+	if req.Cursor != "" {
+		return l.listMessagesByCursor(ctx, req, userID)
+	}
 	page, pageSize := normalizePaging(req.Page, req.PageSize)
 	offset := (page - 1) * pageSize
 
@@ -268,48 +630,75 @@ func (l *logicImpl) ListMessages(ctx context.Context, req *chat.ListMessagesReq,
 	if err != nil {
 		return nil, err
 	}
+	respItems, err := l.toMessageItems(items, userID, req.IncludeReasoning)
+	if err != nil {
+		return nil, err
+	}
+	resp := &chat.ListMessagesResp{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Items:    respItems,
+	}
+	if len(items) > 0 && int64(offset+len(items)) < total {
+		resp.NextCursor = l.messageCursor(cursorNext, items[len(items)-1])
+	}
+	if len(items) > 0 && offset > 0 {
+		resp.PrevCursor = l.messageCursor(cursorPrev, items[0])
+	}
+	return resp, nil
+}
+
+func (l *logicImpl) toMessageItems(items []model.Message, userID string, includeReasoning bool) ([]chat.MessageItem, error) {
+	attachments, err := l.listMessageAttachments(items)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := l.listMessageFeedback(items, userID)
+	if err != nil {
+		return nil, err
+	}
 	respItems := make([]chat.MessageItem, 0, len(items))
 	for _, item := range items {
 		meta := ""
 		if item.Meta != nil {
 			meta = *item.Meta
 		}
+		reasoning := ""
+		if includeReasoning {
+			reasoning = item.Reasoning
+		}
 		respItems = append(respItems, chat.MessageItem{
 			ID:          item.ID,
 			Sequence:    item.Sequence,
+			UserID:      item.UserID,
 			Role:        item.Role,
 			ContentType: item.ContentType,
 			Content:     item.Content,
+			Images:      toChatImages(memory.SignImages(memory.DecodeImages(item), l.svcCtx.BlobURLs)),
+			Attachments: attachments[item.ID],
+			Reasoning:   reasoning,
 			Meta:        meta,
 			IsSummary:   item.IsSummary,
 			CreatedAt:   item.CreatedAt,
+			Model:       item.Model,
+			Feedback:    ratings[item.ID],
 		})
 	}
-	return &chat.ListMessagesResp{
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
-		Items:    respItems,
-	}, nil
+	return respItems, nil
 }
 
 func (l *logicImpl) GetConversation(ctx context.Context, req *chat.GetConversationReq, userID string) (*chat.ConversationItem, error) {
-	if req.ConversationID == "" {
-		return nil, errors.New("missing conversation_id")
-	}
-	conversation, err := l.memory.GetConversation(ctx, req.ConversationID)
+	conversation, _, err := l.memberConversation(ctx, req.ConversationID, userID)
 	if err != nil {
 		return nil, err
 	}
-	if userID != "" && conversation.UserID != userID {
-		return nil, errors.New("forbidden")
+	tags, err := l.memory.ListConversationTags(ctx, []string{conversation.UUID})
+	if err != nil {
+		return nil, err
 	}
-	return &chat.ConversationItem{
-		ConversationID: conversation.UUID,
-		Title:          conversation.Title,
-		CreatedAt:      conversation.CreatedAt,
-		UpdatedAt:      conversation.UpdatedAt,
-	}, nil
+	item := toConversationItem(*conversation, tags[conversation.UUID])
+	return &item, nil
 }
 
 func (l *logicImpl) UpdateConversationTitle(ctx context.Context, req *chat.UpdateConversationTitleReq, userID string) error {
@@ -319,57 +708,41 @@ func (l *logicImpl) UpdateConversationTitle(ctx context.Context, req *chat.Updat
 	if strings.TrimSpace(req.Title) == "" {
 		return errors.New("empty title")
 	}
-	conversation, err := l.memory.GetConversation(ctx, req.ConversationID)
-	if err != nil {
+	if _, err := l.managedConversation(ctx, req.ConversationID, userID); err != nil {
 		return err
 	}
-	if userID != "" && conversation.UserID != userID {
-		return errors.New("forbidden")
-	}
 	return l.memory.UpdateConversationTitle(ctx, req.ConversationID, req.Title)
 }
 
 func (l *logicImpl) DeleteConversation(ctx context.Context, req *chat.DeleteConversationReq, userID string) error {
-	if req.ConversationID == "" {
-		return errors.New("missing conversation_id")
-	}
-	conversation, err := l.memory.GetConversation(ctx, req.ConversationID)
-	if err != nil {
-		return err
-	}
-	if userID != "" && conversation.UserID != userID {
-		return errors.New("forbidden")
-	}
-	if err := l.memory.ClearMessages(ctx, req.ConversationID); err != nil {
+	if _, err := l.managedConversation(ctx, req.ConversationID, userID); err != nil {
 		return err
 	}
 	return l.memory.DeleteConversation(ctx, req.ConversationID)
 }
 
 func (l *logicImpl) ClearMessages(ctx context.Context, req *chat.ClearMessagesReq, userID string) error {
-	if req.ConversationID == "" {
-		return errors.New("missing conversation_id")
-	}
-	conversation, err := l.memory.GetConversation(ctx, req.ConversationID)
-	if err != nil {
+	if _, err := l.managedConversation(ctx, req.ConversationID, userID); err != nil {
 		return err
 	}
-	if userID != "" && conversation.UserID != userID {
-		return errors.New("forbidden")
-	}
 	return l.memory.ClearMessages(ctx, req.ConversationID)
 }
 
-func (l *logicImpl) doStreamCompletion(ctx context.Context, modelName string, messages []memory.PromptMessage) (*http2.SSEReader, error) {
-	prompt := make([]completionMessage, 0, len(messages))
-	for _, msg := range messages {
-		prompt = append(prompt, completionMessage{Role: msg.Role, Content: msg.Content})
+func (l *logicImpl) doStreamCompletion(ctx context.Context, modelName string, messages []memory.PromptMessage, opts generationOptions) (*http2.SSEReader, error) {
+	prompt, err := l.visionMessages(ctx, modelName, messages)
+	if err != nil {
+		return nil, err
 	}
-	body, err := json.Marshal(completionRequest{
-		Model:    modelName,
-		Messages: prompt,
-		Stream:   true,
-	})
+	req := completionRequest{
+		Model:             modelName,
+		Messages:          prompt,
+		Stream:            true,
+		generationOptions: opts,
+	}
+	if l.svcCtx.Config.LLMRequestConf.OpenAI.StreamUsage {
+		req.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
@@ -377,28 +750,50 @@ func (l *logicImpl) doStreamCompletion(ctx context.Context, modelName string, me
 }
 
 func (l *logicImpl) doCompletion(ctx context.Context, modelName string, messages []completionMessage) (string, error) {
-	body, err := json.Marshal(completionRequest{
-		Model:    modelName,
-		Messages: messages,
-		Stream:   false,
-	})
+	msg, err := l.doCompletionMessage(ctx, modelName, messages)
 	if err != nil {
 		return "", err
 	}
+	return msg.Content, nil
+}
+
+func (l *logicImpl) doCompletionMessage(ctx context.Context, modelName string, messages []completionMessage) (completionDelta, error) {
+	msg, _, err := l.doCompletionRequest(ctx, completionRequest{Model: modelName, Messages: messages})
+	return msg, err
+}
+
+func (l *logicImpl) doCompletionRequest(ctx context.Context, req completionRequest) (completionDelta, *chat.Usage, error) {
+	req.Stream = false
+	body, err := json.Marshal(req)
+	if err != nil {
+		return completionDelta{}, nil, err
+	}
 	resp, err := l.utils.RequestHandler.DoCommon(ctx, http.MethodPost, l.urls.Completion, bytes.NewReader(body), l.headers)
 	if err != nil {
-		return "", err
+		return completionDelta{}, nil, err
 	}
 	defer resp.Body.Close()
 
 	var out completionResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", err
+		return completionDelta{}, nil, err
 	}
 	if len(out.Choices) == 0 {
-		return "", errors.New("empty completion response")
+		return completionDelta{}, nil, errors.New("empty completion response")
 	}
-	return out.Choices[0].Message.Content, nil
+	return out.Choices[0].Message.completionDelta, out.Usage.toChat(), nil
+}
+
+// indexMessages embeds new messages for semantic search in the background;
+// a failure only costs the messages their place in semantic results.
+func (l *logicImpl) indexMessages(messages ...model.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), indexTimeout)
+		defer cancel()
+		if err := l.search.IndexMessages(ctx, messages...); err != nil {
+			logger.L().Errorf("index messages for search error: %v", err)
+		}
+	}()
 }
 
 func (l *logicImpl) generateTitleAsync(conversationID, modelName string) {
@@ -428,29 +823,47 @@ func (l *logicImpl) generateTitle(conversationID, modelName string) (string, boo
 		Role:    "system",
 		Content: fmt.Sprintf("Generate a short title (<=%d chars). Return only the title.", titleMaxChars),
 	})
+	session := l.piiSession(conversation.BotID)
 	for _, msg := range titleMessages {
-		prompt = append(prompt, completionMessage{Role: msg.Role, Content: msg.Content})
+		content, err := session.Redact(msg.Content)
+		if err != nil {
+			return "", false
+		}
+		prompt = append(prompt, completionMessage{Role: msg.Role, Content: content})
 	}
-	title, err := l.doCompletion(ctx, modelName, prompt)
+	title, err := l.doCompletion(ctx, l.titleModel(modelName), prompt)
 	if err != nil {
 		return "", false
 	}
-	title = strings.TrimSpace(strings.Trim(title, `"`))
+	title = strings.TrimSpace(strings.Trim(strings.TrimSpace(session.Restore(title)), `"`))
 	if title == "" {
 		return "", false
 	}
-	if err := l.memory.UpdateConversationTitle(ctx, conversationID, title); err != nil {
+	if err := l.memory.UpdateGeneratedTitle(ctx, conversationID, title); err != nil {
 		return "", false
 	}
 	return title, true
 }
 
+// titleModel is the configured title model, or the conversation's model
+// when none is set.
+func (l *logicImpl) titleModel(modelName string) string {
+	if titleModel := l.svcCtx.Config.LLMRequestConf.OpenAI.TitleModel; titleModel != "" {
+		return titleModel
+	}
+	return modelName
+}
+
 func (l *logicImpl) doCompletionFromPrompt(ctx context.Context, modelName string, messages []memory.PromptMessage) (string, error) {
+	return l.doCompletion(ctx, modelName, toCompletionMessages(messages))
+}
+
+func toCompletionMessages(messages []memory.PromptMessage) []completionMessage {
 	prompt := make([]completionMessage, 0, len(messages))
 	for _, msg := range messages {
 		prompt = append(prompt, completionMessage{Role: msg.Role, Content: msg.Content})
 	}
-	return l.doCompletion(ctx, modelName, prompt)
+	return prompt
 }
 
 func normalizePaging(page, pageSize int) (int, int) {
@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/pkg/openaitest"
+)
+
+func TestGenerateTitle(t *testing.T) {
+	mem := &fakeMemory{
+		conversation: model.Conversation{Title: "New"},
+		titleMsgs:    []memory.PromptMessage{{Role: "user", Content: "How do I bake bread?"}},
+	}
+	l, srv := newTestLogic(t, configs.Config{}, mem)
+	srv.Queue(openaitest.Reply{Content: ` "Baking Bread" `})
+
+	title, ok := l.generateTitle("conv-1", testModel)
+	if !ok || title != "Baking Bread" {
+		t.Fatalf("generateTitle = %q, %v, want %q", title, ok, "Baking Bread")
+	}
+	if got := mem.title(); got != "Baking Bread" {
+		t.Errorf("saved title = %q, want %q", got, "Baking Bread")
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 1 || reqs[0].Stream || reqs[0].Model != testModel {
+		t.Fatalf("requests = %+v, want one plain completion", reqs)
+	}
+	if msgs := reqs[0].Messages; len(msgs) != 2 || msgs[0].Role != "system" || msgs[1].Text() != "How do I bake bread?" {
+		t.Errorf("prompt = %+v, want the instruction and the history", msgs)
+	}
+}
+
+func TestGenerateTitleUsesTitleModel(t *testing.T) {
+	var conf configs.Config
+	conf.LLMRequestConf.OpenAI.TitleModel = "title-model"
+	mem := &fakeMemory{
+		conversation: model.Conversation{Title: "New"},
+		titleMsgs:    []memory.PromptMessage{{Role: "user", Content: "hi"}},
+	}
+	l, srv := newTestLogic(t, conf, mem)
+
+	if _, ok := l.generateTitle("conv-1", testModel); !ok {
+		t.Fatal("generateTitle failed")
+	}
+	if reqs := srv.Requests(); len(reqs) != 1 || reqs[0].Model != "title-model" {
+		t.Errorf("requests = %+v, want one to the title model", reqs)
+	}
+}
+
+func TestGenerateTitleKeepsExistingTitle(t *testing.T) {
+	mem := &fakeMemory{
+		conversation: model.Conversation{Title: "Chosen by the user"},
+		titleMsgs:    []memory.PromptMessage{{Role: "user", Content: "hi"}},
+	}
+	l, srv := newTestLogic(t, configs.Config{}, mem)
+
+	if _, ok := l.generateTitle("conv-1", testModel); ok {
+		t.Error("generated a title for a titled conversation")
+	}
+	if reqs := srv.Requests(); len(reqs) != 0 {
+		t.Errorf("sent %d requests, want none", len(reqs))
+	}
+}
+
+func TestGenerateTitleUpstreamError(t *testing.T) {
+	mem := &fakeMemory{
+		conversation: model.Conversation{Title: "New"},
+		titleMsgs:    []memory.PromptMessage{{Role: "user", Content: "hi"}},
+	}
+	l, srv := newTestLogic(t, configs.Config{}, mem)
+	srv.Fail(openaitest.Fault{Status: 500})
+
+	if title, ok := l.generateTitle("conv-1", testModel); ok {
+		t.Errorf("generateTitle = %q after a provider error", title)
+	}
+	if got := mem.title(); got != "" {
+		t.Errorf("saved title %q after a provider error", got)
+	}
+}
+
+func TestGenerateTitleMissingConversation(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, &fakeMemory{err: errNotFound})
+
+	if _, ok := l.generateTitle("conv-1", testModel); ok {
+		t.Error("generated a title for a missing conversation")
+	}
+	if reqs := srv.Requests(); len(reqs) != 0 {
+		t.Errorf("sent %d requests, want none", len(reqs))
+	}
+}
+
+func TestGenerateTitleRedactsPII(t *testing.T) {
+	var conf configs.Config
+	conf.PIIConf.Enabled = true
+	conf.PIIConf.DefaultKinds = []string{"phone"}
+	mem := &fakeMemory{
+		conversation: model.Conversation{Title: "New", BotID: "bot-1"},
+		titleMsgs:    []memory.PromptMessage{{Role: "user", Content: "Call me at 13812345678 tomorrow"}},
+	}
+	l, srv := newTestLogic(t, conf, mem)
+	srv.Respond(func(req openaitest.ChatRequest) openaitest.Reply {
+		return openaitest.Reply{Content: "Call " + placeholderIn(req.LastUserText())}
+	})
+
+	title, ok := l.generateTitle("conv-1", testModel)
+	if !ok || title != "Call 13812345678" {
+		t.Fatalf("generateTitle = %q, %v, want the number restored", title, ok)
+	}
+	for _, msg := range srv.Requests()[0].Messages {
+		if strings.Contains(msg.Text(), "13812345678") {
+			t.Errorf("phone number sent upstream: %q", msg.Text())
+		}
+	}
+}
+
+// placeholderIn returns the first redaction placeholder in text.
+func placeholderIn(text string) string {
+	start := strings.Index(text, "[")
+	end := strings.Index(text, "]")
+	if start < 0 || end < start {
+		return ""
+	}
+	return text[start : end+1]
+}
@@ -0,0 +1,289 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/command"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/export"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/reminder"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+const (
+	summarizeMessageLimit = 50
+	exportReplyMaxBytes   = 32 << 10
+	modelNameMaxChars     = 128
+)
+
+var errExportTooLarge = errors.New("export too large")
+
+// newCommandRouter registers the built-in commands. It returns nil when
+// commands are disabled, leaving every message to the model.
+func (l *logicImpl) newCommandRouter() *command.Router {
+	conf := l.svcCtx.Config.CommandConf
+	if !conf.Enabled {
+		return nil
+	}
+	r := command.NewRouter(command.ConfigPolicy(conf))
+	builtins := []command.Command{
+		{
+			Name:        "help",
+			Description: "list the available commands",
+			Handle: func(ctx context.Context, call *command.Call) ([]chat.StreamEvent, error) {
+				return command.Reply(r.Help(call.Conversation.BotID)), nil
+			},
+		},
+		{
+			Name:        "reset",
+			Description: "clear the conversation history",
+			OwnerOnly:   true,
+			Handle:      l.resetCommand,
+		},
+		{
+			Name:        "title",
+			Usage:       "<title>",
+			Description: "rename the conversation",
+			OwnerOnly:   true,
+			Args:        command.Text(true, titleMaxChars*4),
+			Handle:      l.titleCommand,
+		},
+		{
+			Name:        "model",
+			Usage:       "[name|default]",
+			Description: "show or switch the model of this conversation",
+			OwnerOnly:   true,
+			Args:        command.Text(false, modelNameMaxChars),
+			Handle:      l.modelCommand,
+		},
+		{
+			Name:        "summarize",
+			Description: "summarize the conversation so far",
+			Handle:      l.summarizeCommand,
+		},
+		{
+			Name:        "remind",
+			Usage:       "in <duration> | at <YYYY-MM-DD HH:MM> | cron <m h dom mon dow> <message>",
+			Description: "schedule a reminder in this conversation",
+			Args:        parseRemindArgs,
+			Handle:      l.remindCommand,
+		},
+		{
+			Name:        "export",
+			Description: "export the conversation as markdown",
+			OwnerOnly:   true,
+			Handle:      l.exportCommand,
+		},
+	}
+	for _, cmd := range builtins {
+		if err := r.Register(cmd); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// runCommand answers a command message without involving the model. The
+// command and its answer are not stored in the conversation. It reports
+// false when the message is not a command the bot accepts.
+func (l *logicImpl) runCommand(ctx context.Context, req *chat.Completion, input chat.Message, userID string) (chat.MessageStream, bool, error) {
+	if l.commands == nil {
+		return nil, false, nil
+	}
+	if _, _, ok := command.Parse(input.Content); !ok {
+		return nil, false, nil
+	}
+	conversation, err := l.memory.EnsureConversation(ctx, userID, req.ConversationID, req.BotID)
+	if err != nil {
+		return nil, false, err
+	}
+	req.ConversationID = conversation.UUID
+	req.BotID = conversation.BotID
+	cmd, args, ok := l.commands.Match(conversation.BotID, input.Content)
+	if !ok {
+		return nil, false, nil
+	}
+	role, err := l.conversationRole(ctx, conversation, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	modelName := req.Model
+	if conversation.Model != "" {
+		modelName = conversation.Model
+	}
+	events, err := l.commands.Run(ctx, cmd, args, command.Call{
+		UserID:       userID,
+		Role:         role,
+		Model:        modelName,
+		Conversation: conversation,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if n := len(events); n == 0 || events[n-1].Type != chat.EventDone {
+		events = append(events, chat.StreamEvent{Type: chat.EventDone})
+	}
+	events[len(events)-1].ConversationID = conversation.UUID
+	return newStaticStream(events...), true, nil
+}
+
+// conversationRole is the caller's role in the conversation; the creator of
+// a direct conversation counts as its owner.
+func (l *logicImpl) conversationRole(ctx context.Context, conversation *model.Conversation, userID string) (string, error) {
+	if conversation.UserID == userID {
+		return model.ParticipantRoleOwner, nil
+	}
+	if conversation.Kind != model.ConversationKindGroup {
+		return "", nil
+	}
+	participant, err := l.memory.GetParticipant(ctx, conversation.UUID, userID)
+	if err != nil {
+		return "", err
+	}
+	return participant.Role, nil
+}
+
+func (l *logicImpl) resetCommand(ctx context.Context, call *command.Call) ([]chat.StreamEvent, error) {
+	if err := l.memory.ClearMessages(ctx, call.Conversation.UUID); err != nil {
+		return nil, err
+	}
+	return command.Reply("Conversation history cleared."), nil
+}
+
+func (l *logicImpl) titleCommand(ctx context.Context, call *command.Call) ([]chat.StreamEvent, error) {
+	title := call.Args.(string)
+	if err := l.memory.UpdateConversationTitle(ctx, call.Conversation.UUID, title); err != nil {
+		return nil, err
+	}
+	events := command.Reply(fmt.Sprintf("Title set to %q.", title))
+	return append(events, chat.StreamEvent{Type: chat.EventDone, Title: title}), nil
+}
+
+func (l *logicImpl) modelCommand(ctx context.Context, call *command.Call) ([]chat.StreamEvent, error) {
+	name := call.Args.(string)
+	switch {
+	case name == "":
+		if call.Conversation.Model == "" {
+			return command.Reply(fmt.Sprintf("This conversation uses the requested model (%s).", call.Model)), nil
+		}
+		return command.Reply(fmt.Sprintf("This conversation uses %s.", call.Conversation.Model)), nil
+	case strings.EqualFold(name, "default"):
+		if err := l.memory.SetConversationModel(ctx, call.Conversation.UUID, ""); err != nil {
+			return nil, err
+		}
+		return command.Reply("This conversation now uses the requested model."), nil
+	case strings.ContainsAny(name, " \t\n"):
+		return command.Reply("Usage: /model [name|default]"), nil
+	}
+	if err := l.memory.SetConversationModel(ctx, call.Conversation.UUID, name); err != nil {
+		return nil, err
+	}
+	return command.Reply(fmt.Sprintf("This conversation now uses %s.", name)), nil
+}
+
+func (l *logicImpl) summarizeCommand(ctx context.Context, call *command.Call) ([]chat.StreamEvent, error) {
+	prompt, err := l.memory.BuildSummaryMessages(ctx, call.Conversation.UUID, summarizeMessageLimit)
+	if err != nil {
+		return nil, err
+	}
+	// The first message is the instruction; without others there is
+	// nothing to summarize.
+	if len(prompt) < 2 {
+		return command.Reply("There is nothing to summarize yet."), nil
+	}
+	summary, err := l.redactedSummarizer(l.piiSession(call.Conversation.BotID))(ctx, call.Model, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return command.Reply(strings.TrimSpace(summary)), nil
+}
+
+// exportCommand answers with the markdown export of short conversations;
+// longer ones must use the export API.
+func (l *logicImpl) exportCommand(ctx context.Context, call *command.Call) ([]chat.StreamEvent, error) {
+	file, err := l.export.ExportConversation(ctx, &export.ExportReq{
+		ConversationID: call.Conversation.UUID,
+		Format:         export.FormatMarkdown,
+	}, call.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := file.Write(&limitedBuffer{buf: &buf, max: exportReplyMaxBytes}); err != nil {
+		if errors.Is(err, errExportTooLarge) {
+			return command.Reply("This conversation is too long to export here; use the export feature instead."), nil
+		}
+		return nil, err
+	}
+	return command.Reply(buf.String()), nil
+}
+
+// parseRemindArgs reads "in 2h call mom", "at 2026-10-20 09:00 standup"
+// or "cron 0 9 * * 1-5 standup". Times are in the default timezone.
+func parseRemindArgs(raw string) (any, error) {
+	fields := strings.Fields(raw)
+	if len(fields) < 3 {
+		return nil, command.ErrUsage
+	}
+	req := &reminder.CreateReminderReq{}
+	rest := 2
+	switch strings.ToLower(fields[0]) {
+	case "in":
+		d, err := time.ParseDuration(fields[1])
+		if err != nil || d < time.Minute {
+			return nil, command.ErrUsage
+		}
+		req.RunAt = time.Now().Add(d).Unix()
+	case "at":
+		if len(fields) < 4 {
+			return nil, command.ErrUsage
+		}
+		req.At = fields[1] + " " + fields[2]
+		rest = 3
+	case "cron":
+		if len(fields) < 7 {
+			return nil, command.ErrUsage
+		}
+		req.Cron = strings.Join(fields[1:6], " ")
+		rest = 6
+	default:
+		return nil, command.ErrUsage
+	}
+	req.Message = strings.Join(fields[rest:], " ")
+	return req, nil
+}
+
+func (l *logicImpl) remindCommand(ctx context.Context, call *command.Call) ([]chat.StreamEvent, error) {
+	req := call.Args.(*reminder.CreateReminderReq)
+	req.ConversationID = call.Conversation.UUID
+	item, err := l.reminders.CreateReminder(ctx, req, call.UserID)
+	if err != nil {
+		return command.Reply("Could not create the reminder: " + err.Error()), nil
+	}
+	when := time.Unix(item.NextRunAt, 0)
+	if loc, err := time.LoadLocation(item.Timezone); err == nil {
+		when = when.In(loc)
+	}
+	text := fmt.Sprintf("Reminder set for %s.", when.Format("2006-01-02 15:04 MST"))
+	if item.Kind == model.ReminderKindCron {
+		text = fmt.Sprintf("Reminder set on %q, first at %s.", item.Cron, when.Format("2006-01-02 15:04 MST"))
+	}
+	return command.Reply(text), nil
+}
+
+type limitedBuffer struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.max {
+		return 0, errExportTooLarge
+	}
+	return b.buf.Write(p)
+}
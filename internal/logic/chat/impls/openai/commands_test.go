@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/command"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/pkg/openaitest"
+)
+
+func summarizeCall() *command.Call {
+	return &command.Call{
+		Model:        testModel,
+		Conversation: &model.Conversation{UUID: "conv-1", BotID: "bot-1"},
+	}
+}
+
+func TestSummarizeCommand(t *testing.T) {
+	mem := &fakeMemory{summaryMsgs: []memory.PromptMessage{
+		{Role: "system", Content: "Summarize the conversation."},
+		{Role: "user", Content: "Plan a trip to Kyoto"},
+		{Role: "assistant", Content: "Here is a three day plan."},
+	}}
+	l, srv := newTestLogic(t, configs.Config{}, mem)
+	srv.Queue(openaitest.Reply{Content: "\n A three day Kyoto itinerary. \n"})
+
+	events, err := l.summarizeCommand(context.Background(), summarizeCall())
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if len(events) != 1 || events[0].Delta != "A three day Kyoto itinerary." {
+		t.Errorf("reply = %+v, want the trimmed summary", events)
+	}
+	if reqs := srv.Requests(); len(reqs) != 1 || len(reqs[0].Messages) != 3 {
+		t.Errorf("requests = %+v, want the whole history in one request", reqs)
+	}
+}
+
+func TestSummarizeCommandEmptyConversation(t *testing.T) {
+	mem := &fakeMemory{summaryMsgs: []memory.PromptMessage{
+		{Role: "system", Content: "Summarize the conversation."},
+	}}
+	l, srv := newTestLogic(t, configs.Config{}, mem)
+
+	events, err := l.summarizeCommand(context.Background(), summarizeCall())
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if len(events) != 1 || events[0].Delta != "There is nothing to summarize yet." {
+		t.Errorf("reply = %+v, want the empty notice", events)
+	}
+	if reqs := srv.Requests(); len(reqs) != 0 {
+		t.Errorf("sent %d requests, want none", len(reqs))
+	}
+}
+
+func TestSummarizeCommandRedactsPII(t *testing.T) {
+	var conf configs.Config
+	conf.PIIConf.Enabled = true
+	conf.PIIConf.DefaultKinds = []string{"email"}
+	mem := &fakeMemory{summaryMsgs: []memory.PromptMessage{
+		{Role: "system", Content: "Summarize the conversation."},
+		{Role: "user", Content: "Send the invoice to alice@example.com"},
+	}}
+	l, srv := newTestLogic(t, conf, mem)
+	srv.Respond(func(req openaitest.ChatRequest) openaitest.Reply {
+		return openaitest.Reply{Content: "Invoice goes to " + placeholderIn(req.LastUserText()) + "."}
+	})
+
+	events, err := l.summarizeCommand(context.Background(), summarizeCall())
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if len(events) != 1 || events[0].Delta != "Invoice goes to alice@example.com." {
+		t.Errorf("reply = %+v, want the address restored", events)
+	}
+	for _, msg := range srv.Requests()[0].Messages {
+		if strings.Contains(msg.Text(), "alice@example.com") {
+			t.Errorf("email sent upstream: %q", msg.Text())
+		}
+	}
+}
+
+func TestSummarizeCommandUpstreamError(t *testing.T) {
+	mem := &fakeMemory{summaryMsgs: []memory.PromptMessage{
+		{Role: "system", Content: "Summarize the conversation."},
+		{Role: "user", Content: "hi"},
+	}}
+	l, srv := newTestLogic(t, configs.Config{}, mem)
+	srv.Fail(openaitest.Fault{Status: 429})
+
+	if _, err := l.summarizeCommand(context.Background(), summarizeCall()); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("err = %v, want the rate limit reported", err)
+	}
+}
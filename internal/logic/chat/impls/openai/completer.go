@@ -0,0 +1,55 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/utils"
+	"net/http"
+)
+
+type completer struct {
+	utils   *utils.Utils
+	url     string
+	headers map[string]string
+}
+
+func NewCompleter(svcCtx *svc.Context) (chat.Completer, error) {
+	baseURL, headers, err := loadCredentials(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &completer{
+		utils:   svcCtx.Utils,
+		url:     newURLs(baseURL).Completion,
+		headers: headers,
+	}, nil
+}
+
+func (c *completer) Complete(ctx context.Context, modelName string, messages []chat.Message) (string, error) {
+	req := completionRequest{Model: modelName, Messages: make([]completionMessage, 0, len(messages))}
+	for _, m := range messages {
+		req.Messages = append(req.Messages, completionMessage{Role: m.Role, Content: m.Content})
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.utils.RequestHandler.DoCommon(ctx, http.MethodPost, c.url, bytes.NewReader(body), c.headers)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out completionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Choices) == 0 {
+		return "", errors.New("empty completion response")
+	}
+	return out.Choices[0].Message.Content, nil
+}
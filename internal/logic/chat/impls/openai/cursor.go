@@ -0,0 +1,142 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	chatdao "github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+// conversationCursor carries the full list-order key, since pinned
+// conversations sort ahead of updated_at.
+type conversationCursor struct {
+	Direction string `json:"d"`
+	Pinned    bool   `json:"p,omitempty"`
+	PinnedAt  int64  `json:"pa,omitempty"`
+	UpdatedAt int64  `json:"u"`
+	ID        string `json:"id"`
+}
+
+type messageCursor struct {
+	Direction string `json:"d"`
+	Sequence  int64  `json:"s"`
+}
+
+func (l *logicImpl) listConversationsByCursor(ctx context.Context, req *chat.ListConversationsReq, userID string, filter chatdao.ConversationFilter) (*chat.ListConversationsResp, error) {
+	var c conversationCursor
+	if err := l.svcCtx.Cursor.Decode(req.Cursor, &c); err != nil {
+		return nil, err
+	}
+	before, err := cursorBefore(c.Direction)
+	if err != nil {
+		return nil, err
+	}
+	_, pageSize := normalizePaging(0, req.PageSize)
+	key := chatdao.ConversationKey{Pinned: c.Pinned, PinnedAt: c.PinnedAt, UpdatedAt: c.UpdatedAt, UUID: c.ID}
+	items, err := l.memory.ListConversationsByKey(ctx, userID, filter, key, before, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	items, more := trimPage(items, pageSize, before)
+	respItems, err := l.toConversationItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	resp := &chat.ListConversationsResp{PageSize: pageSize, Items: respItems}
+	if len(items) > 0 {
+		if !before || more {
+			resp.PrevCursor = l.conversationCursor(cursorPrev, items[0])
+		}
+		if before || more {
+			resp.NextCursor = l.conversationCursor(cursorNext, items[len(items)-1])
+		}
+	}
+	if req.IncludeTotal {
+		if resp.Total, err = l.memory.CountConversations(ctx, userID, filter); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (l *logicImpl) listMessagesByCursor(ctx context.Context, req *chat.ListMessagesReq, userID string) (*chat.ListMessagesResp, error) {
+	var c messageCursor
+	if err := l.svcCtx.Cursor.Decode(req.Cursor, &c); err != nil {
+		return nil, err
+	}
+	before, err := cursorBefore(c.Direction)
+	if err != nil {
+		return nil, err
+	}
+	_, pageSize := normalizePaging(0, req.PageSize)
+	items, err := l.memory.ListMessagesBySequence(ctx, req.ConversationID, c.Sequence, before, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	items, more := trimPage(items, pageSize, before)
+	respItems, err := l.toMessageItems(items, userID, req.IncludeReasoning)
+	if err != nil {
+		return nil, err
+	}
+	resp := &chat.ListMessagesResp{PageSize: pageSize, Items: respItems}
+	if len(items) > 0 {
+		if !before || more {
+			resp.PrevCursor = l.messageCursor(cursorPrev, items[0])
+		}
+		if before || more {
+			resp.NextCursor = l.messageCursor(cursorNext, items[len(items)-1])
+		}
+	}
+	if req.IncludeTotal {
+		if resp.Total, err = l.memory.CountMessages(ctx, req.ConversationID); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (l *logicImpl) conversationCursor(direction string, c model.Conversation) string {
+	token, _ := l.svcCtx.Cursor.Encode(conversationCursor{
+		Direction: direction,
+		Pinned:    c.Pinned,
+		PinnedAt:  c.PinnedAt,
+		UpdatedAt: c.UpdatedAt,
+		ID:        c.UUID,
+	})
+	return token
+}
+
+func (l *logicImpl) messageCursor(direction string, m model.Message) string {
+	token, _ := l.svcCtx.Cursor.Encode(messageCursor{Direction: direction, Sequence: m.Sequence})
+	return token
+}
+
+func cursorBefore(direction string) (bool, error) {
+	switch direction {
+	case cursorNext:
+		return false, nil
+	case cursorPrev:
+		return true, nil
+	default:
+		return false, errors.New("invalid cursor")
+	}
+}
+
+// trimPage cuts a limit+1 fetch down to limit and reports whether more items
+// exist in the fetch direction. Items are in list order, so for a backward
+// fetch the extra item is the first one.
+func trimPage[T any](items []T, limit int, before bool) ([]T, bool) {
+	if len(items) <= limit {
+		return items, false
+	}
+	if before {
+		return items[len(items)-limit:], true
+	}
+	return items[:limit], true
+}
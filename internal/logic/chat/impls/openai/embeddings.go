@@ -0,0 +1,103 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/pii"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/utils"
+	"net/http"
+	"sort"
+)
+
+const defaultEmbeddingModel = "text-embedding-3-small"
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// embedder redacts personal data from every input with the PII engine, if
+// any, since search, knowledge and cache vectors are computed upstream too.
+type embedder struct {
+	utils   *utils.Utils
+	url     string
+	headers map[string]string
+	model   string
+	pii     *pii.Engine
+}
+
+func NewEmbedder(svcCtx *svc.Context) (chat.Embedder, error) {
+	baseURL, headers, err := loadCredentials(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	piiEngine, err := newPIIEngine(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	return newEmbedder(svcCtx, newURLs(baseURL), headers, piiEngine), nil
+}
+
+func newEmbedder(svcCtx *svc.Context, u *urls, headers map[string]string, piiEngine *pii.Engine) *embedder {
+	modelName := svcCtx.Config.LLMRequestConf.OpenAI.EmbeddingModel
+	if modelName == "" {
+		modelName = defaultEmbeddingModel
+	}
+	return &embedder{
+		utils:   svcCtx.Utils,
+		url:     u.Embeddings,
+		headers: headers,
+		model:   modelName,
+		pii:     piiEngine,
+	}
+}
+
+func (e *embedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	if e.pii != nil {
+		redacted := make([]string, len(inputs))
+		for i, in := range inputs {
+			var err error
+			if redacted[i], err = e.pii.NewFullSession().Redact(in); err != nil {
+				return nil, err
+			}
+		}
+		inputs = redacted
+	}
+	body, err := json.Marshal(embeddingRequest{Model: e.model, Input: inputs})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.utils.RequestHandler.DoLong(ctx, http.MethodPost, e.url, bytes.NewReader(body), e.headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Data) != len(inputs) {
+		return nil, errors.New("embedding count mismatch")
+	}
+	sort.Slice(out.Data, func(i, j int) bool { return out.Data[i].Index < out.Data[j].Index })
+	vectors := make([][]float32, 0, len(out.Data))
+	for _, d := range out.Data {
+		vectors = append(vectors, d.Embedding)
+	}
+	return vectors, nil
+}
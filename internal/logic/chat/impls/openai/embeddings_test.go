@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+)
+
+func TestEmbedderRedactsPII(t *testing.T) {
+	var conf configs.Config
+	conf.PIIConf.Enabled = true
+	// The bot settings narrow chat redaction; embedding inputs are not
+	// tied to a bot and drop every kind.
+	conf.PIIConf.Bots = map[string][]string{"bot-1": {"email"}}
+	l, srv := newTestLogic(t, conf, nil)
+	e := newEmbedder(l.svcCtx, l.urls, l.headers, l.pii)
+
+	inputs := []string{
+		"call me on 13812345678",
+		"mail alice@example.com about it",
+		"nothing personal here",
+	}
+	vectors, err := e.Embed(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if len(vectors) != len(inputs) {
+		t.Fatalf("got %d vectors, want %d", len(vectors), len(inputs))
+	}
+	sent := srv.EmbeddingInputs()
+	if len(sent) != len(inputs) {
+		t.Fatalf("provider got %d inputs, want %d", len(sent), len(inputs))
+	}
+	for _, in := range sent {
+		for _, raw := range []string{"13812345678", "alice@example.com"} {
+			if strings.Contains(in, raw) {
+				t.Errorf("embedding input %q carries %q", in, raw)
+			}
+		}
+	}
+	if sent[2] != inputs[2] {
+		t.Errorf("clean input changed to %q", sent[2])
+	}
+}
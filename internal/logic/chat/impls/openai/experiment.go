@@ -0,0 +1,76 @@
+package openai
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/experiment"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"time"
+)
+
+// newExperimentAssigner returns nil when experiments are disabled.
+func newExperimentAssigner(svcCtx *svc.Context) (*experiment.Assigner, error) {
+	if !svcCtx.Config.ExperimentConf.Enabled {
+		return nil, nil
+	}
+	return experiment.NewAssigner(svcCtx.Config.ExperimentConf)
+}
+
+// assignExperiment picks the user's variant for the conversation. A model
+// pinned on the conversation is the user's own choice and keeps it out of
+// experiments.
+func (l *logicImpl) assignExperiment(conversation *model.Conversation, userID string) *experiment.Assignment {
+	if l.experiments == nil || conversation.Model != "" {
+		return nil
+	}
+	return l.experiments.Assign(conversation.BotID, userID)
+}
+
+func variantOptions(a *experiment.Assignment) generationOptions {
+	if a == nil {
+		return generationOptions{}
+	}
+	return generationOptions{
+		Temperature: a.Variant.Temperature,
+		TopP:        a.Variant.TopP,
+		MaxTokens:   a.Variant.MaxTokens,
+	}
+}
+
+// withVariantPrompt puts the variant's system prompt first.
+func withVariantPrompt(a *experiment.Assignment, botID, modelName string, messages []memory.PromptMessage) ([]memory.PromptMessage, error) {
+	prompt, err := a.SystemPrompt(experiment.PromptData{
+		BotID: botID,
+		Model: modelName,
+		Date:  time.Now().UTC().Format("2006-01-02"),
+	})
+	if err != nil || prompt == "" {
+		return messages, err
+	}
+	return append([]memory.PromptMessage{{Role: "system", Content: prompt}}, messages...), nil
+}
+
+// replyInput starts an assistant message recording what produced it.
+func (l *logicImpl) replyInput(a *experiment.Assignment, modelName string, started time.Time, firstToken time.Time, usage *chat.Usage) memory.AssistantMessageInput {
+	in := memory.AssistantMessageInput{
+		Model:         modelName,
+		PromptVersion: l.promptVersion(),
+		LatencyMs:     time.Since(started).Milliseconds(),
+	}
+	if !firstToken.IsZero() {
+		in.FirstTokenMs = firstToken.Sub(started).Milliseconds()
+	}
+	if usage != nil {
+		in.PromptTokens = usage.PromptTokens
+		in.CompletionTokens = usage.CompletionTokens
+	}
+	if a != nil {
+		in.Experiment = a.ExperimentID
+		in.Variant = a.Variant.Name
+		if a.Variant.PromptVersion != "" {
+			in.PromptVersion = a.Variant.PromptVersion
+		}
+	}
+	return in
+}
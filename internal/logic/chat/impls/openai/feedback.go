@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/feedback"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+// listMessageFeedback loads the user's ratings of the listed assistant
+// replies, keyed by message ID.
+func (l *logicImpl) listMessageFeedback(messages []model.Message, userID string) (map[int64]*chat.MessageFeedback, error) {
+	ids := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == "assistant" && !msg.IsSummary {
+			ids = append(ids, msg.ID)
+		}
+	}
+	items, err := l.svcCtx.Dao.FeedbackDao.ListFeedbackByMessages(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]*chat.MessageFeedback, len(items))
+	for _, entity := range items {
+		item := feedback.ToFeedbackItem(entity)
+		out[item.MessageID] = &chat.MessageFeedback{
+			Rating:    item.Rating,
+			Reasons:   item.Reasons,
+			Comment:   item.Comment,
+			UpdatedAt: item.UpdatedAt,
+		}
+	}
+	return out, nil
+}
+
+func (l *logicImpl) promptVersion() string {
+	return l.svcCtx.Config.LLMRequestConf.OpenAI.PromptVersion
+}
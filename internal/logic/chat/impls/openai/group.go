@@ -0,0 +1,240 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultBotHandle       = "bot"
+	defaultMaxParticipants = 50
+)
+
+func (l *logicImpl) CreateGroupConversation(ctx context.Context, req *chat.CreateGroupConversationReq, userID string) (*chat.ConversationItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	members := make([]memory.ParticipantInput, 0, len(req.Participants)+1)
+	members = append(members, memory.ParticipantInput{UserID: userID, DisplayName: req.DisplayName})
+	members = append(members, toParticipantInputs(req.Participants)...)
+	if len(members) > l.maxParticipants() {
+		return nil, fmt.Errorf("too many participants (max %d)", l.maxParticipants())
+	}
+	conversation, err := l.memory.CreateGroupConversation(ctx, userID, req.BotID, req.Title, members)
+	if err != nil {
+		return nil, err
+	}
+	item := toConversationItem(*conversation, nil)
+	return &item, nil
+}
+
+func (l *logicImpl) ListParticipants(ctx context.Context, req *chat.ListParticipantsReq, userID string) ([]chat.ParticipantItem, error) {
+	conversation, _, err := l.memberConversation(ctx, req.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Kind != model.ConversationKindGroup {
+		return nil, errors.New("not a group conversation")
+	}
+	return l.listParticipants(ctx, conversation.UUID)
+}
+
+func (l *logicImpl) AddParticipants(ctx context.Context, req *chat.AddParticipantsReq, userID string) ([]chat.ParticipantItem, error) {
+	conversation, err := l.managedGroup(ctx, req.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Participants) == 0 {
+		return nil, errors.New("missing participants")
+	}
+	count, err := l.memory.CountParticipants(ctx, conversation.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if int(count)+len(req.Participants) > l.maxParticipants() {
+		return nil, fmt.Errorf("too many participants (max %d)", l.maxParticipants())
+	}
+	if err := l.memory.AddParticipants(ctx, conversation.UUID, toParticipantInputs(req.Participants)); err != nil {
+		return nil, err
+	}
+	return l.listParticipants(ctx, conversation.UUID)
+}
+
+// RemoveParticipant lets owners remove members and members leave. The
+// creator cannot be removed.
+func (l *logicImpl) RemoveParticipant(ctx context.Context, req *chat.RemoveParticipantReq, userID string) error {
+	if req.UserID == "" {
+		return errors.New("missing participant user_id")
+	}
+	conversation, role, err := l.memberConversation(ctx, req.ConversationID, userID)
+	if err != nil {
+		return err
+	}
+	if conversation.Kind != model.ConversationKindGroup {
+		return errors.New("not a group conversation")
+	}
+	if req.UserID != userID && role != model.ParticipantRoleOwner {
+		return errors.New("forbidden")
+	}
+	if req.UserID == conversation.UserID {
+		return errors.New("the creator cannot leave the conversation")
+	}
+	return l.memory.RemoveParticipant(ctx, conversation.UUID, req.UserID)
+}
+
+func (l *logicImpl) SetParticipantRole(ctx context.Context, req *chat.SetParticipantRoleReq, userID string) error {
+	if req.UserID == "" {
+		return errors.New("missing participant user_id")
+	}
+	conversation, err := l.managedGroup(ctx, req.ConversationID, userID)
+	if err != nil {
+		return err
+	}
+	if req.UserID == conversation.UserID {
+		return errors.New("the creator's role cannot be changed")
+	}
+	if _, err := l.memory.GetParticipant(ctx, conversation.UUID, req.UserID); err != nil {
+		return err
+	}
+	return l.memory.SetParticipantRole(ctx, conversation.UUID, req.UserID, req.Role)
+}
+
+func (l *logicImpl) listParticipants(ctx context.Context, conversationID string) ([]chat.ParticipantItem, error) {
+	participants, err := l.memory.ListParticipants(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]chat.ParticipantItem, 0, len(participants))
+	for _, p := range participants {
+		items = append(items, chat.ParticipantItem{
+			UserID:      p.UserID,
+			DisplayName: p.DisplayName,
+			Role:        p.Role,
+			JoinedAt:    p.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// memberConversation loads a conversation the user may read and post to,
+// together with their role in it. The creator is always an owner; an empty
+// userID is a trusted internal caller.
+func (l *logicImpl) memberConversation(ctx context.Context, conversationID, userID string) (*model.Conversation, string, error) {
+	if conversationID == "" {
+		return nil, "", errors.New("missing conversation_id")
+	}
+	conversation, err := l.memory.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, "", err
+	}
+	if userID == "" || conversation.UserID == userID {
+		return conversation, model.ParticipantRoleOwner, nil
+	}
+	if conversation.Kind != model.ConversationKindGroup {
+		return nil, "", errors.New("forbidden")
+	}
+	participant, err := l.memory.GetParticipant(ctx, conversation.UUID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", errors.New("forbidden")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return conversation, participant.Role, nil
+}
+
+// managedConversation loads a conversation the user may rename, clear or
+// delete: their own, or a group they own.
+func (l *logicImpl) managedConversation(ctx context.Context, conversationID, userID string) (*model.Conversation, error) {
+	conversation, role, err := l.memberConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != model.ParticipantRoleOwner {
+		return nil, errors.New("forbidden")
+	}
+	return conversation, nil
+}
+
+func (l *logicImpl) managedGroup(ctx context.Context, conversationID, userID string) (*model.Conversation, error) {
+	conversation, err := l.managedConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Kind != model.ConversationKindGroup {
+		return nil, errors.New("not a group conversation")
+	}
+	return conversation, nil
+}
+
+// addressed reports whether the bot should answer msg. Direct conversations
+// always get a reply; in groups the bot waits until it is mentioned, either
+// by ID in msg.Mentions or by its handle in the text.
+func (l *logicImpl) addressed(conversation *model.Conversation, msg chat.Message) bool {
+	if conversation.Kind != model.ConversationKindGroup {
+		return true
+	}
+	handle := l.botHandle()
+	for _, id := range msg.Mentions {
+		if (conversation.BotID != "" && id == conversation.BotID) || strings.EqualFold(id, handle) {
+			return true
+		}
+	}
+	return mentionsHandle(msg.Content, handle)
+}
+
+// mentionsHandle finds "@handle" in text, case-insensitively, as a whole
+// word so that "@bots" or "@bot_admin" do not count.
+func mentionsHandle(text, handle string) bool {
+	text = strings.ToLower(text)
+	needle := "@" + strings.ToLower(handle)
+	for i := 0; ; {
+		j := strings.Index(text[i:], needle)
+		if j < 0 {
+			return false
+		}
+		end := i + j + len(needle)
+		if next, _ := utf8.DecodeRuneInString(text[end:]); end == len(text) || !(unicode.IsLetter(next) || unicode.IsDigit(next) || next == '_') {
+			return true
+		}
+		i = end
+	}
+}
+
+func (l *logicImpl) botHandle() string {
+	if handle := strings.TrimPrefix(strings.TrimSpace(l.svcCtx.Config.GroupConf.BotHandle), "@"); handle != "" {
+		return handle
+	}
+	return defaultBotHandle
+}
+
+func (l *logicImpl) maxParticipants() int {
+	if n := l.svcCtx.Config.GroupConf.MaxParticipants; n > 0 {
+		return n
+	}
+	return defaultMaxParticipants
+}
+
+func conversationKind(c model.Conversation) string {
+	if c.Kind == "" {
+		return model.ConversationKindDirect
+	}
+	return c.Kind
+}
+
+func toParticipantInputs(participants []chat.Participant) []memory.ParticipantInput {
+	out := make([]memory.ParticipantInput, 0, len(participants))
+	for _, p := range participants {
+		out = append(out, memory.ParticipantInput{UserID: p.UserID, DisplayName: p.DisplayName})
+	}
+	return out
+}
@@ -0,0 +1,197 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultImageSize     = "1024x1024"
+	maxGeneratedImages   = 4
+	maxGeneratedImageLen = 20 << 20
+)
+
+type imageGenerationRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	N      int    `json:"n,omitempty"`
+	Size   string `json:"size,omitempty"`
+}
+
+type imageGenerationResponse struct {
+	Data []struct {
+		URL           string `json:"url"`
+		B64JSON       string `json:"b64_json"`
+		RevisedPrompt string `json:"revised_prompt"`
+	} `json:"data"`
+}
+
+type imageMeta struct {
+	Mode          chat.CompletionMode `json:"mode"`
+	Model         string              `json:"model"`
+	Prompt        string              `json:"prompt"`
+	Size          string              `json:"size"`
+	RevisedPrompt string              `json:"revised_prompt,omitempty"`
+}
+
+func (l *logicImpl) imageResponseStream(ctx context.Context, req *chat.Completion, prompt string) (chat.MessageStream, error) {
+	size := req.ImageSize
+	if size == "" {
+		size = l.svcCtx.Config.LLMRequestConf.OpenAI.ImageSize
+	}
+	if size == "" {
+		size = defaultImageSize
+	}
+	count := req.ImageCount
+	if count <= 0 {
+		count = 1
+	}
+	if count > maxGeneratedImages {
+		count = maxGeneratedImages
+	}
+
+	images, revisedPrompt, err := l.generateImages(ctx, req.ConversationID, req.Model, prompt, size, count)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := json.Marshal(imageMeta{
+		Mode:          chat.CompletionModeImage,
+		Model:         req.Model,
+		Prompt:        prompt,
+		Size:          size,
+		RevisedPrompt: revisedPrompt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.memory.SaveAssistantMessage(ctx, req.ConversationID, memory.AssistantMessageInput{
+		ContentType: memory.ContentTypeImage,
+		Content:     revisedPrompt,
+		Images:      images,
+		Meta:        string(meta),
+		Model:       req.Model,
+	}); err != nil {
+		return nil, err
+	}
+
+	events := make([]chat.StreamEvent, 0, len(images)+1)
+	for _, img := range toChatImages(memory.SignImages(images, l.svcCtx.BlobURLs)) {
+		events = append(events, chat.StreamEvent{Type: chat.EventImage, Image: &img})
+	}
+	done := chat.StreamEvent{Type: chat.EventDone, ConversationID: req.ConversationID}
+	if title, ok := l.generateTitle(req.ConversationID, req.Model); ok {
+		done.Title = title
+	}
+	events = append(events, done)
+	return newStaticStream(events...), nil
+}
+
+func (l *logicImpl) generateImages(ctx context.Context, conversationID, modelName, prompt, size string, count int) ([]memory.ImagePart, string, error) {
+	body, err := json.Marshal(imageGenerationRequest{
+		Model:  modelName,
+		Prompt: prompt,
+		N:      count,
+		Size:   size,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := l.utils.RequestHandler.DoLong(ctx, http.MethodPost, l.urls.ImageGeneration, bytes.NewReader(body), l.headers)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var out imageGenerationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, "", err
+	}
+	if len(out.Data) == 0 {
+		return nil, "", errors.New("empty image generation response")
+	}
+
+	var revisedPrompt string
+	parts := make([]memory.ImagePart, 0, len(out.Data))
+	for _, item := range out.Data {
+		if revisedPrompt == "" {
+			revisedPrompt = strings.TrimSpace(item.RevisedPrompt)
+		}
+		data, err := l.fetchGeneratedImage(ctx, item.B64JSON, item.URL)
+		if err != nil {
+			return nil, "", err
+		}
+		part, err := l.storeImage(ctx, "images/"+conversationID, data)
+		if err != nil {
+			return nil, "", err
+		}
+		parts = append(parts, part)
+	}
+	return parts, revisedPrompt, nil
+}
+
+func (l *logicImpl) fetchGeneratedImage(ctx context.Context, b64, url string) ([]byte, error) {
+	if b64 != "" {
+		return base64.StdEncoding.DecodeString(b64)
+	}
+	if url == "" {
+		return nil, errors.New("image generation returned no data")
+	}
+	resp, err := l.utils.RequestHandler.DoLong(ctx, http.MethodGet, url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxGeneratedImageLen+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxGeneratedImageLen {
+		return nil, errors.New("generated image too large")
+	}
+	return data, nil
+}
+
+func (l *logicImpl) storeImage(ctx context.Context, prefix string, data []byte) (memory.ImagePart, error) {
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return memory.ImagePart{}, errors.New("generated content is not an image")
+	}
+	return l.putImage(ctx, prefix, data, mimeType)
+}
+
+func (l *logicImpl) putImage(ctx context.Context, prefix string, data []byte, mimeType string) (memory.ImagePart, error) {
+	key := prefix + "/" + l.utils.UUID.New() + imageExtension(mimeType)
+	obj, err := l.svcCtx.Infra.Blob.Put(ctx, key, bytes.NewReader(data), mimeType)
+	if err != nil {
+		return memory.ImagePart{}, err
+	}
+	return memory.ImagePart{
+		URL:      obj.URL,
+		MimeType: mimeType,
+		Size:     obj.Size,
+		BlobKey:  obj.Key,
+	}, nil
+}
+
+func imageExtension(mimeType string) string {
+	switch mimeType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ""
+	}
+}
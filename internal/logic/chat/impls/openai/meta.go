@@ -0,0 +1,17 @@
+package openai
+
+import "encoding/json"
+
+// messageMeta collects the metadata stored with an assistant message.
+type messageMeta map[string]any
+
+func (m messageMeta) String() string {
+	if len(m) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
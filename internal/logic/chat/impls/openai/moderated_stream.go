@@ -0,0 +1,107 @@
+package openai
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/moderation"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type moderateFunc func(text string) (string, moderation.Record, bool)
+
+// moderatedStream holds back text deltas and releases them in chunks once
+// they pass moderation. A blocked chunk ends the stream with the
+// content_filter finish reason; whatever was released before stays.
+type moderatedStream struct {
+	inner      chat.MessageStream
+	check      moderateFunc
+	chunkChars int
+	pending    strings.Builder
+	queue      []chat.StreamEvent
+	record     moderation.Record
+	blocked    bool
+	finished   bool
+}
+
+func newModeratedStream(inner chat.MessageStream, chunkChars int, check moderateFunc) *moderatedStream {
+	return &moderatedStream{inner: inner, check: check, chunkChars: chunkChars}
+}
+
+func (s *moderatedStream) Next() (chat.StreamEvent, bool, error) {
+	for {
+		if len(s.queue) > 0 {
+			ev := s.queue[0]
+			s.queue = s.queue[1:]
+			return ev, ev.Type == chat.EventDone, nil
+		}
+		if s.finished {
+			return chat.StreamEvent{Type: chat.EventDone}, true, nil
+		}
+		ev, done, err := s.inner.Next()
+		if err != nil {
+			return ev, done, err
+		}
+		if ev.Type == chat.EventTextDelta {
+			s.pending.WriteString(ev.Delta)
+			if !done {
+				s.release(s.cut())
+				continue
+			}
+		}
+		if done {
+			s.release(s.pending.String())
+			s.pending.Reset()
+			if !s.blocked {
+				if ev.Type == chat.EventTextDelta {
+					ev = chat.StreamEvent{Type: chat.EventDone, FinishReason: ev.FinishReason}
+				}
+				s.queue = append(s.queue, ev)
+			}
+			s.finished = true
+			continue
+		}
+		s.queue = append(s.queue, ev)
+	}
+}
+
+func (s *moderatedStream) Close() error {
+	return s.inner.Close()
+}
+
+// Record is the aggregate outcome of every released chunk.
+func (s *moderatedStream) Record() moderation.Record {
+	return s.record
+}
+
+// cut takes a chunk off the pending text once enough has accumulated,
+// ending it at the last whitespace so words are not split across chunks.
+func (s *moderatedStream) cut() string {
+	text := s.pending.String()
+	if utf8.RuneCountInString(text) < s.chunkChars {
+		return ""
+	}
+	end := len(text)
+	if i := strings.LastIndexFunc(text, unicode.IsSpace); i > 0 {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		end = i + size
+	}
+	s.pending.Reset()
+	s.pending.WriteString(text[end:])
+	return text[:end]
+}
+
+func (s *moderatedStream) release(text string) {
+	if text == "" || s.blocked {
+		return
+	}
+	out, record, blocked := s.check(text)
+	s.record.Merge(record)
+	if blocked {
+		s.blocked = true
+		s.finished = true
+		s.queue = append(s.queue, chat.StreamEvent{Type: chat.EventDone, FinishReason: chat.FinishReasonContentFilter})
+		return
+	}
+	s.queue = append(s.queue, chat.StreamEvent{Type: chat.EventTextDelta, Delta: out})
+}
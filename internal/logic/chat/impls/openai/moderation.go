@@ -0,0 +1,169 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/moderation"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	"github.com/im-core-go/im-core-bot-platform/pkg/utils"
+	"net/http"
+	"sort"
+)
+
+const (
+	defaultModerationModel = "omni-moderation-latest"
+	defaultModerationChunk = 200
+)
+
+var errContentFiltered = errors.New("message blocked by content filter")
+
+type moderationRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+// openAIModerator calls the /moderations endpoint. It cannot locate what
+// it flags, so redaction masks the whole text.
+type openAIModerator struct {
+	utils   *utils.Utils
+	url     string
+	headers map[string]string
+	model   string
+}
+
+func (m *openAIModerator) Moderate(ctx context.Context, text string) (*moderation.Result, error) {
+	body, err := json.Marshal(moderationRequest{Model: m.model, Input: text})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.utils.RequestHandler.DoCommon(ctx, http.MethodPost, m.url, bytes.NewReader(body), m.headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out moderationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Results) == 0 {
+		return nil, errors.New("empty moderation result")
+	}
+	result := &moderation.Result{Flagged: out.Results[0].Flagged}
+	for category, hit := range out.Results[0].Categories {
+		if hit {
+			result.Categories = append(result.Categories, category)
+		}
+	}
+	sort.Strings(result.Categories)
+	return result, nil
+}
+
+// newModerator builds the configured moderator chain, or nil when
+// moderation is disabled.
+func newModerator(svcCtx *svc.Context, u *urls, headers map[string]string) (moderation.Moderator, error) {
+	conf := svcCtx.Config.ModerationConf
+	if !conf.Enabled {
+		return nil, nil
+	}
+	for _, a := range []string{conf.InputAction, conf.OutputAction} {
+		if !moderation.ValidAction(moderation.Action(a)) {
+			return nil, fmt.Errorf("unknown moderation action %q", a)
+		}
+	}
+	for botID, p := range conf.Bots {
+		if !moderation.ValidAction(moderation.Action(p.InputAction)) || !moderation.ValidAction(moderation.Action(p.OutputAction)) {
+			return nil, fmt.Errorf("unknown moderation action for bot %s", botID)
+		}
+	}
+	var moderators []moderation.Moderator
+	for _, provider := range conf.Providers {
+		switch provider {
+		case "rules":
+			m, err := moderation.NewRuleModerator(conf.Rules)
+			if err != nil {
+				return nil, err
+			}
+			moderators = append(moderators, m)
+		case "openai":
+			modelName := conf.Model
+			if modelName == "" {
+				modelName = defaultModerationModel
+			}
+			moderators = append(moderators, &openAIModerator{
+				utils:   svcCtx.Utils,
+				url:     u.Moderations,
+				headers: headers,
+				model:   modelName,
+			})
+		default:
+			return nil, fmt.Errorf("unknown moderation provider %q", provider)
+		}
+	}
+	if len(moderators) == 0 {
+		return nil, errors.New("moderation enabled without providers")
+	}
+	return moderation.Chain(moderators...), nil
+}
+
+func (l *logicImpl) moderationPolicy(botID string) moderation.Policy {
+	if l.moderator == nil {
+		return moderation.Policy{}
+	}
+	return moderation.PolicyFor(l.svcCtx.Config.ModerationConf, botID)
+}
+
+// moderate applies action to text and reports the text to use, the
+// outcome to store and whether the text is blocked. A failing moderator
+// lets the text through when FailOpen is set and flags it otherwise.
+func (l *logicImpl) moderate(ctx context.Context, action moderation.Action, text string) (string, moderation.Record, bool) {
+	if l.moderator == nil || action == moderation.ActionNone || text == "" {
+		return text, moderation.Record{}, false
+	}
+	record := moderation.Record{}
+	result, err := l.moderator.Moderate(ctx, text)
+	if err != nil {
+		logger.L().Errorf("moderate text error: %v", err)
+		record.Error = err.Error()
+		if l.svcCtx.Config.ModerationConf.FailOpen {
+			return text, record, false
+		}
+		result = &moderation.Result{Flagged: true}
+	}
+	if !result.Flagged {
+		return text, record, false
+	}
+	record.Action = action
+	record.Categories = result.Categories
+	switch action {
+	case moderation.ActionRedact:
+		return moderation.Redact(text, result), record, false
+	case moderation.ActionBlock:
+		return "", record, true
+	}
+	return text, record, false
+}
+
+func (l *logicImpl) outputChunkChars() int {
+	if n := l.svcCtx.Config.ModerationConf.OutputChunkChars; n > 0 {
+		return n
+	}
+	return defaultModerationChunk
+}
+
+// logInputBlocked records the outcome for a blocked message, which is not
+// saved. The text itself is never logged.
+func logInputBlocked(conversationID, userID string, record moderation.Record) {
+	logger.L().Infof("moderation blocked input conversation=%s user=%s record=%s", conversationID, userID, record.String())
+}
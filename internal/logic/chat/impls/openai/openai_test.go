@@ -0,0 +1,141 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+	http2 "github.com/im-core-go/im-core-bot-platform/pkg/http"
+	"github.com/im-core-go/im-core-bot-platform/pkg/infra"
+	"github.com/im-core-go/im-core-bot-platform/pkg/openaitest"
+	"github.com/im-core-go/im-core-bot-platform/pkg/regexp"
+	"github.com/im-core-go/im-core-bot-platform/pkg/utils"
+	"github.com/im-core-go/im-core-bot-platform/pkg/uuid"
+)
+
+const testModel = "mock-model"
+
+// newTestLogic returns a chat logic talking to a fresh mock provider, with
+// mem standing in for the database-backed memory.
+func newTestLogic(t *testing.T, conf configs.Config, mem memory.Manager) (*logicImpl, *openaitest.Server) {
+	t.Helper()
+	srv := openaitest.NewServer()
+	t.Cleanup(srv.Close)
+
+	conf.LLMRequestConf.OpenAI.BaseURL = srv.URL + "/v1"
+	store, err := blob.NewLocalStore(t.TempDir(), "http://blobs.test")
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	svcCtx := &svc.Context{
+		Config: conf,
+		Utils: &utils.Utils{
+			Regexp:         regexp.NewHandler(),
+			RequestHandler: http2.NewRequestHandler(),
+			UUID:           uuid.NewWrap(),
+		},
+		Infra: &infra.Infra{Blob: store},
+	}
+	piiEngine, err := newPIIEngine(svcCtx)
+	if err != nil {
+		t.Fatalf("pii engine: %v", err)
+	}
+	headers := map[string]string{
+		"Authorization": "Bearer test",
+		"Content-Type":  "application/json",
+	}
+	return &logicImpl{
+		svcCtx:  svcCtx,
+		utils:   svcCtx.Utils,
+		urls:    newURLs(conf.LLMRequestConf.OpenAI.BaseURL),
+		authKey: headers["Authorization"],
+		headers: headers,
+		memory:  mem,
+		images:  newImagePolicy(conf.LLMRequestConf.OpenAI, conf.ImageInputConf),
+		pii:     piiEngine,
+	}, srv
+}
+
+// fakeMemory implements the parts of memory.Manager title generation,
+// summarization and the response cache use; anything else panics on the
+// nil embedded interface.
+type fakeMemory struct {
+	memory.Manager
+
+	mu           sync.Mutex
+	conversation model.Conversation
+	titleMsgs    []memory.PromptMessage
+	summaryMsgs  []memory.PromptMessage
+	savedTitle   string
+	counts       map[string]int64
+	err          error
+}
+
+func (m *fakeMemory) GetConversation(_ context.Context, conversationID string) (*model.Conversation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	c := m.conversation
+	c.UUID = conversationID
+	return &c, nil
+}
+
+func (m *fakeMemory) CountMessages(_ context.Context, conversationID string) (int64, error) {
+	return m.counts[conversationID], m.err
+}
+
+func (m *fakeMemory) BuildTitleMessages(context.Context, string, int) ([]memory.PromptMessage, error) {
+	return m.titleMsgs, nil
+}
+
+func (m *fakeMemory) BuildSummaryMessages(context.Context, string, int) ([]memory.PromptMessage, error) {
+	return m.summaryMsgs, nil
+}
+
+func (m *fakeMemory) UpdateGeneratedTitle(_ context.Context, _ string, title string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.savedTitle = title
+	return nil
+}
+
+func (m *fakeMemory) title() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.savedTitle
+}
+
+var errNotFound = errors.New("not found")
+
+// drain reads a stream to its end, returning its events and the error that
+// stopped it, if any.
+func drain(s chat.MessageStream) ([]chat.StreamEvent, error) {
+	var events []chat.StreamEvent
+	for {
+		ev, done, err := s.Next()
+		if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+		if done {
+			return events, nil
+		}
+	}
+}
+
+func deltas(events []chat.StreamEvent, typ chat.StreamEventType) string {
+	var s string
+	for _, ev := range events {
+		if ev.Type == typ {
+			s += ev.Delta
+		}
+	}
+	return s
+}
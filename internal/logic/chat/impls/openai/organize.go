@@ -0,0 +1,157 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+func (l *logicImpl) PinConversation(ctx context.Context, req *chat.PinConversationReq, userID string) error {
+	if _, err := l.ownedConversation(ctx, req.ConversationID, userID); err != nil {
+		return err
+	}
+	return l.memory.PinConversation(ctx, req.ConversationID, req.Pinned)
+}
+
+func (l *logicImpl) ArchiveConversation(ctx context.Context, req *chat.ArchiveConversationReq, userID string) error {
+	if _, err := l.ownedConversation(ctx, req.ConversationID, userID); err != nil {
+		return err
+	}
+	return l.memory.ArchiveConversation(ctx, req.ConversationID, req.Archived)
+}
+
+// MoveConversation files the conversation into a folder; an empty folder ID
+// moves it back to the top level.
+func (l *logicImpl) MoveConversation(ctx context.Context, req *chat.MoveConversationReq, userID string) error {
+	if _, err := l.ownedConversation(ctx, req.ConversationID, userID); err != nil {
+		return err
+	}
+	if req.FolderID != "" {
+		if _, err := l.ownedFolder(ctx, req.FolderID, userID); err != nil {
+			return err
+		}
+	}
+	return l.memory.MoveConversation(ctx, req.ConversationID, req.FolderID)
+}
+
+func (l *logicImpl) SetConversationTags(ctx context.Context, req *chat.SetConversationTagsReq, userID string) ([]string, error) {
+	conversation, err := l.ownedConversation(ctx, req.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return l.memory.SetConversationTags(ctx, conversation, req.Tags)
+}
+
+func (l *logicImpl) ListTags(ctx context.Context, userID string) ([]chat.TagItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	counts, err := l.memory.ListTags(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]chat.TagItem, 0, len(counts))
+	for _, c := range counts {
+		items = append(items, chat.TagItem{Tag: c.Tag, Count: c.Count})
+	}
+	return items, nil
+}
+
+func (l *logicImpl) CreateFolder(ctx context.Context, req *chat.CreateFolderReq, userID string) (*chat.FolderItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	folder, err := l.memory.CreateFolder(ctx, userID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	item := toFolderItem(*folder)
+	return &item, nil
+}
+
+func (l *logicImpl) ListFolders(ctx context.Context, userID string) ([]chat.FolderItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	folders, err := l.memory.ListFolders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]chat.FolderItem, 0, len(folders))
+	for _, folder := range folders {
+		items = append(items, toFolderItem(folder))
+	}
+	return items, nil
+}
+
+func (l *logicImpl) RenameFolder(ctx context.Context, req *chat.RenameFolderReq, userID string) error {
+	if _, err := l.ownedFolder(ctx, req.FolderID, userID); err != nil {
+		return err
+	}
+	return l.memory.RenameFolder(ctx, req.FolderID, req.Name)
+}
+
+func (l *logicImpl) DeleteFolder(ctx context.Context, req *chat.DeleteFolderReq, userID string) error {
+	if _, err := l.ownedFolder(ctx, req.FolderID, userID); err != nil {
+		return err
+	}
+	return l.memory.DeleteFolder(ctx, req.FolderID)
+}
+
+// ownedConversation is stricter than managedConversation: pin, archive,
+// folder and tags are stored on the conversation and belong to its creator,
+// so other owners of a group cannot change them.
+func (l *logicImpl) ownedConversation(ctx context.Context, conversationID, userID string) (*model.Conversation, error) {
+	if conversationID == "" {
+		return nil, errors.New("missing conversation_id")
+	}
+	conversation, err := l.memory.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && conversation.UserID != userID {
+		return nil, errors.New("forbidden")
+	}
+	return conversation, nil
+}
+
+func (l *logicImpl) ownedFolder(ctx context.Context, folderID, userID string) (*model.Folder, error) {
+	if folderID == "" {
+		return nil, errors.New("missing folder_id")
+	}
+	folder, err := l.memory.GetFolder(ctx, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && folder.UserID != userID {
+		return nil, errors.New("forbidden")
+	}
+	return folder, nil
+}
+
+func toConversationItem(c model.Conversation, tags []string) chat.ConversationItem {
+	return chat.ConversationItem{
+		ConversationID: c.UUID,
+		BotID:          c.BotID,
+		Kind:           conversationKind(c),
+		Title:          c.Title,
+		Model:          c.Model,
+		FolderID:       c.FolderID,
+		Pinned:         c.Pinned,
+		Archived:       c.Archived,
+		Tags:           tags,
+		CreatedAt:      c.CreatedAt,
+		UpdatedAt:      c.UpdatedAt,
+		DeletedAt:      int64(c.DeletedAt),
+	}
+}
+
+func toFolderItem(f model.Folder) chat.FolderItem {
+	return chat.FolderItem{
+		FolderID:  f.UUID,
+		Name:      f.Name,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
@@ -3,39 +3,75 @@ package openai
 import (
 	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
 	"strings"
+	"time"
 )
 
+type streamResult struct {
+	Content   string
+	Reasoning string
+	Usage     *chat.Usage
+	// FirstTokenAt is when the first text or reasoning arrived; zero if
+	// none did.
+	FirstTokenAt time.Time
+	// Finished is set when the stream reached its done event rather than
+	// failing or being closed early.
+	Finished     bool
+	FinishReason string
+}
+
 type persistedStream struct {
-	inner      chat.MessageStream
-	onComplete func(content string) error
-	builder    strings.Builder
-	done       bool
-	ctx        *streamContext
+	inner            chat.MessageStream
+	onComplete       func(result streamResult) error
+	builder          strings.Builder
+	reasoning        strings.Builder
+	includeReasoning bool
+	done             bool
+	ctx              *streamContext
+	usage            *chat.Usage
+	firstTokenAt     time.Time
+	finished         bool
+	finishReason     string
 }
 
-func newPersistedStream(inner chat.MessageStream, onComplete func(content string) error) chat.MessageStream {
+func newPersistedStream(inner chat.MessageStream, includeReasoning bool, onComplete func(result streamResult) error) chat.MessageStream {
 	return &persistedStream{
-		inner:      inner,
-		onComplete: onComplete,
+		inner:            inner,
+		onComplete:       onComplete,
+		includeReasoning: includeReasoning,
 	}
 }
 
 func (p *persistedStream) Next() (chat.StreamEvent, bool, error) {
-	ev, done, err := p.inner.Next()
-	if err != nil {
-		return ev, done, err
-	}
-	if ev.Type == chat.EventTextDelta {
-		p.builder.WriteString(ev.Delta)
-	}
-	if done {
-		p.flushOnce()
-		if p.ctx != nil {
-			ev.ConversationID = p.ctx.conversationID
-			ev.Title = p.ctx.getTitle()
+	for {
+		ev, done, err := p.inner.Next()
+		if err != nil {
+			return ev, done, err
+		}
+		if p.firstTokenAt.IsZero() && ev.Delta != "" {
+			p.firstTokenAt = time.Now()
+		}
+		switch ev.Type {
+		case chat.EventTextDelta:
+			p.builder.WriteString(ev.Delta)
+		case chat.EventReasoningDelta:
+			p.reasoning.WriteString(ev.Delta)
+			if !p.includeReasoning && !done {
+				continue
+			}
+		}
+		if done {
+			p.usage = ev.Usage
+			p.finished = ev.Type == chat.EventDone
+			p.finishReason = ev.FinishReason
+			p.flushOnce()
+			if p.ctx != nil {
+				ev.ConversationID = p.ctx.conversationID
+				ev.Title = p.ctx.getTitle()
+				ev.Citations = p.ctx.citations
+			}
 		}
+		return ev, done, nil
 	}
-	return ev, done, nil
 }
 
 func (p *persistedStream) Close() error {
@@ -48,5 +84,12 @@ func (p *persistedStream) flushOnce() {
 		return
 	}
 	p.done = true
-	_ = p.onComplete(p.builder.String())
+	_ = p.onComplete(streamResult{
+		Content:      p.builder.String(),
+		Reasoning:    p.reasoning.String(),
+		Usage:        p.usage,
+		FirstTokenAt: p.firstTokenAt,
+		Finished:     p.finished,
+		FinishReason: p.finishReason,
+	})
 }
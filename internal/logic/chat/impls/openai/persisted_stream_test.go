@@ -0,0 +1,142 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/pkg/openaitest"
+)
+
+// recordResults returns an onComplete hook and the results it received.
+func recordResults() (func(streamResult) error, *[]streamResult) {
+	var results []streamResult
+	return func(r streamResult) error {
+		results = append(results, r)
+		return nil
+	}, &results
+}
+
+func TestPersistedStreamSavesHiddenReasoning(t *testing.T) {
+	var conf configs.Config
+	conf.LLMRequestConf.OpenAI.StreamUsage = true
+	l, srv := newTestLogic(t, conf, nil)
+	srv.Queue(openaitest.Reply{
+		Reasoning: "let me think",
+		Content:   "the answer",
+		ChunkSize: 4,
+		Usage:     &openaitest.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7},
+	})
+	onComplete, results := recordResults()
+	stream := newPersistedStream(openTestStream(t, context.Background(), l), false, onComplete)
+
+	events, err := drain(stream)
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if got := deltas(events, chat.EventReasoningDelta); got != "" {
+		t.Errorf("reasoning reached the client: %q", got)
+	}
+	if got := deltas(events, chat.EventTextDelta); got != "the answer" {
+		t.Errorf("content = %q, want %q", got, "the answer")
+	}
+	_ = stream.Close()
+
+	if len(*results) != 1 {
+		t.Fatalf("onComplete called %d times, want once", len(*results))
+	}
+	r := (*results)[0]
+	if r.Content != "the answer" || r.Reasoning != "let me think" {
+		t.Errorf("saved content %q and reasoning %q", r.Content, r.Reasoning)
+	}
+	if !r.Finished || r.FinishReason != "stop" {
+		t.Errorf("finished = %v with reason %q, want a stopped stream", r.Finished, r.FinishReason)
+	}
+	if r.Usage == nil || r.Usage.PromptTokens != 5 || r.Usage.CompletionTokens != 2 {
+		t.Errorf("usage = %+v, want 5 prompt and 2 completion tokens", r.Usage)
+	}
+	if r.FirstTokenAt.IsZero() {
+		t.Error("first token time not recorded")
+	}
+}
+
+func TestPersistedStreamIncludesReasoning(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Queue(openaitest.Reply{Reasoning: "let me think", Content: "ok"})
+	stream := newPersistedStream(openTestStream(t, context.Background(), l), true, nil)
+
+	events, err := drain(stream)
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if got := deltas(events, chat.EventReasoningDelta); got != "let me think" {
+		t.Errorf("reasoning = %q, want %q", got, "let me think")
+	}
+}
+
+func TestPersistedStreamClosedEarly(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Queue(openaitest.Reply{Content: "abcdefghijkl", ChunkSize: 4})
+	onComplete, results := recordResults()
+	stream := newPersistedStream(openTestStream(t, context.Background(), l), false, onComplete)
+
+	if _, _, err := stream.Next(); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	_ = stream.Close()
+	_ = stream.Close()
+
+	if len(*results) != 1 {
+		t.Fatalf("onComplete called %d times, want once", len(*results))
+	}
+	if r := (*results)[0]; r.Finished || r.Content != "abcd" {
+		t.Errorf("result = %+v, want the unfinished partial content", r)
+	}
+}
+
+func TestPersistedStreamUpstreamDisconnect(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Queue(openaitest.Reply{Content: "abcdefghijkl", ChunkSize: 4})
+	srv.Fail(openaitest.Fault{Disconnect: true, AfterChunks: 1})
+	onComplete, results := recordResults()
+	stream := newPersistedStream(openTestStream(t, context.Background(), l), false, onComplete)
+
+	if _, err := drain(stream); err == nil {
+		t.Fatal("drain succeeded, want the dropped connection reported")
+	}
+	_ = stream.Close()
+
+	if len(*results) != 1 {
+		t.Fatalf("onComplete called %d times, want once", len(*results))
+	}
+	if r := (*results)[0]; r.Finished || r.Content != "abcd" {
+		t.Errorf("result = %+v, want the unfinished partial content", r)
+	}
+}
+
+func TestPersistedStreamContext(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Queue(openaitest.Reply{Content: "ok"})
+	stream := newPersistedStream(openTestStream(t, context.Background(), l), false, nil)
+	citations := []chat.Citation{{DocumentTitle: "doc"}}
+	l.setStreamContext("conv-1", citations, stream)
+	l.setStreamTitle(stream, "Greeting")
+
+	events, err := drain(stream)
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.ConversationID != "conv-1" || last.Title != "Greeting" {
+		t.Errorf("done event = %+v, want the conversation and title", last)
+	}
+	if len(last.Citations) != 1 || last.Citations[0].DocumentTitle != "doc" {
+		t.Errorf("citations = %+v, want the retrieved document", last.Citations)
+	}
+	for _, ev := range events[:len(events)-1] {
+		if ev.ConversationID != "" {
+			t.Errorf("context on a delta event: %+v", ev)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/moderation"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/pii"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	"sort"
+	"strings"
+)
+
+func newPIIEngine(svcCtx *svc.Context) (*pii.Engine, error) {
+	conf := svcCtx.Config.PIIConf
+	if !conf.Enabled {
+		return nil, nil
+	}
+	return pii.NewEngine(conf, svcCtx.Utils.Regexp)
+}
+
+// piiSession starts redaction for one request, or returns nil when the bot
+// redacts nothing; a nil session passes text through.
+func (l *logicImpl) piiSession(botID string) *pii.Session {
+	if l.pii == nil {
+		return nil
+	}
+	return l.pii.NewSession(botID)
+}
+
+// redactPrompt returns a copy of messages with personal data replaced.
+func redactPrompt(s *pii.Session, messages []memory.PromptMessage) ([]memory.PromptMessage, error) {
+	if s == nil {
+		return messages, nil
+	}
+	out := make([]memory.PromptMessage, len(messages))
+	for i, msg := range messages {
+		content, err := s.Redact(msg.Content)
+		if err != nil {
+			return nil, err
+		}
+		msg.Content = content
+		out[i] = msg
+	}
+	return out, nil
+}
+
+// moderateInput moderates text with personal data redacted, so a remote
+// moderator never sees it, and returns the text with the values restored.
+func (l *logicImpl) moderateInput(ctx context.Context, s *pii.Session, action moderation.Action, text string) (string, moderation.Record, bool, error) {
+	redacted, err := s.Redact(text)
+	if err != nil {
+		return "", moderation.Record{}, false, err
+	}
+	out, record, blocked := l.moderate(ctx, action, redacted)
+	return s.Restore(out), record, blocked, nil
+}
+
+// redactedSummarizer summarizes history without sending personal data
+// upstream; the stored summary keeps the original values.
+func (l *logicImpl) redactedSummarizer(s *pii.Session) memory.Summarizer {
+	return func(ctx context.Context, modelName string, messages []memory.PromptMessage) (string, error) {
+		redacted, err := redactPrompt(s, messages)
+		if err != nil {
+			return "", err
+		}
+		summary, err := l.doCompletionFromPrompt(ctx, modelName, redacted)
+		if err != nil {
+			return "", err
+		}
+		return s.Restore(summary), nil
+	}
+}
+
+// withPIIInstruction asks the model to keep placeholders intact when the
+// prompt has any.
+func withPIIInstruction(s *pii.Session, messages []memory.PromptMessage) []memory.PromptMessage {
+	instruction := s.Instruction()
+	if instruction == "" {
+		return messages
+	}
+	return append([]memory.PromptMessage{{Role: "system", Content: instruction}}, messages...)
+}
+
+// logRedactions records how much was redacted, never the values.
+func logRedactions(s *pii.Session, conversationID string) {
+	counts := s.Counts()
+	if len(counts) == 0 {
+		return
+	}
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s=%d", kind, counts[kind]))
+	}
+	logger.L().Infof("pii redacted conversation=%s %s", conversationID, strings.Join(parts, " "))
+}
+
+// restoredStream puts redacted values back into the streamed reply.
+type restoredStream struct {
+	inner    chat.MessageStream
+	session  *pii.Session
+	restorer *pii.Restorer
+	pending  *chat.StreamEvent
+}
+
+func newRestoredStream(inner chat.MessageStream, s *pii.Session) chat.MessageStream {
+	return &restoredStream{inner: inner, session: s, restorer: s.NewRestorer()}
+}
+
+func (r *restoredStream) Next() (chat.StreamEvent, bool, error) {
+	if r.pending != nil {
+		ev := *r.pending
+		r.pending = nil
+		return ev, true, nil
+	}
+	for {
+		ev, done, err := r.inner.Next()
+		if err != nil {
+			return ev, done, err
+		}
+		switch ev.Type {
+		case chat.EventTextDelta:
+			ev.Delta = r.restorer.Write(ev.Delta)
+			if ev.Delta == "" && !done {
+				continue
+			}
+		case chat.EventReasoningDelta:
+			ev.Delta = r.session.Restore(ev.Delta)
+		}
+		if done {
+			if rest := r.restorer.Flush(); rest != "" {
+				if ev.Type == chat.EventTextDelta {
+					ev.Delta += rest
+				} else {
+					r.pending = &ev
+					return chat.StreamEvent{Type: chat.EventTextDelta, Delta: rest}, false, nil
+				}
+			}
+		}
+		return ev, done, nil
+	}
+}
+
+func (r *restoredStream) Close() error {
+	return r.inner.Close()
+}
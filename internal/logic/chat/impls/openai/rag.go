@@ -0,0 +1,80 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	"strings"
+)
+
+const defaultKnowledgeContextTokens = 2000
+
+// retrieveContext looks up the bot's knowledge bases visible to the user
+// for the latest user message. Retrieval failures are logged and the reply goes ahead without
+// references rather than failing the whole turn. Each chunk passes the
+// injection guard, which may tag or drop it. private reports that a cited
+// chunk comes from the user's own knowledge base, so the reply is only
+// theirs to see.
+func (l *logicImpl) retrieveContext(ctx context.Context, botID, userID, query string, guard *injectionGuard) (ref *memory.PromptMessage, citations []chat.Citation, private bool) {
+	if botID == "" || strings.TrimSpace(query) == "" {
+		return nil, nil, false
+	}
+	chunks, err := l.knowledge.Retrieve(ctx, botID, userID, query)
+	if err != nil {
+		logger.L().Errorf("retrieve knowledge for bot %s error: %v", botID, err)
+		return nil, nil, false
+	}
+	if len(chunks) == 0 {
+		return nil, nil, false
+	}
+
+	budget := l.svcCtx.Config.KnowledgeConf.ContextTokens
+	if budget <= 0 {
+		budget = defaultKnowledgeContextTokens
+	}
+	var b strings.Builder
+	b.WriteString("Answer using the reference documents below when they are relevant. ")
+	b.WriteString("Cite the documents you use as [n]. If they do not contain the answer, say so instead of guessing.\n")
+	citations = make([]chat.Citation, 0, len(chunks))
+	for _, chunk := range chunks {
+		content, ok := guard.screenDocument(ctx, chunk.DocumentID, strings.TrimSpace(chunk.Content))
+		if !ok {
+			continue
+		}
+		entry := fmt.Sprintf("\n[%d] %s\n%s\n", len(citations)+1, chunk.DocumentTitle, content)
+		cost := memory.EstimateTokens(entry)
+		if cost > budget {
+			if len(citations) > 0 {
+				break
+			}
+			entry = memory.TruncateToTokens(entry, budget)
+			cost = memory.EstimateTokens(entry)
+		}
+		budget -= cost
+		b.WriteString(entry)
+		private = private || chunk.Private
+		citations = append(citations, chat.Citation{
+			KnowledgeBaseID: chunk.KnowledgeBaseID,
+			DocumentID:      chunk.DocumentID,
+			DocumentTitle:   chunk.DocumentTitle,
+			ChunkIndex:      chunk.ChunkIndex,
+			Score:           chunk.Score,
+		})
+	}
+	if len(citations) == 0 {
+		return nil, nil, false
+	}
+	return &memory.PromptMessage{Role: "system", Content: b.String()}, citations, private
+}
+
+func insertBeforeLast(prompt []memory.PromptMessage, msg memory.PromptMessage) []memory.PromptMessage {
+	if len(prompt) == 0 {
+		return []memory.PromptMessage{msg}
+	}
+	out := make([]memory.PromptMessage, 0, len(prompt)+1)
+	out = append(out, prompt[:len(prompt)-1]...)
+	out = append(out, msg, prompt[len(prompt)-1])
+	return out
+}
@@ -0,0 +1,97 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao"
+	knowledgedao "github.com/im-core-go/im-core-bot-platform/internal/dao/knowledge"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/knowledge"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
+)
+
+// fakeKnowledgeDao serves fixed knowledge bases and chunks; anything else
+// panics on the nil embedded interface.
+type fakeKnowledgeDao struct {
+	knowledgedao.Dao
+
+	kbs    []model.KnowledgeBase
+	chunks map[int64]model.KnowledgeChunk
+}
+
+func (d *fakeKnowledgeDao) ListKnowledgeBasesByBot(string) ([]model.KnowledgeBase, error) {
+	return d.kbs, nil
+}
+
+func (d *fakeKnowledgeDao) ListChunksByIDs(ids []int64) ([]model.KnowledgeChunk, error) {
+	var out []model.KnowledgeChunk
+	for _, id := range ids {
+		out = append(out, d.chunks[id])
+	}
+	return out, nil
+}
+
+func (d *fakeKnowledgeDao) GetDocumentByID(docID string) (*model.KnowledgeDocument, error) {
+	return &model.KnowledgeDocument{UUID: docID, Title: docID}, nil
+}
+
+// fakeVectorStore backs the in-memory index with fixed namespaces.
+type fakeVectorStore struct {
+	vector.Store
+
+	records map[string][]vector.Record
+}
+
+func (s *fakeVectorStore) LoadNamespace(_ context.Context, namespace string) ([]vector.Record, error) {
+	return s.records[namespace], nil
+}
+
+type fixedEmbedder []float32
+
+func (e fixedEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	out := make([][]float32, len(inputs))
+	for i := range inputs {
+		out[i] = e
+	}
+	return out, nil
+}
+
+func TestRetrieveContextPrivateKnowledgeBase(t *testing.T) {
+	var conf configs.Config
+	conf.KnowledgeConf.BotOwners = map[string][]string{"bot-1": {"owner"}}
+	l, _ := newTestLogic(t, conf, nil)
+	kbDao := &fakeKnowledgeDao{
+		kbs: []model.KnowledgeBase{
+			{UUID: "kb-shared", BotID: "bot-1", OwnerID: "owner"},
+			{UUID: "kb-alice", BotID: "bot-1", OwnerID: "alice"},
+		},
+		chunks: map[int64]model.KnowledgeChunk{
+			1: {ID: 1, KnowledgeBaseID: "kb-shared", DocumentID: "handbook", Content: "Offices open at nine."},
+			2: {ID: 2, KnowledgeBaseID: "kb-alice", DocumentID: "salary", Content: "Alice earns a lot."},
+		},
+	}
+	store := &fakeVectorStore{records: map[string][]vector.Record{
+		"kb:kb-shared": {{ID: "1", Vector: []float32{1, 0}}},
+		"kb:kb-alice":  {{ID: "2", Vector: []float32{1, 0}}},
+	}}
+	l.svcCtx.Dao = &dao.Dao{KnowledgeDao: kbDao}
+	l.svcCtx.VectorIndex = vector.NewMemoryIndex(store, 0, 0)
+	l.knowledge = knowledge.NewLogic(l.svcCtx, fixedEmbedder{1, 0})
+	ctx := context.Background()
+
+	ref, citations, private := l.retrieveContext(ctx, "bot-1", "alice", "what do I earn?", nil)
+	if ref == nil || len(citations) != 2 || !private {
+		t.Fatalf("alice: citations = %+v, private = %v, want both documents and a private reply", citations, private)
+	}
+
+	ref, citations, private = l.retrieveContext(ctx, "bot-1", "bob", "what do I earn?", nil)
+	if ref == nil || private {
+		t.Fatalf("bob: private = %v, want a shareable reply", private)
+	}
+	if len(citations) != 1 || citations[0].KnowledgeBaseID != "kb-shared" || strings.Contains(ref.Content, "Alice") {
+		t.Errorf("bob: citations = %+v, prompt %q, want only the bot's knowledge base", citations, ref.Content)
+	}
+}
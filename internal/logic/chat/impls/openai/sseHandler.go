@@ -9,32 +9,80 @@ import (
 	"strings"
 )
 
+// chatCompletionsStream turns completion chunks into stream events. When
+// usage was requested it arrives in a chunk after the finish reason, so the
+// done event is held back until the stream ends.
 type chatCompletionsStream struct {
-	sr *http2.SSEReader
+	sr         *http2.SSEReader
+	pending    []chat.StreamEvent
+	awaitUsage bool
+	finished   *chat.StreamEvent
 }
 
 type ChatCompletionChunk struct {
 	Choices []struct {
-		Delta struct {
-			Content string `json:"content"`
-		} `json:"delta"`
-		FinishReason *string `json:"finish_reason"`
+		Delta        completionDelta `json:"delta"`
+		FinishReason *string         `json:"finish_reason"`
 	} `json:"choices"`
+	Usage *completionUsage `json:"usage"`
 }
 
-func newOpenAIChatCompletionsStream(sr *http2.SSEReader) *chatCompletionsStream {
-	return &chatCompletionsStream{sr: sr}
+type completionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (u *completionUsage) toChat() *chat.Usage {
+	if u == nil {
+		return nil
+	}
+	return &chat.Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
+}
+
+// completionDelta covers the reasoning field names used by the
+// OpenAI-compatible gateways we talk to: reasoning_content (DeepSeek, Qwen),
+// reasoning (OpenRouter, vLLM) and thinking (Claude-style gateways).
+type completionDelta struct {
+	Content          string `json:"content"`
+	ReasoningContent string `json:"reasoning_content"`
+	Reasoning        string `json:"reasoning"`
+	Thinking         string `json:"thinking"`
+}
+
+func (d completionDelta) reasoningText() string {
+	switch {
+	case d.ReasoningContent != "":
+		return d.ReasoningContent
+	case d.Reasoning != "":
+		return d.Reasoning
+	default:
+		return d.Thinking
+	}
+}
+
+func newOpenAIChatCompletionsStream(sr *http2.SSEReader, awaitUsage bool) *chatCompletionsStream {
+	return &chatCompletionsStream{sr: sr, awaitUsage: awaitUsage}
 }
 
 func (s *chatCompletionsStream) Close() error { return s.sr.Close() }
 
 func (s *chatCompletionsStream) Next() (chat.StreamEvent, bool, error) {
 	for {
+		if len(s.pending) > 0 {
+			ev := s.pending[0]
+			s.pending = s.pending[1:]
+			return ev, ev.Type == chat.EventDone, nil
+		}
 		ev, ok, err := s.sr.Next()
 		if err != nil {
 			return chat.StreamEvent{}, false, err
 		}
 		if !ok {
+			if s.finished != nil {
+				ev := *s.finished
+				s.finished = nil
+				return ev, true, nil
+			}
 			return chat.StreamEvent{Type: chat.EventDone}, true, nil
 		}
 		if strings.TrimSpace(ev.Data) == "" {
@@ -47,15 +95,26 @@ func (s *chatCompletionsStream) Next() (chat.StreamEvent, bool, error) {
 			return chat.StreamEvent{Type: chat.EventError}, false, errors.New("invalid stream json")
 		}
 
+		if e.Usage != nil && s.finished != nil {
+			s.finished.Usage = e.Usage.toChat()
+		}
 		if len(e.Choices) == 0 {
 			continue
 		}
 		choice := e.Choices[0]
+		if reasoning := choice.Delta.reasoningText(); reasoning != "" {
+			s.pending = append(s.pending, chat.StreamEvent{Type: chat.EventReasoningDelta, Delta: reasoning})
+		}
 		if choice.Delta.Content != "" {
-			return chat.StreamEvent{Type: chat.EventTextDelta, Delta: choice.Delta.Content}, false, nil
+			s.pending = append(s.pending, chat.StreamEvent{Type: chat.EventTextDelta, Delta: choice.Delta.Content})
 		}
 		if choice.FinishReason != nil && *choice.FinishReason != "" {
-			return chat.StreamEvent{Type: chat.EventDone}, true, nil
+			done := chat.StreamEvent{Type: chat.EventDone, FinishReason: *choice.FinishReason, Usage: e.Usage.toChat()}
+			if s.awaitUsage && done.Usage == nil {
+				s.finished = &done
+				continue
+			}
+			s.pending = append(s.pending, done)
 		}
 	}
 }
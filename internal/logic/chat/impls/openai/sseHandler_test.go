@@ -0,0 +1,150 @@
+package openai
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/pkg/openaitest"
+)
+
+func openTestStream(t *testing.T, ctx context.Context, l *logicImpl) *chatCompletionsStream {
+	t.Helper()
+	sr, err := l.doStreamCompletion(ctx, testModel, []memory.PromptMessage{{Role: "user", Content: "hi"}}, generationOptions{})
+	if err != nil {
+		t.Fatalf("doStreamCompletion: %v", err)
+	}
+	s := newOpenAIChatCompletionsStream(sr, l.svcCtx.Config.LLMRequestConf.OpenAI.StreamUsage)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestChatCompletionsStreamDeltas(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Queue(openaitest.Reply{Reasoning: "think first", Content: "Hello, 世界!", ChunkSize: 3})
+
+	events, err := drain(openTestStream(t, context.Background(), l))
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if got := deltas(events, chat.EventReasoningDelta); got != "think first" {
+		t.Errorf("reasoning = %q, want %q", got, "think first")
+	}
+	if got := deltas(events, chat.EventTextDelta); got != "Hello, 世界!" {
+		t.Errorf("content = %q, want %q", got, "Hello, 世界!")
+	}
+	seenText := false
+	for _, ev := range events {
+		switch ev.Type {
+		case chat.EventTextDelta:
+			seenText = true
+		case chat.EventReasoningDelta:
+			if seenText {
+				t.Fatalf("reasoning delta after content: %+v", events)
+			}
+		}
+	}
+	last := events[len(events)-1]
+	if last.Type != chat.EventDone || last.FinishReason != "stop" {
+		t.Errorf("last event = %+v, want done with finish reason stop", last)
+	}
+	if last.Usage != nil {
+		t.Errorf("usage = %+v without stream_usage", last.Usage)
+	}
+	if reqs := srv.Requests(); len(reqs) != 1 || !reqs[0].Stream || reqs[0].StreamOptions != nil {
+		t.Errorf("requests = %+v, want one stream without stream_options", reqs)
+	}
+}
+
+func TestChatCompletionsStreamUsage(t *testing.T) {
+	var conf configs.Config
+	conf.LLMRequestConf.OpenAI.StreamUsage = true
+	l, srv := newTestLogic(t, conf, nil)
+	srv.Queue(openaitest.Reply{
+		Content:      "truncated",
+		FinishReason: "length",
+		Usage:        &openaitest.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+	})
+
+	events, err := drain(openTestStream(t, context.Background(), l))
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Type != chat.EventDone || last.FinishReason != "length" {
+		t.Fatalf("last event = %+v, want done with finish reason length", last)
+	}
+	if last.Usage == nil || *last.Usage != (chat.Usage{PromptTokens: 12, CompletionTokens: 3}) {
+		t.Errorf("usage = %+v, want 12 prompt and 3 completion tokens", last.Usage)
+	}
+	for _, ev := range events[:len(events)-1] {
+		if ev.Type == chat.EventDone {
+			t.Errorf("done event before the usage chunk: %+v", events)
+		}
+	}
+	if req := srv.Requests()[0]; req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+		t.Errorf("stream_options = %+v, want include_usage", req.StreamOptions)
+	}
+}
+
+func TestChatCompletionsStreamUpstreamErrors(t *testing.T) {
+	for _, status := range []int{429, 500} {
+		l, srv := newTestLogic(t, configs.Config{}, nil)
+		srv.Fail(openaitest.Fault{Status: status})
+		_, err := l.doStreamCompletion(context.Background(), testModel, []memory.PromptMessage{{Role: "user", Content: "hi"}}, generationOptions{})
+		if err == nil || !strings.Contains(err.Error(), strconv.Itoa(status)) {
+			t.Errorf("status %d: err = %v, want the status reported", status, err)
+		}
+	}
+}
+
+func TestChatCompletionsStreamMalformedChunk(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Queue(openaitest.Reply{Content: "abcdefghijkl", ChunkSize: 4})
+	srv.Fail(openaitest.Fault{Malformed: true, AfterChunks: 2})
+
+	events, err := drain(openTestStream(t, context.Background(), l))
+	if err == nil {
+		t.Fatalf("drain succeeded with events %+v, want an error", events)
+	}
+	if got := deltas(events, chat.EventTextDelta); got != "abcdefgh" {
+		t.Errorf("content before the bad chunk = %q, want %q", got, "abcdefgh")
+	}
+}
+
+func TestChatCompletionsStreamDisconnect(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Queue(openaitest.Reply{Content: "abcdefghijkl", ChunkSize: 4})
+	srv.Fail(openaitest.Fault{Disconnect: true, AfterChunks: 1})
+
+	events, err := drain(openTestStream(t, context.Background(), l))
+	if err == nil {
+		t.Fatalf("drain succeeded with events %+v, want an error", events)
+	}
+	for _, ev := range events {
+		if ev.Type == chat.EventDone {
+			t.Errorf("done event from a dropped stream: %+v", events)
+		}
+	}
+}
+
+func TestChatCompletionsStreamSlowUpstream(t *testing.T) {
+	l, srv := newTestLogic(t, configs.Config{}, nil)
+	srv.Fail(openaitest.Fault{ChunkDelay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := drain(openTestStream(t, ctx, l))
+	if err == nil {
+		t.Fatal("drain succeeded, want the deadline to end the stream")
+	}
+	if elapsed := time.Since(started); elapsed > 900*time.Millisecond {
+		t.Errorf("stream ended after %v, want it cut at the deadline", elapsed)
+	}
+}
@@ -0,0 +1,24 @@
+package openai
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+)
+
+type staticStream struct {
+	events []chat.StreamEvent
+}
+
+func newStaticStream(events ...chat.StreamEvent) chat.MessageStream {
+	return &staticStream{events: events}
+}
+
+func (s *staticStream) Next() (chat.StreamEvent, bool, error) {
+	if len(s.events) == 0 {
+		return chat.StreamEvent{Type: chat.EventDone}, true, nil
+	}
+	ev := s.events[0]
+	s.events = s.events[1:]
+	return ev, ev.Type == chat.EventDone, nil
+}
+
+func (s *staticStream) Close() error { return nil }
@@ -7,6 +7,7 @@ import (
 
 type streamContext struct {
 	conversationID string
+	citations      []chat.Citation
 	title          string
 	mu             sync.Mutex
 }
@@ -23,12 +24,12 @@ func (s *streamContext) getTitle() string {
 	return s.title
 }
 
-func (l *logicImpl) setStreamContext(conversationID string, stream chat.MessageStream) {
+func (l *logicImpl) setStreamContext(conversationID string, citations []chat.Citation, stream chat.MessageStream) {
 	ps, ok := stream.(*persistedStream)
 	if !ok {
 		return
 	}
-	ps.ctx = &streamContext{conversationID: conversationID}
+	ps.ctx = &streamContext{conversationID: conversationID, citations: citations}
 }
 
 func (l *logicImpl) setStreamTitle(stream chat.MessageStream, title string) {
@@ -0,0 +1,84 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	chatdao "github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/pkg/openaitest"
+
+	"gorm.io/gorm"
+)
+
+// fakeChatDao holds one conversation's messages for the memory manager;
+// anything else panics on the nil embedded interface.
+type fakeChatDao struct {
+	chatdao.Dao
+
+	messages []model.Message
+	created  []model.Message
+}
+
+func (d *fakeChatDao) GetLastSummary(string) (*model.Message, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (d *fakeChatDao) ListNonSummaryMessagesAfterSequence(string, int64) ([]model.Message, error) {
+	return d.messages, nil
+}
+
+func (d *fakeChatDao) ListParticipants(string) ([]model.ConversationParticipant, error) {
+	return nil, nil
+}
+
+func (d *fakeChatDao) CreateMessage(message model.Message, _ ...model.WebhookEvent) error {
+	d.created = append(d.created, message)
+	return nil
+}
+
+func (d *fakeChatDao) UpdateConversation(string, map[string]interface{}, ...model.WebhookEvent) error {
+	return nil
+}
+
+func TestBuildPromptSummarizesLongHistory(t *testing.T) {
+	dao := &fakeChatDao{}
+	for i, text := range []string{"one", "two", "three", "four", "five", "six", "seven"} {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		dao.messages = append(dao.messages, model.Message{ID: int64(i + 1), Sequence: int64(i + 1), Role: role, ContentType: memory.ContentTypeText, Content: text})
+	}
+	nextID := int64(100)
+	mem := memory.NewManager(dao, func() int64 { nextID++; return nextID }, func() string { return "uuid" })
+	l, srv := newTestLogic(t, configs.Config{}, mem)
+	srv.Queue(openaitest.Reply{Content: "They counted to six."})
+
+	latest := dao.messages[len(dao.messages)-1]
+	prompt, err := mem.BuildPrompt(context.Background(), "conv-1", latest, testModel, l.redactedSummarizer(l.piiSession("")))
+	if err != nil {
+		t.Fatalf("build prompt: %v", err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 || reqs[0].Stream {
+		t.Fatalf("requests = %+v, want one summary request", reqs)
+	}
+	var sent []string
+	for _, msg := range reqs[0].Messages {
+		sent = append(sent, msg.Text())
+	}
+	if joined := strings.Join(sent, "\n"); !strings.Contains(joined, "six") || strings.Contains(joined, "seven") {
+		t.Errorf("summary request = %q, want the history before the latest message", joined)
+	}
+	if len(prompt) != 2 || prompt[0].Role != "system" || prompt[0].Content != "They counted to six." || prompt[1].Content != "seven" {
+		t.Errorf("prompt = %+v, want the summary and the latest message", prompt)
+	}
+	if len(dao.created) != 1 || !dao.created[0].IsSummary || dao.created[0].SummaryFromID != 1 || dao.created[0].SummaryToID != 6 {
+		t.Errorf("saved = %+v, want a summary of messages 1 to 6", dao.created)
+	}
+}
@@ -0,0 +1,44 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+)
+
+func (l *logicImpl) ListTrash(ctx context.Context, req *chat.ListTrashReq, userID string) (*chat.ListConversationsResp, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	page, pageSize := normalizePaging(req.Page, req.PageSize)
+	offset := (page - 1) * pageSize
+
+	items, total, err := l.memory.ListTrashedConversations(ctx, userID, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	respItems, err := l.toConversationItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	return &chat.ListConversationsResp{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Items:    respItems,
+	}, nil
+}
+
+func (l *logicImpl) RestoreConversation(ctx context.Context, req *chat.RestoreConversationReq, userID string) error {
+	if req.ConversationID == "" {
+		return errors.New("missing conversation_id")
+	}
+	conversation, err := l.memory.GetTrashedConversation(ctx, req.ConversationID)
+	if err != nil {
+		return err
+	}
+	if userID != "" && conversation.UserID != userID {
+		return errors.New("forbidden")
+	}
+	return l.memory.RestoreConversation(ctx, *conversation)
+}
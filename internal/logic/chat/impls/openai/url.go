@@ -1,15 +1,21 @@
 package openai
 
 type urls struct {
-	BaseURL    string
-	ModelList  string
-	Completion string
+	BaseURL         string
+	ModelList       string
+	Completion      string
+	ImageGeneration string
+	Embeddings      string
+	Moderations     string
 }
 
 func newURLs(baseURL string) *urls {
 	return &urls{
-		BaseURL:    baseURL,
-		ModelList:  baseURL + "/models",
-		Completion: baseURL + "/chat/completions",
+		BaseURL:         baseURL,
+		ModelList:       baseURL + "/models",
+		Completion:      baseURL + "/chat/completions",
+		ImageGeneration: baseURL + "/images/generations",
+		Embeddings:      baseURL + "/embeddings",
+		Moderations:     baseURL + "/moderations",
 	}
 }
@@ -0,0 +1,251 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultImageMaxBytes       = 5 << 20
+	defaultImageMaxCount       = 4
+	defaultImagePromptMaxCount = 8
+)
+
+var defaultImageMimeTypes = []string{"image/png", "image/jpeg", "image/webp", "image/gif"}
+
+type contentPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *contentImage `json:"image_url,omitempty"`
+}
+
+type contentImage struct {
+	URL string `json:"url"`
+}
+
+type imagePolicy struct {
+	visionModels    []string
+	maxBytes        int64
+	maxCount        int
+	promptMaxImages int
+	mimeTypes       map[string]struct{}
+}
+
+func newImagePolicy(openaiConf configs.OpenAIConfig, conf configs.ImageInputConfig) *imagePolicy {
+	p := &imagePolicy{
+		visionModels: openaiConf.VisionModels,
+		maxBytes:     conf.MaxBytes,
+		maxCount:     conf.MaxCount,
+		mimeTypes:    make(map[string]struct{}),
+	}
+	if p.maxBytes <= 0 {
+		p.maxBytes = defaultImageMaxBytes
+	}
+	if p.maxCount <= 0 {
+		p.maxCount = defaultImageMaxCount
+	}
+	p.promptMaxImages = conf.PromptMaxImages
+	if p.promptMaxImages <= 0 {
+		p.promptMaxImages = defaultImagePromptMaxCount
+	}
+	// The latest turn always goes out whole.
+	p.promptMaxImages = max(p.promptMaxImages, p.maxCount)
+	mimeTypes := conf.MimeTypes
+	if len(mimeTypes) == 0 {
+		mimeTypes = defaultImageMimeTypes
+	}
+	for _, t := range mimeTypes {
+		p.mimeTypes[strings.ToLower(t)] = struct{}{}
+	}
+	return p
+}
+
+// supportsVision matches the model name against the configured prefixes so
+// dated variants such as gpt-4o-2024-08-06 are covered by "gpt-4o".
+func (p *imagePolicy) supportsVision(modelName string) bool {
+	modelName = strings.ToLower(modelName)
+	for _, prefix := range p.visionModels {
+		if prefix != "" && strings.HasPrefix(modelName, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *imagePolicy) buildImageParts(modelName string, images []chat.Image) ([]memory.ImagePart, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+	if !p.supportsVision(modelName) {
+		return nil, fmt.Errorf("model %s does not support image input", modelName)
+	}
+	if len(images) > p.maxCount {
+		return nil, fmt.Errorf("too many images: max %d", p.maxCount)
+	}
+	parts := make([]memory.ImagePart, 0, len(images))
+	for _, img := range images {
+		part, err := p.buildImagePart(img)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+func (p *imagePolicy) buildImagePart(img chat.Image) (memory.ImagePart, error) {
+	if len(img.Data) > 0 {
+		mimeType := strings.ToLower(img.MimeType)
+		if mimeType == "" {
+			mimeType = http.DetectContentType(img.Data)
+		}
+		if _, ok := p.mimeTypes[mimeType]; !ok {
+			return memory.ImagePart{}, fmt.Errorf("unsupported image type %s", mimeType)
+		}
+		if int64(len(img.Data)) > p.maxBytes {
+			return memory.ImagePart{}, fmt.Errorf("image too large: max %d bytes", p.maxBytes)
+		}
+		return memory.ImagePart{
+			MimeType: mimeType,
+			Size:     int64(len(img.Data)),
+			Data:     img.Data,
+		}, nil
+	}
+	u, err := url.Parse(strings.TrimSpace(img.URL))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return memory.ImagePart{}, errors.New("invalid image url")
+	}
+	mimeType := strings.ToLower(img.MimeType)
+	if mimeType != "" {
+		if _, ok := p.mimeTypes[mimeType]; !ok {
+			return memory.ImagePart{}, fmt.Errorf("unsupported image type %s", mimeType)
+		}
+	}
+	return memory.ImagePart{URL: u.String(), MimeType: mimeType}, nil
+}
+
+func toChatImages(parts []memory.ImagePart) []chat.Image {
+	if len(parts) == 0 {
+		return nil
+	}
+	images := make([]chat.Image, 0, len(parts))
+	for _, part := range parts {
+		images = append(images, chat.Image{URL: part.URL, MimeType: part.MimeType, BlobKey: part.BlobKey})
+	}
+	return images
+}
+
+// storeUploads writes uploaded images to the blob store, so the message row
+// keeps a reference instead of the bytes.
+func (l *logicImpl) storeUploads(ctx context.Context, conversationID string, images []memory.ImagePart) error {
+	for i, img := range images {
+		if len(img.Data) == 0 {
+			continue
+		}
+		part, err := l.putImage(ctx, "uploads/"+conversationID, img.Data, img.MimeType)
+		if err != nil {
+			return err
+		}
+		images[i] = part
+	}
+	return nil
+}
+
+// visionMessages builds the completion messages for the model. Only the
+// newest images within the prompt budget are sent; older turns get
+// captions. Stored images are inlined, since the provider cannot fetch
+// them from the blob store.
+func (l *logicImpl) visionMessages(ctx context.Context, modelName string, messages []memory.PromptMessage) ([]completionMessage, error) {
+	if !l.images.supportsVision(modelName) {
+		return l.images.toVisionMessages(modelName, messages), nil
+	}
+	out := make([]memory.PromptMessage, len(messages))
+	copy(out, messages)
+	budget := l.images.promptMaxImages
+	for i := len(out) - 1; i >= 0; i-- {
+		msg := out[i]
+		if len(msg.Images) == 0 || msg.Role != "user" {
+			continue
+		}
+		if len(msg.Images) > budget {
+			budget = 0
+			msg.Content = memory.ImageCaption(msg.Content, len(msg.Images))
+			msg.Images = nil
+		} else {
+			budget -= len(msg.Images)
+			images, err := l.inlineImages(ctx, msg.Images)
+			if err != nil {
+				return nil, err
+			}
+			if len(images) == 0 {
+				msg.Content = memory.ImageCaption(msg.Content, len(msg.Images))
+			}
+			msg.Images = images
+		}
+		out[i] = msg
+	}
+	return l.images.toVisionMessages(modelName, out), nil
+}
+
+// inlineImages turns stored images into data URLs. Images whose blob is
+// gone are left out.
+func (l *logicImpl) inlineImages(ctx context.Context, images []memory.ImagePart) ([]memory.ImagePart, error) {
+	out := make([]memory.ImagePart, 0, len(images))
+	for _, img := range images {
+		if img.BlobKey == "" {
+			out = append(out, img)
+			continue
+		}
+		r, err := l.svcCtx.Infra.Blob.Get(ctx, img.BlobKey)
+		if errors.Is(err, blob.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(r, maxGeneratedImageLen))
+		_ = r.Close()
+		if err != nil {
+			return nil, err
+		}
+		img.URL = "data:" + img.MimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+		out = append(out, img)
+	}
+	return out, nil
+}
+
+// toVisionMessages sends user images in the OpenAI content-array format when
+// the model can see them, and falls back to a text caption otherwise.
+func (p *imagePolicy) toVisionMessages(modelName string, messages []memory.PromptMessage) []completionMessage {
+	vision := p.supportsVision(modelName)
+	prompt := make([]completionMessage, 0, len(messages))
+	for _, msg := range messages {
+		if len(msg.Images) == 0 {
+			prompt = append(prompt, completionMessage{Role: msg.Role, Content: msg.Content})
+			continue
+		}
+		if !vision || msg.Role != "user" {
+			prompt = append(prompt, completionMessage{Role: msg.Role, Content: memory.ImageCaption(msg.Content, len(msg.Images))})
+			continue
+		}
+		parts := make([]contentPart, 0, len(msg.Images)+1)
+		if msg.Content != "" {
+			parts = append(parts, contentPart{Type: "text", Text: msg.Content})
+		}
+		for _, img := range msg.Images {
+			parts = append(parts, contentPart{Type: "image_url", ImageURL: &contentImage{URL: img.URL}})
+		}
+		prompt = append(prompt, completionMessage{Role: msg.Role, Content: parts})
+	}
+	return prompt
+}
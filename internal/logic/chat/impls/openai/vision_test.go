@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n fake image")
+
+func visionConf(promptMaxImages int) configs.Config {
+	var conf configs.Config
+	conf.LLMRequestConf.OpenAI.VisionModels = []string{testModel}
+	conf.ImageInputConf.MaxCount = 1
+	conf.ImageInputConf.PromptMaxImages = promptMaxImages
+	return conf
+}
+
+func TestStoreUploadsKeepsReferenceOnly(t *testing.T) {
+	l, _ := newTestLogic(t, visionConf(0), nil)
+	images := []memory.ImagePart{{MimeType: "image/png", Size: int64(len(testPNG)), Data: testPNG}}
+
+	if err := l.storeUploads(context.Background(), "conv-1", images); err != nil {
+		t.Fatalf("storeUploads: %v", err)
+	}
+	img := images[0]
+	if img.Data != nil || !strings.HasPrefix(img.BlobKey, "uploads/conv-1/") || img.MimeType != "image/png" {
+		t.Fatalf("image = %+v, want a blob reference", img)
+	}
+	row, err := json.Marshal(images)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(row), "data:") || strings.Contains(string(row), "PNG") {
+		t.Errorf("stored row carries the image bytes: %s", row)
+	}
+}
+
+func TestVisionMessagesInlinesWithinBudget(t *testing.T) {
+	l, _ := newTestLogic(t, visionConf(2), nil)
+	ctx := context.Background()
+	var messages []memory.PromptMessage
+	for _, text := range []string{"first", "second", "third"} {
+		images := []memory.ImagePart{{MimeType: "image/png", Data: testPNG}}
+		if err := l.storeUploads(ctx, "conv-1", images); err != nil {
+			t.Fatalf("storeUploads: %v", err)
+		}
+		messages = append(messages, memory.PromptMessage{Role: "user", Content: text, Images: images})
+	}
+
+	prompt, err := l.visionMessages(ctx, testModel, messages)
+	if err != nil {
+		t.Fatalf("visionMessages: %v", err)
+	}
+	if got, ok := prompt[0].Content.(string); !ok || !strings.Contains(got, "[image]") {
+		t.Errorf("oldest turn = %#v, want an image caption", prompt[0].Content)
+	}
+	for _, msg := range prompt[1:] {
+		parts, ok := msg.Content.([]contentPart)
+		if !ok || len(parts) != 2 || parts[1].ImageURL == nil {
+			t.Fatalf("turn = %#v, want text and an image", msg.Content)
+		}
+		if url := parts[1].ImageURL.URL; !strings.HasPrefix(url, "data:image/png;base64,") {
+			t.Errorf("image url = %q, want the stored bytes inlined", url)
+		}
+	}
+	if messages[1].Images[0].URL == prompt[1].Content.([]contentPart)[1].ImageURL.URL {
+		t.Error("inlining modified the stored message")
+	}
+}
+
+func TestVisionMessagesMissingBlob(t *testing.T) {
+	l, _ := newTestLogic(t, visionConf(0), nil)
+	messages := []memory.PromptMessage{{
+		Role:    "user",
+		Content: "look",
+		Images:  []memory.ImagePart{{MimeType: "image/png", BlobKey: "uploads/conv-1/gone.png"}},
+	}}
+
+	prompt, err := l.visionMessages(context.Background(), testModel, messages)
+	if err != nil {
+		t.Fatalf("visionMessages: %v", err)
+	}
+	if got, ok := prompt[0].Content.(string); !ok || !strings.Contains(got, "[image]") {
+		t.Errorf("turn = %#v, want an image caption", prompt[0].Content)
+	}
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+func (m *manager) checkAttachments(msg MessageInput) error {
+	if len(msg.AttachmentIDs) == 0 {
+		return nil
+	}
+	if m.attachments == nil {
+		return errors.New("attachments disabled")
+	}
+	items, err := m.attachments.ListAttachmentsByIDs(msg.AttachmentIDs)
+	if err != nil {
+		return err
+	}
+	if len(items) != len(msg.AttachmentIDs) {
+		return errors.New("attachment not found")
+	}
+	for _, item := range items {
+		if msg.UserID != "" && item.UserID != msg.UserID {
+			return errors.New("forbidden")
+		}
+		if item.MessageID != 0 {
+			return fmt.Errorf("attachment %s already used", item.UUID)
+		}
+		if item.Status != model.AttachmentStatusReady {
+			return fmt.Errorf("attachment %s not readable: %s", item.UUID, item.Status)
+		}
+	}
+	return nil
+}
+
+func (m *manager) loadAttachments(messages ...model.Message) map[int64][]model.Attachment {
+	if m.attachments == nil {
+		return nil
+	}
+	ids := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == "user" {
+			ids = append(ids, msg.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	items, err := m.attachments.ListAttachmentsByMessages(ids)
+	if err != nil || len(items) == 0 {
+		return nil
+	}
+	out := make(map[int64][]model.Attachment, len(items))
+	for _, item := range items {
+		out[item.MessageID] = append(out[item.MessageID], item)
+	}
+	return out
+}
+
+// withAttachmentText appends the extracted text of each attachment after the
+// user's message, sharing the token budget evenly between attachments.
+func (m *manager) withAttachmentText(content string, items []model.Attachment) string {
+	if len(items) == 0 {
+		return content
+	}
+	budget := m.attachmentTokenBudget / len(items)
+	var b strings.Builder
+	b.WriteString(content)
+	for _, item := range items {
+		if strings.TrimSpace(item.Text) == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "<attachment name=%q type=%q>\n", item.FileName, item.MimeType)
+		b.WriteString(TruncateToTokens(item.Text, budget))
+		b.WriteString("\n</attachment>")
+	}
+	return b.String()
+}
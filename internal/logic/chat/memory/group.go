@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxDisplayNameLen = 64
+
+const groupNote = "This is a group conversation with several people. Each user message starts with the name of its speaker followed by a colon. Keep track of who said what and address people by name when it helps."
+
+type ParticipantInput struct {
+	UserID      string
+	DisplayName string
+}
+
+// CreateGroupConversation creates a group owned by ownerID. The owner is
+// added as a participant even when members does not list them.
+func (m *manager) CreateGroupConversation(ctx context.Context, ownerID, botID, title string, members []ParticipantInput) (*model.Conversation, error) {
+	if ownerID == "" {
+		return nil, errors.New("missing user")
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = "New"
+	}
+	conversation := model.Conversation{
+		UUID:   m.newUUID(),
+		UserID: ownerID,
+		BotID:  botID,
+		Kind:   model.ConversationKindGroup,
+		Title:  title,
+	}
+	participants := []model.ConversationParticipant{{
+		ID:             m.newID(),
+		ConversationID: conversation.UUID,
+		UserID:         ownerID,
+		Role:           model.ParticipantRoleOwner,
+	}}
+	for _, p := range m.toParticipants(conversation.UUID, members) {
+		if p.UserID == ownerID {
+			participants[0].DisplayName = p.DisplayName
+			continue
+		}
+		participants = append(participants, p)
+	}
+	if err := m.dao.CreateGroupConversation(conversation, participants, m.outbox(model.WebhookConversationCreated, conversation.UUID, conversationCreated(conversation))...); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (m *manager) GetParticipant(ctx context.Context, conversationID, userID string) (*model.ConversationParticipant, error) {
+	return m.dao.GetParticipant(conversationID, userID)
+}
+
+func (m *manager) ListParticipants(ctx context.Context, conversationID string) ([]model.ConversationParticipant, error) {
+	return m.dao.ListParticipants(conversationID)
+}
+
+func (m *manager) CountParticipants(ctx context.Context, conversationID string) (int64, error) {
+	return m.dao.CountParticipants(conversationID)
+}
+
+func (m *manager) AddParticipants(ctx context.Context, conversationID string, members []ParticipantInput) error {
+	return m.dao.AddParticipants(m.toParticipants(conversationID, members))
+}
+
+func (m *manager) SetParticipantRole(ctx context.Context, conversationID, userID, role string) error {
+	if role != model.ParticipantRoleOwner && role != model.ParticipantRoleMember {
+		return errors.New("invalid role")
+	}
+	return m.dao.UpdateParticipant(conversationID, userID, map[string]interface{}{"role": role})
+}
+
+func (m *manager) RemoveParticipant(ctx context.Context, conversationID, userID string) error {
+	return m.dao.RemoveParticipant(conversationID, userID)
+}
+
+// toParticipants builds member rows, dropping blank and duplicate users.
+func (m *manager) toParticipants(conversationID string, members []ParticipantInput) []model.ConversationParticipant {
+	seen := make(map[string]bool, len(members))
+	out := make([]model.ConversationParticipant, 0, len(members))
+	for _, member := range members {
+		userID := strings.TrimSpace(member.UserID)
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		out = append(out, model.ConversationParticipant{
+			ID:             m.newID(),
+			ConversationID: conversationID,
+			UserID:         userID,
+			Role:           model.ParticipantRoleMember,
+			DisplayName:    normalizeDisplayName(member.DisplayName),
+		})
+	}
+	return out
+}
+
+func normalizeDisplayName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if utf8.RuneCountInString(name) > maxDisplayNameLen {
+		name = string([]rune(name)[:maxDisplayNameLen])
+	}
+	return name
+}
+
+// speakerNames maps the members of a group conversation to the names the
+// model sees. It is nil for direct conversations, which need no labels.
+func (m *manager) speakerNames(conversationID string) (map[string]string, error) {
+	participants, err := m.dao.ListParticipants(conversationID)
+	if err != nil || len(participants) == 0 {
+		return nil, err
+	}
+	names := make(map[string]string, len(participants))
+	for _, p := range participants {
+		names[p.UserID] = p.DisplayName
+	}
+	return names, nil
+}
+
+// labelSpeaker prefixes user messages of group conversations with the name
+// of their author. Former members and unnamed members fall back to their
+// user ID.
+func labelSpeaker(msg model.Message, content string, speakers map[string]string) string {
+	if speakers == nil || msg.Role != "user" {
+		return content
+	}
+	name := speakers[msg.UserID]
+	if name == "" {
+		name = msg.UserID
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return name + ": " + content
+}
+
+func withGroupNote(prompt []PromptMessage, speakers map[string]string) []PromptMessage {
+	if speakers == nil {
+		return prompt
+	}
+	return append([]PromptMessage{{Role: "system", Content: groupNote}}, prompt...)
+}
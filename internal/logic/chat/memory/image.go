@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+)
+
+const (
+	ContentTypeText  = "text"
+	ContentTypeImage = "image"
+)
+
+// ImagePart is an image of a message. Uploaded bytes are kept in Data only
+// until they are written to the blob store; rows keep the BlobKey.
+type ImagePart struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	BlobKey  string `json:"blob_key,omitempty"`
+	Data     []byte `json:"-"`
+}
+
+func encodeImages(images []ImagePart) (*string, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(images)
+	if err != nil {
+		return nil, err
+	}
+	out := string(b)
+	return &out, nil
+}
+
+func DecodeImages(msg model.Message) []ImagePart {
+	if msg.Images == nil || *msg.Images == "" {
+		return nil
+	}
+	var images []ImagePart
+	if err := json.Unmarshal([]byte(*msg.Images), &images); err != nil {
+		return nil
+	}
+	return images
+}
+
+// SignImages points stored images at fresh signed links, for callers already
+// allowed to see them; images without a blob keep their URL.
+func SignImages(images []ImagePart, signer *blob.URLSigner) []ImagePart {
+	for i := range images {
+		if images[i].BlobKey != "" {
+			images[i].URL = signer.URL(images[i].BlobKey)
+		}
+	}
+	return images
+}
+
+// ImageCaption renders an image message as plain text for models, summaries
+// and titles that cannot see the image itself.
+func ImageCaption(content string, count int) string {
+	if count <= 0 {
+		count = 1
+	}
+	label := "[image]"
+	if count > 1 {
+		label = "[" + strings.Repeat("image, ", count-1) + "image]"
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return label
+	}
+	return label + " " + content
+}
+
+func promptable(msg model.Message) bool {
+	return msg.ContentType == ContentTypeText || msg.ContentType == ContentTypeImage
+}
+
+func captionOf(msg model.Message) string {
+	if msg.ContentType != ContentTypeImage {
+		return msg.Content
+	}
+	return ImageCaption(msg.Content, len(DecodeImages(msg)))
+}
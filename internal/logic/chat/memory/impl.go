@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/attachment"
 	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
 	"github.com/im-core-go/im-core-bot-platform/internal/model"
 	"strings"
@@ -12,52 +13,92 @@ import (
 )
 
 const (
-	defaultSummaryThreshold    = 5
-	defaultSummaryIncludeLimit = 3
+	defaultSummaryThreshold      = 5
+	defaultSummaryIncludeLimit   = 3
+	defaultAttachmentTokenBudget = 8000
 )
 
 type manager struct {
-	dao                 chat.Dao
-	newID               func() int64
-	newUUID             func() string
-	summaryThreshold    int
-	summaryIncludeLimit int
+	dao                   chat.Dao
+	newID                 func() int64
+	newUUID               func() string
+	summaryThreshold      int
+	summaryIncludeLimit   int
+	includeReasoning      bool
+	attachments           attachment.Dao
+	attachmentTokenBudget int
+	outboxEnabled         bool
 }
 
-func NewManager(dao chat.Dao, newID func() int64, newUUID func() string) Manager {
-	return &manager{
-		dao:                 dao,
-		newID:               newID,
-		newUUID:             newUUID,
-		summaryThreshold:    defaultSummaryThreshold,
-		summaryIncludeLimit: defaultSummaryIncludeLimit,
+type Option func(m *manager)
+
+// WithReasoningInPrompt replays stored reasoning traces of assistant messages
+// into later prompts. Traces are left out by default.
+func WithReasoningInPrompt(include bool) Option {
+	return func(m *manager) {
+		m.includeReasoning = include
+	}
+}
+
+// WithAttachments links uploaded attachments to user messages and inlines
+// their extracted text into prompts, truncated to tokenBudget per message.
+func WithAttachments(dao attachment.Dao, tokenBudget int) Option {
+	return func(m *manager) {
+		m.attachments = dao
+		if tokenBudget > 0 {
+			m.attachmentTokenBudget = tokenBudget
+		}
 	}
 }
 
-func (m *manager) EnsureConversation(ctx context.Context, userID, conversationID string) (string, error) {
+func NewManager(dao chat.Dao, newID func() int64, newUUID func() string, opts ...Option) Manager {
+	m := &manager{
+		dao:                   dao,
+		newID:                 newID,
+		newUUID:               newUUID,
+		summaryThreshold:      defaultSummaryThreshold,
+		summaryIncludeLimit:   defaultSummaryIncludeLimit,
+		attachmentTokenBudget: defaultAttachmentTokenBudget,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *manager) EnsureConversation(ctx context.Context, userID, conversationID, botID string) (*model.Conversation, error) {
 	if conversationID == "" {
 		if userID == "" {
-			return "", errors.New("missing user")
+			return nil, errors.New("missing user")
 		}
-		conversationID = m.newUUID()
 		conversation := model.Conversation{
-			UUID:   conversationID,
+			UUID:   m.newUUID(),
 			UserID: userID,
+			BotID:  botID,
+			Kind:   model.ConversationKindDirect,
 			Title:  "New",
 		}
-		if err := m.dao.CreateConversation(conversation); err != nil {
-			return "", err
+		if err := m.dao.CreateConversation(conversation, m.outbox(model.WebhookConversationCreated, conversation.UUID, conversationCreated(conversation))...); err != nil {
+			return nil, err
 		}
-		return conversationID, nil
+		return &conversation, nil
 	}
 	conversation, err := m.dao.GetConversationByID(conversationID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if userID != "" && conversation.UserID != userID {
-		return "", errors.New("forbidden")
+		if conversation.Kind != model.ConversationKindGroup {
+			return nil, errors.New("forbidden")
+		}
+		if _, err := m.dao.GetParticipant(conversation.UUID, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("forbidden")
+			}
+			return nil, err
+		}
 	}
-	return conversationID, nil
+	return conversation, nil
 }
 
 func (m *manager) SaveUserMessage(ctx context.Context, conversationID string, msg MessageInput) (model.Message, error) {
@@ -67,53 +108,103 @@ func (m *manager) SaveUserMessage(ctx context.Context, conversationID string, ms
 	}
 	contentType := msg.ContentType
 	if contentType == "" {
-		contentType = "text"
+		contentType = ContentTypeText
+	}
+	if len(msg.Images) > 0 {
+		contentType = ContentTypeImage
 	}
 	content := strings.TrimSpace(msg.Content)
-	if content == "" {
+	if content == "" && len(msg.Images) == 0 && len(msg.AttachmentIDs) == 0 {
 		return model.Message{}, errors.New("empty message")
 	}
+	if err := m.checkAttachments(msg); err != nil {
+		return model.Message{}, err
+	}
+	images, err := encodeImages(msg.Images)
+	if err != nil {
+		return model.Message{}, err
+	}
 	var meta *string
 	if strings.TrimSpace(msg.Meta) != "" {
 		meta = &msg.Meta
 	}
+	var moderation *string
+	if msg.Moderation != "" {
+		moderation = &msg.Moderation
+	}
 	id := m.newID()
 	entity := model.Message{
 		ID:             id,
 		Sequence:       id,
 		ConversationID: conversationID,
+		UserID:         msg.UserID,
 		Role:           role,
 		ContentType:    contentType,
 		Content:        content,
+		Images:         images,
 		Meta:           meta,
+		Moderation:     moderation,
 		IsSummary:      false,
 	}
 	if err := m.dao.CreateMessage(entity); err != nil {
 		return model.Message{}, err
 	}
+	if len(msg.AttachmentIDs) > 0 {
+		if err := m.attachments.LinkAttachments(msg.AttachmentIDs, conversationID, entity.ID); err != nil {
+			return model.Message{}, err
+		}
+	}
 	m.touchConversation(conversationID)
 	return entity, nil
 }
 
-func (m *manager) SaveAssistantMessage(ctx context.Context, conversationID, content string) error {
-	trimmed := strings.TrimSpace(content)
-	if trimmed == "" {
-		return nil
+func (m *manager) SaveAssistantMessage(ctx context.Context, conversationID string, msg AssistantMessageInput) (model.Message, error) {
+	trimmed := strings.TrimSpace(msg.Content)
+	if trimmed == "" && len(msg.Images) == 0 {
+		return model.Message{}, nil
+	}
+	contentType := msg.ContentType
+	if contentType == "" {
+		contentType = ContentTypeText
+	}
+	images, err := encodeImages(msg.Images)
+	if err != nil {
+		return model.Message{}, err
+	}
+	var meta *string
+	if strings.TrimSpace(msg.Meta) != "" {
+		meta = &msg.Meta
+	}
+	var moderation *string
+	if msg.Moderation != "" {
+		moderation = &msg.Moderation
 	}
 	id := m.newID()
 	entity := model.Message{
-		ID:             id,
-		Sequence:       id,
-		ConversationID: conversationID,
-		Role:           "assistant",
-		ContentType:    "text",
-		Content:        trimmed,
-	}
-	if err := m.dao.CreateMessage(entity); err != nil {
-		return err
+		ID:               id,
+		Sequence:         id,
+		ConversationID:   conversationID,
+		Role:             "assistant",
+		ContentType:      contentType,
+		Content:          trimmed,
+		Reasoning:        strings.TrimSpace(msg.Reasoning),
+		Images:           images,
+		Meta:             meta,
+		Moderation:       moderation,
+		Model:            msg.Model,
+		PromptVersion:    msg.PromptVersion,
+		Experiment:       msg.Experiment,
+		Variant:          msg.Variant,
+		PromptTokens:     msg.PromptTokens,
+		CompletionTokens: msg.CompletionTokens,
+		LatencyMs:        msg.LatencyMs,
+		FirstTokenMs:     msg.FirstTokenMs,
+	}
+	if err := m.dao.CreateMessage(entity, m.outbox(model.WebhookMessageCompleted, conversationID, messageCompleted(entity))...); err != nil {
+		return model.Message{}, err
 	}
 	m.touchConversation(conversationID)
-	return nil
+	return entity, nil
 }
 
 func (m *manager) SaveSummaryMessage(ctx context.Context, conversationID, content string, fromID, toID int64) error {
@@ -155,24 +246,28 @@ func (m *manager) BuildPrompt(ctx context.Context, conversationID string, latest
 	if err != nil {
 		return nil, err
 	}
+	speakers, err := m.speakerNames(conversationID)
+	if err != nil {
+		return nil, err
+	}
 	if len(messages) == 0 {
-		return []PromptMessage{{Role: latest.Role, Content: latest.Content}}, nil
+		return withGroupNote([]PromptMessage{m.toPromptMessage(latest, m.loadAttachments(latest), speakers)}, speakers), nil
 	}
 
 	if len(messages) > m.summaryThreshold {
 		summarySource := messages[:len(messages)-1]
 		lastMsg := messages[len(messages)-1]
-		summaryPrompt := buildSummaryPrompt(summarySource)
+		summaryPrompt := buildSummaryPrompt(summarySource, speakers)
 		if len(summaryPrompt) > 0 {
 			summaryText, sumErr := summarize(ctx, modelName, summaryPrompt)
 			if sumErr == nil && strings.TrimSpace(summaryText) != "" {
 				if err := m.SaveSummaryMessage(ctx, conversationID, summaryText, summarySource[0].ID, summarySource[len(summarySource)-1].ID); err != nil {
 					return nil, err
 				}
-				return []PromptMessage{
+				return withGroupNote([]PromptMessage{
 					{Role: "system", Content: summaryText},
-					{Role: lastMsg.Role, Content: lastMsg.Content},
-				}, nil
+					m.toPromptMessage(lastMsg, m.loadAttachments(lastMsg), speakers),
+				}, speakers), nil
 			}
 		}
 	}
@@ -182,17 +277,33 @@ func (m *manager) BuildPrompt(ctx context.Context, conversationID string, latest
 		return nil, err
 	}
 
+	attachments := m.loadAttachments(messages...)
 	prompt := make([]PromptMessage, 0, len(summaries)+len(messages))
 	for i := len(summaries) - 1; i >= 0; i-- {
 		prompt = append(prompt, PromptMessage{Role: "system", Content: summaries[i].Content})
 	}
 	for _, msg := range messages {
-		if msg.ContentType != "text" {
+		if !promptable(msg) {
 			continue
 		}
-		prompt = append(prompt, PromptMessage{Role: msg.Role, Content: msg.Content})
+		prompt = append(prompt, m.toPromptMessage(msg, attachments, speakers))
+	}
+	return withGroupNote(prompt, speakers), nil
+}
+
+func (m *manager) toPromptMessage(msg model.Message, attachments map[int64][]model.Attachment, speakers map[string]string) PromptMessage {
+	return PromptMessage{
+		Role:    msg.Role,
+		Content: labelSpeaker(msg, m.withAttachmentText(m.promptContent(msg), attachments[msg.ID]), speakers),
+		Images:  DecodeImages(msg),
 	}
-	return prompt, nil
+}
+
+func (m *manager) promptContent(msg model.Message) string {
+	if !m.includeReasoning || msg.Reasoning == "" {
+		return msg.Content
+	}
+	return "<think>\n" + msg.Reasoning + "\n</think>\n\n" + msg.Content
 }
 
 func (m *manager) BuildTitleMessages(ctx context.Context, conversationID string, limit int) ([]PromptMessage, error) {
@@ -205,17 +316,34 @@ func (m *manager) BuildTitleMessages(ctx context.Context, conversationID string,
 	}
 	prompt := make([]PromptMessage, 0, len(messages))
 	for i := len(messages) - 1; i >= 0; i-- {
-		if messages[i].ContentType != "text" {
+		if !promptable(messages[i]) {
 			continue
 		}
 		prompt = append(prompt, PromptMessage{
 			Role:    messages[i].Role,
-			Content: messages[i].Content,
+			Content: captionOf(messages[i]),
 		})
 	}
 	return prompt, nil
 }
 
+// BuildSummaryMessages prompts for a summary of the latest limit messages,
+// with speakers labelled as in BuildPrompt.
+func (m *manager) BuildSummaryMessages(ctx context.Context, conversationID string, limit int) ([]PromptMessage, error) {
+	messages, err := m.dao.ListRecentNonSummaryMessages(conversationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	speakers, err := m.speakerNames(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return buildSummaryPrompt(messages, speakers), nil
+}
+
 func (m *manager) GetConversation(ctx context.Context, conversationID string) (*model.Conversation, error) {
 	return m.dao.GetConversationByID(conversationID)
 }
@@ -230,29 +358,64 @@ func (m *manager) UpdateConversationTitle(ctx context.Context, conversationID, t
 	})
 }
 
-func (m *manager) ListConversations(ctx context.Context, userID string, offset, limit int) ([]model.Conversation, int64, error) {
-	return m.dao.ListConversationsByUser(userID, offset, limit)
+func (m *manager) ListConversations(ctx context.Context, userID string, filter chat.ConversationFilter, offset, limit int) ([]model.Conversation, int64, error) {
+	return m.dao.ListConversationsByUser(userID, filter, offset, limit)
+}
+
+func (m *manager) ListConversationsByKey(ctx context.Context, userID string, filter chat.ConversationFilter, key chat.ConversationKey, before bool, limit int) ([]model.Conversation, error) {
+	return m.dao.ListConversationsByKey(userID, filter, key, before, limit)
+}
+
+func (m *manager) CountConversations(ctx context.Context, userID string, filter chat.ConversationFilter) (int64, error) {
+	return m.dao.CountConversationsByUser(userID, filter)
 }
 
 func (m *manager) ListMessages(ctx context.Context, conversationID string, offset, limit int) ([]model.Message, int64, error) {
 	return m.dao.ListMessagesByConversation(conversationID, offset, limit)
 }
 
+func (m *manager) ListMessagesBySequence(ctx context.Context, conversationID string, sequence int64, before bool, limit int) ([]model.Message, error) {
+	return m.dao.ListMessagesBySequence(conversationID, sequence, before, limit)
+}
+
+func (m *manager) CountMessages(ctx context.Context, conversationID string) (int64, error) {
+	return m.dao.CountMessagesByConversation(conversationID)
+}
+
 func (m *manager) DeleteConversation(ctx context.Context, conversationID string) error {
 	if conversationID == "" {
 		return errors.New("missing conversation_id")
 	}
-	return m.dao.DeleteConversation(conversationID)
+	return m.dao.DeleteConversation(conversationID, m.outbox(model.WebhookConversationDeleted, conversationID, conversationEvent{
+		ConversationID: conversationID,
+	})...)
+}
+
+func (m *manager) GetTrashedConversation(ctx context.Context, conversationID string) (*model.Conversation, error) {
+	return m.dao.GetTrashedConversationByID(conversationID)
+}
+
+func (m *manager) ListTrashedConversations(ctx context.Context, userID string, offset, limit int) ([]model.Conversation, int64, error) {
+	return m.dao.ListTrashedConversationsByUser(userID, offset, limit)
+}
+
+func (m *manager) RestoreConversation(ctx context.Context, conversation model.Conversation) error {
+	return m.dao.RestoreConversation(conversation)
 }
 
 func (m *manager) ClearMessages(ctx context.Context, conversationID string) error {
 	if conversationID == "" {
 		return errors.New("missing conversation_id")
 	}
+	if m.attachments != nil {
+		if err := m.attachments.DeleteAttachmentsByConversation(conversationID); err != nil {
+			return err
+		}
+	}
 	return m.dao.DeleteMessagesByConversation(conversationID)
 }
 
-func buildSummaryPrompt(messages []model.Message) []PromptMessage {
+func buildSummaryPrompt(messages []model.Message, speakers map[string]string) []PromptMessage {
 	prompt := []PromptMessage{
 		{
 			Role:    "system",
@@ -260,10 +423,10 @@ func buildSummaryPrompt(messages []model.Message) []PromptMessage {
 		},
 	}
 	for _, msg := range messages {
-		if msg.ContentType != "text" {
+		if !promptable(msg) {
 			continue
 		}
-		prompt = append(prompt, PromptMessage{Role: msg.Role, Content: msg.Content})
+		prompt = append(prompt, PromptMessage{Role: msg.Role, Content: labelSpeaker(msg, captionOf(msg), speakers)})
 	}
 	if len(prompt) <= 1 {
 		return nil
@@ -2,34 +2,90 @@ package memory
 
 import (
 	"context"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
 	"github.com/im-core-go/im-core-bot-platform/internal/model"
 )
 
 type PromptMessage struct {
 	Role    string
 	Content string
+	Images  []ImagePart
 }
 
 type Summarizer func(ctx context.Context, modelName string, messages []PromptMessage) (string, error)
 
 type MessageInput struct {
-	Role        string
+	Role          string
+	ContentType   string
+	Content       string
+	Images        []ImagePart
+	AttachmentIDs []string
+	UserID        string
+	Meta          string
+	Moderation    string
+}
+
+type AssistantMessageInput struct {
 	ContentType string
 	Content     string
+	Reasoning   string
+	Images      []ImagePart
 	Meta        string
+	Moderation  string
+	// Model and PromptVersion record what produced the reply.
+	Model            string
+	PromptVersion    string
+	Experiment       string
+	Variant          string
+	PromptTokens     int
+	CompletionTokens int
+	LatencyMs        int64
+	FirstTokenMs     int64
 }
 
 type Manager interface {
-	EnsureConversation(ctx context.Context, userID, conversationID string) (string, error)
+	EnsureConversation(ctx context.Context, userID, conversationID, botID string) (*model.Conversation, error)
 	SaveUserMessage(ctx context.Context, conversationID string, msg MessageInput) (model.Message, error)
-	SaveAssistantMessage(ctx context.Context, conversationID, content string) error
+	SaveAssistantMessage(ctx context.Context, conversationID string, msg AssistantMessageInput) (model.Message, error)
 	SaveSummaryMessage(ctx context.Context, conversationID, content string, fromID, toID int64) error
+	SaveReminderMessage(ctx context.Context, reminder model.Reminder) (model.Message, error)
 	BuildPrompt(ctx context.Context, conversationID string, latest model.Message, modelName string, summarize Summarizer) ([]PromptMessage, error)
 	BuildTitleMessages(ctx context.Context, conversationID string, limit int) ([]PromptMessage, error)
 	GetConversation(ctx context.Context, conversationID string) (*model.Conversation, error)
 	UpdateConversationTitle(ctx context.Context, conversationID, title string) error
-	ListConversations(ctx context.Context, userID string, offset, limit int) ([]model.Conversation, int64, error)
+	UpdateGeneratedTitle(ctx context.Context, conversationID, title string) error
+	SetConversationModel(ctx context.Context, conversationID, modelName string) error
+	BuildSummaryMessages(ctx context.Context, conversationID string, limit int) ([]PromptMessage, error)
+	ListConversations(ctx context.Context, userID string, filter chat.ConversationFilter, offset, limit int) ([]model.Conversation, int64, error)
+	ListConversationsByKey(ctx context.Context, userID string, filter chat.ConversationFilter, key chat.ConversationKey, before bool, limit int) ([]model.Conversation, error)
+	CountConversations(ctx context.Context, userID string, filter chat.ConversationFilter) (int64, error)
 	ListMessages(ctx context.Context, conversationID string, offset, limit int) ([]model.Message, int64, error)
+	ListMessagesBySequence(ctx context.Context, conversationID string, sequence int64, before bool, limit int) ([]model.Message, error)
+	CountMessages(ctx context.Context, conversationID string) (int64, error)
 	DeleteConversation(ctx context.Context, conversationID string) error
+	GetTrashedConversation(ctx context.Context, conversationID string) (*model.Conversation, error)
+	ListTrashedConversations(ctx context.Context, userID string, offset, limit int) ([]model.Conversation, int64, error)
+	RestoreConversation(ctx context.Context, conversation model.Conversation) error
 	ClearMessages(ctx context.Context, conversationID string) error
+
+	PinConversation(ctx context.Context, conversationID string, pinned bool) error
+	ArchiveConversation(ctx context.Context, conversationID string, archived bool) error
+	MoveConversation(ctx context.Context, conversationID, folderID string) error
+	SetConversationTags(ctx context.Context, conversation *model.Conversation, tags []string) ([]string, error)
+	ListConversationTags(ctx context.Context, conversationIDs []string) (map[string][]string, error)
+	ListTags(ctx context.Context, userID string) ([]chat.TagCount, error)
+
+	CreateGroupConversation(ctx context.Context, ownerID, botID, title string, members []ParticipantInput) (*model.Conversation, error)
+	GetParticipant(ctx context.Context, conversationID, userID string) (*model.ConversationParticipant, error)
+	ListParticipants(ctx context.Context, conversationID string) ([]model.ConversationParticipant, error)
+	CountParticipants(ctx context.Context, conversationID string) (int64, error)
+	AddParticipants(ctx context.Context, conversationID string, members []ParticipantInput) error
+	SetParticipantRole(ctx context.Context, conversationID, userID, role string) error
+	RemoveParticipant(ctx context.Context, conversationID, userID string) error
+
+	CreateFolder(ctx context.Context, userID, name string) (*model.Folder, error)
+	GetFolder(ctx context.Context, folderID string) (*model.Folder, error)
+	ListFolders(ctx context.Context, userID string) ([]model.Folder, error)
+	RenameFolder(ctx context.Context, folderID, name string) error
+	DeleteFolder(ctx context.Context, folderID string) error
 }
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxTags          = 20
+	maxTagChars      = 32
+	maxFolderNameLen = 64
+)
+
+func (m *manager) PinConversation(ctx context.Context, conversationID string, pinned bool) error {
+	var pinnedAt int64
+	if pinned {
+		pinnedAt = time.Now().Unix()
+	}
+	return m.dao.UpdateConversationColumns(conversationID, map[string]interface{}{
+		"pinned":    pinned,
+		"pinned_at": pinnedAt,
+	})
+}
+
+// SetConversationModel pins the model of a conversation; an empty name
+// goes back to the model each request asks for.
+func (m *manager) SetConversationModel(ctx context.Context, conversationID, modelName string) error {
+	return m.dao.UpdateConversationColumns(conversationID, map[string]interface{}{
+		"model": modelName,
+	})
+}
+
+func (m *manager) ArchiveConversation(ctx context.Context, conversationID string, archived bool) error {
+	return m.dao.UpdateConversationColumns(conversationID, map[string]interface{}{
+		"archived": archived,
+	})
+}
+
+func (m *manager) MoveConversation(ctx context.Context, conversationID, folderID string) error {
+	return m.dao.UpdateConversationColumns(conversationID, map[string]interface{}{
+		"folder_id": folderID,
+	})
+}
+
+// SetConversationTags replaces the conversation's tags with the normalized
+// set and returns it.
+func (m *manager) SetConversationTags(ctx context.Context, conversation *model.Conversation, tags []string) ([]string, error) {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.dao.ReplaceConversationTags(conversation.UUID, conversation.UserID, normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func (m *manager) ListConversationTags(ctx context.Context, conversationIDs []string) (map[string][]string, error) {
+	rows, err := m.dao.ListTagsByConversations(conversationIDs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]string)
+	for _, row := range rows {
+		out[row.ConversationID] = append(out[row.ConversationID], row.Tag)
+	}
+	return out, nil
+}
+
+func (m *manager) ListTags(ctx context.Context, userID string) ([]chat.TagCount, error) {
+	return m.dao.ListTagCountsByUser(userID)
+}
+
+func (m *manager) CreateFolder(ctx context.Context, userID, name string) (*model.Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	folder := model.Folder{
+		UUID:   m.newUUID(),
+		UserID: userID,
+		Name:   name,
+	}
+	if err := m.dao.CreateFolder(folder); err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (m *manager) GetFolder(ctx context.Context, folderID string) (*model.Folder, error) {
+	return m.dao.GetFolderByID(folderID)
+}
+
+func (m *manager) ListFolders(ctx context.Context, userID string) ([]model.Folder, error) {
+	return m.dao.ListFoldersByUser(userID)
+}
+
+func (m *manager) RenameFolder(ctx context.Context, folderID, name string) error {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return err
+	}
+	return m.dao.UpdateFolder(folderID, map[string]interface{}{
+		"name": name,
+	})
+}
+
+func (m *manager) DeleteFolder(ctx context.Context, folderID string) error {
+	if folderID == "" {
+		return errors.New("missing folder_id")
+	}
+	return m.dao.DeleteFolder(folderID)
+}
+
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("empty folder name")
+	}
+	if utf8.RuneCountInString(name) > maxFolderNameLen {
+		return "", fmt.Errorf("folder name too long: max %d characters", maxFolderNameLen)
+	}
+	return name, nil
+}
+
+// normalizeTags trims and lowercases tags and drops duplicates, so "Work"
+// and "work " are the same tag.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagChars {
+			return nil, fmt.Errorf("tag too long: max %d characters", maxTagChars)
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	if len(out) > maxTags {
+		return nil, fmt.Errorf("too many tags: max %d", maxTags)
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"strings"
+	"time"
+)
+
+// WithWebhookOutbox records conversation and message lifecycle events in
+// the webhook outbox, in the transaction of the change they describe.
+func WithWebhookOutbox(enabled bool) Option {
+	return func(m *manager) {
+		m.outboxEnabled = enabled
+	}
+}
+
+type conversationEvent struct {
+	ConversationID string `json:"conversation_id"`
+	UserID         string `json:"user_id,omitempty"`
+	BotID          string `json:"bot_id,omitempty"`
+	Kind           string `json:"kind,omitempty"`
+	Title          string `json:"title,omitempty"`
+}
+
+type messageEvent struct {
+	ConversationID string `json:"conversation_id"`
+	MessageID      int64  `json:"message_id"`
+	Role           string `json:"role"`
+	ContentType    string `json:"content_type"`
+	Content        string `json:"content"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// outbox returns the event to store with a change, or nothing when the
+// outbox is disabled.
+func (m *manager) outbox(eventType, conversationID string, data any) []model.WebhookEvent {
+	if !m.outboxEnabled {
+		return nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	payload := string(b)
+	return []model.WebhookEvent{{
+		ID:             m.newID(),
+		Type:           eventType,
+		ConversationID: conversationID,
+		Data:           &payload,
+	}}
+}
+
+func conversationCreated(c model.Conversation) conversationEvent {
+	return conversationEvent{
+		ConversationID: c.UUID,
+		UserID:         c.UserID,
+		BotID:          c.BotID,
+		Kind:           c.Kind,
+		Title:          c.Title,
+	}
+}
+
+func messageCompleted(msg model.Message) messageEvent {
+	return messageEvent{
+		ConversationID: msg.ConversationID,
+		MessageID:      msg.ID,
+		Role:           msg.Role,
+		ContentType:    msg.ContentType,
+		Content:        msg.Content,
+		CreatedAt:      time.Now().Unix(),
+	}
+}
+
+// UpdateGeneratedTitle stores a title produced by the model. Unlike a
+// rename by the user it is announced to webhooks.
+func (m *manager) UpdateGeneratedTitle(ctx context.Context, conversationID, title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return errors.New("empty title")
+	}
+	return m.dao.UpdateConversation(conversationID, map[string]interface{}{
+		"title": title,
+	}, m.outbox(model.WebhookConversationTitleGenerated, conversationID, conversationEvent{
+		ConversationID: conversationID,
+		Title:          title,
+	})...)
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+type reminderEvent struct {
+	ReminderID     string `json:"reminder_id"`
+	UserID         string `json:"user_id"`
+	ConversationID string `json:"conversation_id"`
+	MessageID      int64  `json:"message_id"`
+	Message        string `json:"message"`
+	Kind           string `json:"kind"`
+	FiredAt        int64  `json:"fired_at"`
+}
+
+// SaveReminderMessage posts a fired reminder into its conversation as a bot
+// message. Along with message.completed it records reminder.fired, which
+// names the user to notify.
+func (m *manager) SaveReminderMessage(ctx context.Context, reminder model.Reminder) (model.Message, error) {
+	meta, err := json.Marshal(map[string]string{"reminder_id": reminder.UUID})
+	if err != nil {
+		return model.Message{}, err
+	}
+	metaStr := string(meta)
+	id := m.newID()
+	entity := model.Message{
+		ID:             id,
+		Sequence:       id,
+		ConversationID: reminder.ConversationID,
+		Role:           "assistant",
+		ContentType:    ContentTypeText,
+		Content:        strings.TrimSpace(reminder.Message),
+		Meta:           &metaStr,
+	}
+	events := m.outbox(model.WebhookMessageCompleted, entity.ConversationID, messageCompleted(entity))
+	events = append(events, m.outbox(model.WebhookReminderFired, entity.ConversationID, reminderEvent{
+		ReminderID:     reminder.UUID,
+		UserID:         reminder.UserID,
+		ConversationID: reminder.ConversationID,
+		MessageID:      entity.ID,
+		Message:        entity.Content,
+		Kind:           reminder.Kind,
+		FiredAt:        time.Now().Unix(),
+	})...)
+	if err := m.dao.CreateMessage(entity, events...); err != nil {
+		return model.Message{}, err
+	}
+	m.touchConversation(entity.ConversationID)
+	return entity, nil
+}
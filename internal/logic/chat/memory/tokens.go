@@ -0,0 +1,48 @@
+package memory
+
+import "unicode/utf8"
+
+const truncatedMarker = "\n...[truncated]"
+
+// EstimateTokens approximates the tokenizer without a vocabulary: ASCII text
+// averages about four characters per token, CJK and other wide runes about
+// one token each.
+func EstimateTokens(s string) int {
+	var ascii, wide int
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			wide++
+		}
+	}
+	return (ascii+3)/4 + wide
+}
+
+func TruncateToTokens(s string, budget int) string {
+	if budget <= 0 {
+		return ""
+	}
+	n := TokenPrefixLen(s, budget)
+	if n == len(s) {
+		return s
+	}
+	return s[:n] + truncatedMarker
+}
+
+// TokenPrefixLen returns the byte length of the longest prefix of s that fits
+// in budget tokens.
+func TokenPrefixLen(s string, budget int) int {
+	var ascii, wide int
+	for i, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			wide++
+		}
+		if (ascii+3)/4+wide > budget {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -9,72 +9,161 @@ type ModelListResp struct {
 	Data []ModelInfo
 }
 
+type Image struct {
+	URL      string
+	Data     []byte
+	MimeType string
+	BlobKey  string
+}
+
 type Message struct {
-	Role        string
-	ContentType string
-	Content     string
-	Meta        string
+	Role          string
+	ContentType   string
+	Content       string
+	Images        []Image
+	AttachmentIDs []string
+	Meta          string
+	// Mentions lists the user or bot IDs the message addresses. In group
+	// conversations the bot only replies when it is mentioned here or by
+	// its handle in the text.
+	Mentions []string
 }
 
+type CompletionMode string
+
+const (
+	CompletionModeChat  CompletionMode = "chat"
+	CompletionModeImage CompletionMode = "image"
+)
+
 type Completion struct {
-	ConversationID string
-	Model          string
-	Messages       []Message
-	Stream         bool
+	ConversationID   string
+	BotID            string
+	Model            string
+	Messages         []Message
+	Stream           bool
+	IncludeReasoning bool
+	Mode             CompletionMode
+	ImageSize        string
+	ImageCount       int
+	Cache            CacheControl
+}
+
+// CacheControl bypasses the response cache for one request: NoLookup always
+// asks the model, NoStore keeps the reply out of the cache.
+type CacheControl struct {
+	NoLookup bool
+	NoStore  bool
 }
 
 type CreateConversationReq struct {
 	Model   string
+	BotID   string
 	Message Message
+	Cache   CacheControl
 }
 
 type CreateConversationResp struct {
 	ConversationID string
 	Title          string
 	Reply          Message
+	FinishReason   string
+	// Cached is set when the reply was served from the response cache.
+	Cached bool
 }
 
+// ListConversationsReq pages by Page/PageSize, or by Cursor when it is set.
+// Cursor mode only counts the total when IncludeTotal is set.
 type ListConversationsReq struct {
-	Page     int
-	PageSize int
+	Page         int
+	PageSize     int
+	Cursor       string
+	IncludeTotal bool
+	FolderID     string
+	Tag          string
+	BotID        string
+	Archived     bool
+	UpdatedFrom  int64
+	UpdatedTo    int64
 }
 
 type ConversationItem struct {
 	ConversationID string
+	BotID          string
+	Kind           string
 	Title          string
+	Model          string
+	FolderID       string
+	Pinned         bool
+	Archived       bool
+	Tags           []string
 	CreatedAt      int64
 	UpdatedAt      int64
+	DeletedAt      int64
 }
 
 type ListConversationsResp struct {
-	Total    int64
-	Page     int
-	PageSize int
-	Items    []ConversationItem
+	Total      int64
+	Page       int
+	PageSize   int
+	NextCursor string
+	PrevCursor string
+	Items      []ConversationItem
 }
 
+// ListMessagesReq pages newest first. In cursor mode NextCursor loads older
+// messages and PrevCursor newer ones.
 type ListMessagesReq struct {
 	ConversationID string
 	Page           int
 	PageSize       int
+	Cursor         string
+	IncludeTotal   bool
+	// IncludeReasoning returns stored reasoning traces, which are hidden by
+	// default as in the stream.
+	IncludeReasoning bool
+}
+
+type AttachmentRef struct {
+	AttachmentID string
+	FileName     string
+	MimeType     string
+	Size         int64
 }
 
 type MessageItem struct {
 	ID          int64
 	Sequence    int64
+	UserID      string
 	Role        string
 	ContentType string
 	Content     string
+	Images      []Image
+	Attachments []AttachmentRef
+	Reasoning   string
 	Meta        string
 	IsSummary   bool
 	CreatedAt   int64
+	// Model is the model that wrote an assistant reply.
+	Model string
+	// Feedback is the requesting user's rating of the message, if any.
+	Feedback *MessageFeedback
+}
+
+type MessageFeedback struct {
+	Rating    int
+	Reasons   []string
+	Comment   string
+	UpdatedAt int64
 }
 
 type ListMessagesResp struct {
-	Total    int64
-	Page     int
-	PageSize int
-	Items    []MessageItem
+	Total      int64
+	Page       int
+	PageSize   int
+	NextCursor string
+	PrevCursor string
+	Items      []MessageItem
 }
 
 type GetConversationReq struct {
@@ -94,23 +183,143 @@ type ClearMessagesReq struct {
 	ConversationID string
 }
 
+type ListTrashReq struct {
+	Page     int
+	PageSize int
+}
+
+type RestoreConversationReq struct {
+	ConversationID string
+}
+
+type PinConversationReq struct {
+	ConversationID string
+	Pinned         bool
+}
+
+type ArchiveConversationReq struct {
+	ConversationID string
+	Archived       bool
+}
+
+type MoveConversationReq struct {
+	ConversationID string
+	FolderID       string
+}
+
+type SetConversationTagsReq struct {
+	ConversationID string
+	Tags           []string
+}
+
+type CreateFolderReq struct {
+	Name string
+}
+
+type RenameFolderReq struct {
+	FolderID string
+	Name     string
+}
+
+type DeleteFolderReq struct {
+	FolderID string
+}
+
+type FolderItem struct {
+	FolderID  string
+	Name      string
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type TagItem struct {
+	Tag   string
+	Count int64
+}
+
 type StreamEventType string
 
 const (
-	EventTextDelta StreamEventType = "text.delta"
-	EventImage     StreamEventType = "image"
-	EventDone      StreamEventType = "done"
-	EventError     StreamEventType = "error"
+	EventTextDelta      StreamEventType = "text.delta"
+	EventReasoningDelta StreamEventType = "reasoning.delta"
+	EventImage          StreamEventType = "image"
+	EventDone           StreamEventType = "done"
+	EventError          StreamEventType = "error"
 )
 
+// FinishReasonContentFilter ends a stream cut short by moderation.
+const FinishReasonContentFilter = "content_filter"
+
+type Citation struct {
+	KnowledgeBaseID string  `json:"knowledge_base_id"`
+	DocumentID      string  `json:"document_id"`
+	DocumentTitle   string  `json:"document_title"`
+	ChunkIndex      int     `json:"chunk_index"`
+	Score           float32 `json:"score"`
+}
+
 type StreamEvent struct {
 	Type           StreamEventType
 	Delta          string
 	ConversationID string
 	Title          string
+	Image          *Image
+	Citations      []Citation
+	FinishReason   string
+	// Usage is reported on the done event when the provider returns it.
+	Usage *Usage
+	// Cached is set on the done event of a reply replayed from the
+	// response cache.
+	Cached bool
+}
+
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
 }
 
 type MessageStream interface {
 	Next() (StreamEvent, bool, error)
 	Close() error
 }
+
+type Participant struct {
+	UserID      string
+	DisplayName string
+}
+
+// CreateGroupConversationReq creates a group owned by the caller, who joins
+// under DisplayName.
+type CreateGroupConversationReq struct {
+	BotID        string
+	Title        string
+	DisplayName  string
+	Participants []Participant
+}
+
+type ListParticipantsReq struct {
+	ConversationID string
+}
+
+type AddParticipantsReq struct {
+	ConversationID string
+	Participants   []Participant
+}
+
+type RemoveParticipantReq struct {
+	ConversationID string
+	UserID         string
+}
+
+type SetParticipantRoleReq struct {
+	ConversationID string
+	UserID         string
+	Role           string
+}
+
+type ParticipantItem struct {
+	UserID      string
+	DisplayName string
+	Role        string
+	JoinedAt    int64
+}
@@ -0,0 +1,119 @@
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"text/template"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+)
+
+// Assignment is the variant a request runs with.
+type Assignment struct {
+	ExperimentID string
+	Variant      configs.ExperimentVariant
+	prompt       *template.Template
+}
+
+// PromptData is what a variant's system prompt template can refer to.
+type PromptData struct {
+	BotID string
+	Model string
+	Date  string
+}
+
+// SystemPrompt renders the variant's system prompt; empty when it has none.
+func (a *Assignment) SystemPrompt(data PromptData) (string, error) {
+	if a == nil || a.prompt == nil {
+		return "", nil
+	}
+	var b strings.Builder
+	if err := a.prompt.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+type compiled struct {
+	conf    configs.Experiment
+	prompts []*template.Template
+}
+
+// Assigner buckets users into the configured experiments.
+type Assigner struct {
+	experiments []compiled
+}
+
+func NewAssigner(conf configs.ExperimentConfig) (*Assigner, error) {
+	a := &Assigner{}
+	ids := map[string]bool{}
+	for _, exp := range conf.Experiments {
+		if exp.ID == "" {
+			return nil, errors.New("experiment without id")
+		}
+		if ids[exp.ID] {
+			return nil, fmt.Errorf("duplicate experiment %q", exp.ID)
+		}
+		ids[exp.ID] = true
+		if len(exp.Variants) == 0 {
+			return nil, fmt.Errorf("experiment %s has no variants", exp.ID)
+		}
+		c := compiled{conf: exp, prompts: make([]*template.Template, len(exp.Variants))}
+		names := map[string]bool{}
+		total := 0
+		for i, v := range exp.Variants {
+			if v.Name == "" || names[v.Name] {
+				return nil, fmt.Errorf("experiment %s: variant names must be set and unique", exp.ID)
+			}
+			names[v.Name] = true
+			if v.Weight < 0 {
+				return nil, fmt.Errorf("experiment %s: negative weight", exp.ID)
+			}
+			total += v.Weight
+			if v.SystemPrompt != "" {
+				t, err := template.New(exp.ID + "/" + v.Name).Option("missingkey=error").Parse(v.SystemPrompt)
+				if err != nil {
+					return nil, fmt.Errorf("experiment %s variant %s: %w", exp.ID, v.Name, err)
+				}
+				c.prompts[i] = t
+			}
+		}
+		if total > 100 {
+			return nil, fmt.Errorf("experiment %s: weights add up to more than 100", exp.ID)
+		}
+		a.experiments = append(a.experiments, c)
+	}
+	return a, nil
+}
+
+// Assign returns the user's variant in the first enabled experiment covering
+// the bot, or nil when the user falls outside it or there is none.
+func (a *Assigner) Assign(botID, userID string) *Assignment {
+	if userID == "" {
+		return nil
+	}
+	for _, c := range a.experiments {
+		if !c.conf.Enabled || (c.conf.BotID != "" && c.conf.BotID != botID) {
+			continue
+		}
+		bucket := Bucket(c.conf.ID, userID)
+		for i, v := range c.conf.Variants {
+			if bucket < v.Weight {
+				return &Assignment{ExperimentID: c.conf.ID, Variant: v, prompt: c.prompts[i]}
+			}
+			bucket -= v.Weight
+		}
+		return nil
+	}
+	return nil
+}
+
+// Bucket maps a user to 0-99 for an experiment. Salting with the experiment
+// ID keeps a user's buckets independent across experiments.
+func Bucket(experimentID, userID string) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(experimentID + "\x00" + userID))
+	return int(h.Sum64() % 100)
+}
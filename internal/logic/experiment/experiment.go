@@ -0,0 +1,9 @@
+package experiment
+
+import "context"
+
+// Logic reports how an experiment's variants perform. Results cover every
+// user, so they are limited to the admins listed in the experiment config.
+type Logic interface {
+	GetResults(ctx context.Context, req *ResultsReq, userID string) (*ResultsResp, error)
+}
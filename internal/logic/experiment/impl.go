@@ -0,0 +1,95 @@
+package experiment
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/experiment"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+)
+
+type logicImpl struct {
+	dao    experiment.Dao
+	conf   configs.ExperimentConfig
+	admins map[string]bool
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	conf := svcCtx.Config.ExperimentConf
+	admins := make(map[string]bool, len(conf.AdminUserIDs))
+	for _, id := range conf.AdminUserIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = true
+		}
+	}
+	return &logicImpl{dao: svcCtx.Dao.ExperimentDao, conf: conf, admins: admins}
+}
+
+func (l *logicImpl) GetResults(ctx context.Context, req *ResultsReq, userID string) (*ResultsResp, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	if !l.admins[userID] {
+		return nil, errors.New("forbidden")
+	}
+	if req.ExperimentID == "" {
+		return nil, errors.New("missing experiment_id")
+	}
+	if req.From > 0 && req.To > 0 && req.From >= req.To {
+		return nil, errors.New("from must be before to")
+	}
+	stats, err := l.dao.AggregateVariants(req.ExperimentID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	byVariant := make(map[string]experiment.VariantStat, len(stats))
+	for _, s := range stats {
+		byVariant[s.Variant] = s
+	}
+
+	// Configured variants come first, in order, even without replies yet;
+	// variants since removed from the config follow.
+	out := &ResultsResp{ExperimentID: req.ExperimentID}
+	for _, exp := range l.conf.Experiments {
+		if exp.ID != req.ExperimentID {
+			continue
+		}
+		for _, v := range exp.Variants {
+			item := toVariantResult(byVariant[v.Name])
+			item.Variant, item.Weight, item.Model, item.PromptVersion = v.Name, v.Weight, v.Model, v.PromptVersion
+			out.Variants = append(out.Variants, item)
+			delete(byVariant, v.Name)
+		}
+	}
+	for _, s := range stats {
+		if _, ok := byVariant[s.Variant]; ok {
+			out.Variants = append(out.Variants, toVariantResult(s))
+		}
+	}
+	if len(out.Variants) == 0 {
+		return nil, errors.New("experiment not found")
+	}
+	return out, nil
+}
+
+func toVariantResult(s experiment.VariantStat) VariantResult {
+	item := VariantResult{
+		Variant:          s.Variant,
+		Replies:          s.Replies,
+		AvgLatencyMs:     s.AvgLatencyMs,
+		AvgFirstTokenMs:  s.AvgFirstTokenMs,
+		PromptTokens:     s.PromptTokens,
+		CompletionTokens: s.CompletionTokens,
+		Up:               s.Up,
+		Down:             s.Down,
+	}
+	if s.Replies > 0 {
+		item.AvgCompletionTokens = float64(s.CompletionTokens) / float64(s.Replies)
+	}
+	if rated := s.Up + s.Down; rated > 0 {
+		item.Satisfaction = float64(s.Up) / float64(rated)
+	}
+	return item
+}
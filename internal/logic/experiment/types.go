@@ -0,0 +1,32 @@
+package experiment
+
+type ResultsReq struct {
+	ExperimentID string
+	// From and To bound the reply time in unix seconds, To exclusive.
+	From int64
+	To   int64
+}
+
+// VariantResult compares one variant's replies. Latencies average the
+// replies that recorded one; Satisfaction is the share of thumbs up among
+// rated replies.
+type VariantResult struct {
+	Variant             string
+	Weight              int
+	Model               string
+	PromptVersion       string
+	Replies             int64
+	AvgLatencyMs        float64
+	AvgFirstTokenMs     float64
+	PromptTokens        int64
+	CompletionTokens    int64
+	AvgCompletionTokens float64
+	Up                  int64
+	Down                int64
+	Satisfaction        float64
+}
+
+type ResultsResp struct {
+	ExperimentID string
+	Variants     []VariantResult
+}
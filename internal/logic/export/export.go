@@ -0,0 +1,8 @@
+package export
+
+import "context"
+
+type Logic interface {
+	ExportConversation(ctx context.Context, req *ExportReq, userID string) (*Export, error)
+	ExportAll(ctx context.Context, req *ExportAllReq, userID string) (*Export, error)
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+// formatter renders one conversation. A fresh formatter is used for every
+// conversation, so implementations may keep state between calls.
+type formatter interface {
+	begin(w io.Writer, doc document) error
+	message(w io.Writer, msg record) error
+	end(w io.Writer) error
+}
+
+type document struct {
+	Conversation model.Conversation
+	Tags         []string
+	ExportedAt   time.Time
+}
+
+type record struct {
+	model.Message
+	Images      []memory.ImagePart
+	Attachments []model.Attachment
+}
+
+func newFormatter(format Format) (formatter, error) {
+	switch format {
+	case FormatMarkdown:
+		return &markdownFormatter{}, nil
+	case FormatJSON:
+		return &jsonFormatter{}, nil
+	case FormatHTML:
+		return &htmlFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %s", format)
+	}
+}
+
+func formatInfo(format Format) (ext, mimeType string) {
+	switch format {
+	case FormatJSON:
+		return ".json", "application/json"
+	case FormatHTML:
+		return ".html", "text/html; charset=utf-8"
+	default:
+		return ".md", "text/markdown; charset=utf-8"
+	}
+}
+
+func roleLabel(msg record) string {
+	if msg.IsSummary {
+		return "Summary"
+	}
+	switch msg.Role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "system":
+		return "System"
+	default:
+		return msg.Role
+	}
+}
+
+func formatTime(unix int64) string {
+	if unix <= 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+var htmlHeader = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;max-width:860px;margin:2rem auto;padding:0 1rem;color:#1f2328;background:#fff}
+header{border-bottom:1px solid #d0d7de;margin-bottom:1.5rem}
+header p{color:#59636e;font-size:.9rem;margin:.25rem 0}
+.msg{border:1px solid #d0d7de;border-radius:8px;padding:.75rem 1rem;margin:1rem 0}
+.msg.user{background:#f6f8fa}
+.msg.summary{border-style:dashed;color:#59636e}
+.meta{font-size:.8rem;color:#59636e;margin-bottom:.5rem}
+.content{white-space:pre-wrap;word-wrap:break-word}
+details{margin-top:.5rem;color:#59636e}
+img{max-width:100%;border-radius:6px;margin-top:.5rem}
+.attachment{font-size:.85rem;margin-top:.5rem}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>Conversation {{.ID}}{{if .Tags}} · {{.Tags}}{{end}}</p>
+<p>Created {{.Created}} · Exported {{.Exported}}</p>
+</header>
+`))
+
+var htmlMessage = template.Must(template.New("message").Parse(`<section class="msg {{.Class}}">
+<div class="meta">{{.Role}} · {{.Created}}</div>
+{{if .Content}}<div class="content">{{.Content}}</div>
+{{end}}{{if .Reasoning}}<details><summary>Reasoning</summary><div class="content">{{.Reasoning}}</div></details>
+{{end}}{{range .Images}}<img src="{{.}}" alt="image">
+{{end}}{{range .Attachments}}<div class="attachment">📎 {{.FileName}} ({{.MimeType}}, {{.Size}} bytes)</div>
+{{end}}</section>
+`))
+
+// htmlFormatter writes a single self-contained page: styles are inlined and
+// all content goes through html/template escaping.
+type htmlFormatter struct{}
+
+func (f *htmlFormatter) begin(w io.Writer, doc document) error {
+	c := doc.Conversation
+	return htmlHeader.Execute(w, map[string]any{
+		"Title":    c.Title,
+		"ID":       c.UUID,
+		"Tags":     strings.Join(doc.Tags, ", "),
+		"Created":  formatTime(c.CreatedAt),
+		"Exported": formatTime(doc.ExportedAt.Unix()),
+	})
+}
+
+func (f *htmlFormatter) message(w io.Writer, msg record) error {
+	class := msg.Role
+	if msg.IsSummary {
+		class = "summary"
+	}
+	images := make([]template.URL, 0, len(msg.Images))
+	for _, img := range msg.Images {
+		if strings.HasPrefix(img.URL, "data:image/") || strings.HasPrefix(img.URL, "https://") || strings.HasPrefix(img.URL, "http://") {
+			images = append(images, template.URL(img.URL))
+		}
+	}
+	return htmlMessage.Execute(w, map[string]any{
+		"Class":       class,
+		"Role":        roleLabel(msg),
+		"Created":     formatTime(msg.CreatedAt),
+		"Content":     msg.Content,
+		"Reasoning":   msg.Reasoning,
+		"Images":      images,
+		"Attachments": msg.Attachments,
+	})
+}
+
+func (f *htmlFormatter) end(w io.Writer) error {
+	_, err := io.WriteString(w, "</body>\n</html>\n")
+	return err
+}
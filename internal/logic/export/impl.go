@@ -0,0 +1,217 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/attachment"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+)
+
+const (
+	messageBatch      = 200
+	conversationBatch = 100
+	maxFileNameRunes  = 48
+)
+
+type logicImpl struct {
+	dao         chat.Dao
+	attachments attachment.Dao
+	blobURLs    *blob.URLSigner
+	now         func() time.Time
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	return &logicImpl{
+		dao:         svcCtx.Dao.ChatDao,
+		attachments: svcCtx.Dao.AttachmentDao,
+		blobURLs:    svcCtx.BlobURLs,
+		now:         time.Now,
+	}
+}
+
+func (l *logicImpl) ExportConversation(ctx context.Context, req *ExportReq, userID string) (*Export, error) {
+	if req.ConversationID == "" {
+		return nil, errors.New("missing conversation_id")
+	}
+	if _, err := newFormatter(req.Format); err != nil {
+		return nil, err
+	}
+	conversation, err := l.dao.GetConversationByID(req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && conversation.UserID != userID {
+		return nil, errors.New("forbidden")
+	}
+	ext, mimeType := formatInfo(req.Format)
+	return &Export{
+		FileName: fileName(*conversation) + ext,
+		MimeType: mimeType,
+		write: func(w io.Writer) error {
+			bw := bufio.NewWriter(w)
+			if err := l.writeConversation(ctx, bw, *conversation, req.Format); err != nil {
+				return err
+			}
+			return bw.Flush()
+		},
+	}, nil
+}
+
+// ExportAll writes every conversation of the user, archived ones included,
+// as one file per conversation in a zip archive.
+func (l *logicImpl) ExportAll(ctx context.Context, req *ExportAllReq, userID string) (*Export, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	if _, err := newFormatter(req.Format); err != nil {
+		return nil, err
+	}
+	ext, _ := formatInfo(req.Format)
+	return &Export{
+		FileName: "conversations-" + l.now().UTC().Format("20060102") + ".zip",
+		MimeType: "application/zip",
+		write: func(w io.Writer) error {
+			zw := zip.NewWriter(w)
+			for _, archived := range []bool{false, true} {
+				filter := chat.ConversationFilter{Archived: archived}
+				for offset := 0; ; offset += conversationBatch {
+					items, _, err := l.dao.ListConversationsByUser(userID, filter, offset, conversationBatch)
+					if err != nil {
+						return err
+					}
+					for _, item := range items {
+						fw, err := zw.CreateHeader(&zip.FileHeader{
+							Name:     fileName(item) + ext,
+							Method:   zip.Deflate,
+							Modified: time.Unix(item.UpdatedAt, 0),
+						})
+						if err != nil {
+							return err
+						}
+						if err := l.writeConversation(ctx, fw, item, req.Format); err != nil {
+							return err
+						}
+					}
+					if len(items) < conversationBatch {
+						break
+					}
+				}
+			}
+			return zw.Close()
+		},
+	}, nil
+}
+
+func (l *logicImpl) writeConversation(ctx context.Context, w io.Writer, conversation model.Conversation, format Format) error {
+	f, err := newFormatter(format)
+	if err != nil {
+		return err
+	}
+	tags, err := l.dao.ListTagsByConversations([]string{conversation.UUID})
+	if err != nil {
+		return err
+	}
+	doc := document{Conversation: conversation, ExportedAt: l.now()}
+	for _, t := range tags {
+		doc.Tags = append(doc.Tags, t.Tag)
+	}
+	if err := f.begin(w, doc); err != nil {
+		return err
+	}
+	var after int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		messages, err := l.dao.ListMessagesAfterSequence(conversation.UUID, after, messageBatch)
+		if err != nil {
+			return err
+		}
+		records, err := l.toRecords(messages)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := f.message(w, r); err != nil {
+				return err
+			}
+		}
+		if len(messages) < messageBatch {
+			break
+		}
+		after = messages[len(messages)-1].Sequence
+	}
+	return f.end(w)
+}
+
+func (l *logicImpl) toRecords(messages []model.Message) ([]record, error) {
+	ids := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	attachments, err := l.attachments.ListAttachmentsByMessages(ids)
+	if err != nil {
+		return nil, err
+	}
+	byMessage := make(map[int64][]model.Attachment, len(attachments))
+	for _, a := range attachments {
+		byMessage[a.MessageID] = append(byMessage[a.MessageID], a)
+	}
+	records := make([]record, 0, len(messages))
+	for _, msg := range messages {
+		records = append(records, record{
+			Message:     msg,
+			Images:      memory.SignImages(memory.DecodeImages(msg), l.blobURLs),
+			Attachments: byMessage[msg.ID],
+		})
+	}
+	return records, nil
+}
+
+// fileName builds a filesystem-safe name from the title; the ID prefix keeps
+// names unique inside a bulk archive.
+func fileName(c model.Conversation) string {
+	var (
+		b    strings.Builder
+		n    int
+		last rune
+	)
+	for _, r := range strings.TrimSpace(c.Title) {
+		if n >= maxFileNameRunes {
+			break
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		case unicode.IsSpace(r) || r == '-':
+			if last == '-' {
+				continue
+			}
+			r = '-'
+		default:
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+		n++
+	}
+	name := strings.Trim(b.String(), "-")
+	if name == "" {
+		name = "conversation"
+	}
+	id := c.UUID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return name + "-" + id
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	jsonSchema  = "im-core-bot.conversation-export"
+	jsonVersion = 1
+)
+
+type jsonConversation struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	BotID     string   `json:"bot_id,omitempty"`
+	FolderID  string   `json:"folder_id,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Pinned    bool     `json:"pinned"`
+	Archived  bool     `json:"archived"`
+	CreatedAt int64    `json:"created_at"`
+	UpdatedAt int64    `json:"updated_at"`
+}
+
+type jsonImage struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+type jsonAttachment struct {
+	ID       string `json:"id"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+type jsonMessage struct {
+	ID            int64            `json:"id"`
+	Sequence      int64            `json:"sequence"`
+	Role          string           `json:"role"`
+	ContentType   string           `json:"content_type"`
+	Content       string           `json:"content"`
+	Reasoning     string           `json:"reasoning,omitempty"`
+	Images        []jsonImage      `json:"images,omitempty"`
+	Attachments   []jsonAttachment `json:"attachments,omitempty"`
+	Meta          json.RawMessage  `json:"meta,omitempty"`
+	IsSummary     bool             `json:"is_summary"`
+	SummaryFromID int64            `json:"summary_from_id,omitempty"`
+	SummaryToID   int64            `json:"summary_to_id,omitempty"`
+	CreatedAt     int64            `json:"created_at"`
+}
+
+// jsonFormatter writes the versioned export schema:
+//
+//	{"schema": ..., "version": 1, "exported_at": ..., "conversation": {...}, "messages": [...]}
+//
+// Messages are encoded one by one so the document is never held in memory.
+type jsonFormatter struct {
+	count int
+}
+
+func (f *jsonFormatter) begin(w io.Writer, doc document) error {
+	c := doc.Conversation
+	header, err := json.Marshal(struct {
+		Schema       string           `json:"schema"`
+		Version      int              `json:"version"`
+		ExportedAt   int64            `json:"exported_at"`
+		Conversation jsonConversation `json:"conversation"`
+	}{
+		Schema:     jsonSchema,
+		Version:    jsonVersion,
+		ExportedAt: doc.ExportedAt.Unix(),
+		Conversation: jsonConversation{
+			ID:        c.UUID,
+			Title:     c.Title,
+			BotID:     c.BotID,
+			FolderID:  c.FolderID,
+			Tags:      doc.Tags,
+			Pinned:    c.Pinned,
+			Archived:  c.Archived,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// Reopen the header object to append the messages array.
+	if _, err := w.Write(header[:len(header)-1]); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `,"messages":[`)
+	return err
+}
+
+func (f *jsonFormatter) message(w io.Writer, msg record) error {
+	out := jsonMessage{
+		ID:            msg.ID,
+		Sequence:      msg.Sequence,
+		Role:          msg.Role,
+		ContentType:   msg.ContentType,
+		Content:       msg.Content,
+		Reasoning:     msg.Reasoning,
+		IsSummary:     msg.IsSummary,
+		SummaryFromID: msg.SummaryFromID,
+		SummaryToID:   msg.SummaryToID,
+		CreatedAt:     msg.CreatedAt,
+	}
+	if msg.Meta != nil && json.Valid([]byte(*msg.Meta)) {
+		out.Meta = json.RawMessage(*msg.Meta)
+	}
+	for _, img := range msg.Images {
+		out.Images = append(out.Images, jsonImage{URL: img.URL, MimeType: img.MimeType})
+	}
+	for _, a := range msg.Attachments {
+		out.Attachments = append(out.Attachments, jsonAttachment{ID: a.UUID, FileName: a.FileName, MimeType: a.MimeType, Size: a.Size})
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	if f.count > 0 {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	f.count++
+	_, err = w.Write(b)
+	return err
+}
+
+func (f *jsonFormatter) end(w io.Writer) error {
+	_, err := io.WriteString(w, "]}\n")
+	return err
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type markdownFormatter struct{}
+
+func (f *markdownFormatter) begin(w io.Writer, doc document) error {
+	c := doc.Conversation
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", c.Title)
+	fmt.Fprintf(&b, "- Conversation: `%s`\n", c.UUID)
+	if c.BotID != "" {
+		fmt.Fprintf(&b, "- Bot: `%s`\n", c.BotID)
+	}
+	if len(doc.Tags) > 0 {
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(doc.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "- Created: %s\n", formatTime(c.CreatedAt))
+	fmt.Fprintf(&b, "- Exported: %s\n", formatTime(doc.ExportedAt.Unix()))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (f *markdownFormatter) message(w io.Writer, msg record) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n---\n\n### %s · %s\n\n", roleLabel(msg), formatTime(msg.CreatedAt))
+	if msg.IsSummary {
+		for _, line := range strings.Split(msg.Content, "\n") {
+			fmt.Fprintf(&b, "> %s\n", line)
+		}
+	} else if msg.Content != "" {
+		b.WriteString(msg.Content)
+		b.WriteString("\n")
+	}
+	if msg.Reasoning != "" {
+		fmt.Fprintf(&b, "\n<details>\n<summary>Reasoning</summary>\n\n%s\n\n</details>\n", msg.Reasoning)
+	}
+	for i, img := range msg.Images {
+		if !strings.HasPrefix(img.URL, "data:") {
+			fmt.Fprintf(&b, "\n![image %d](%s)\n", i+1, img.URL)
+		} else {
+			fmt.Fprintf(&b, "\n*[inline image %d]*\n", i+1)
+		}
+	}
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "\n📎 %s (%s, %d bytes)\n", a.FileName, a.MimeType, a.Size)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (f *markdownFormatter) end(w io.Writer) error {
+	return nil
+}
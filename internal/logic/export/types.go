@@ -0,0 +1,33 @@
+package export
+
+import "io"
+
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+	FormatHTML     Format = "html"
+)
+
+type ExportReq struct {
+	ConversationID string
+	Format         Format
+}
+
+type ExportAllReq struct {
+	Format Format
+}
+
+// Export is a prepared export. Access is checked when it is created; the
+// content is produced only when Write is called, so large conversations are
+// streamed rather than built in memory.
+type Export struct {
+	FileName string
+	MimeType string
+	write    func(w io.Writer) error
+}
+
+func (e *Export) Write(w io.Writer) error {
+	return e.write(w)
+}
@@ -0,0 +1,14 @@
+package feedback
+
+import "context"
+
+// Logic records users' ratings of assistant messages and reports
+// satisfaction across bots, models and prompt versions.
+type Logic interface {
+	// RateMessage sets the user's feedback on an assistant message. A zero
+	// rating withdraws it and returns nil.
+	RateMessage(ctx context.Context, req *RateMessageReq, userID string) (*FeedbackItem, error)
+	// GetFeedbackStats aggregates every user's ratings, so it is limited to
+	// the admins listed in the feedback config.
+	GetFeedbackStats(ctx context.Context, req *FeedbackStatsReq, userID string) (*FeedbackStatsResp, error)
+}
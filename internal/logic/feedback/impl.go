@@ -0,0 +1,244 @@
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/feedback"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxCommentChars = 2000
+	maxReasons             = 10
+	maxReasonChars         = 32
+)
+
+var groupBys = map[string]bool{
+	feedback.GroupByBot:           true,
+	feedback.GroupByModel:         true,
+	feedback.GroupByPromptVersion: true,
+	feedback.GroupByDate:          true,
+}
+
+type logicImpl struct {
+	dao             feedback.Dao
+	chat            chat.Dao
+	newID           func() int64
+	admins          map[string]bool
+	reasonTags      map[string]bool
+	maxCommentChars int
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	conf := svcCtx.Config.FeedbackConf
+	l := &logicImpl{
+		dao:             svcCtx.Dao.FeedbackDao,
+		chat:            svcCtx.Dao.ChatDao,
+		newID:           func() int64 { return svcCtx.Utils.SnowFlake.Generate().Int64() },
+		admins:          map[string]bool{},
+		reasonTags:      map[string]bool{},
+		maxCommentChars: conf.MaxCommentChars,
+	}
+	if l.maxCommentChars <= 0 {
+		l.maxCommentChars = defaultMaxCommentChars
+	}
+	for _, id := range conf.AdminUserIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			l.admins[id] = true
+		}
+	}
+	for _, tag := range conf.ReasonTags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			l.reasonTags[tag] = true
+		}
+	}
+	return l
+}
+
+func (l *logicImpl) RateMessage(ctx context.Context, req *RateMessageReq, userID string) (*FeedbackItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	if req.MessageID == 0 {
+		return nil, errors.New("missing message_id")
+	}
+	if req.Rating != 0 && req.Rating != model.FeedbackRatingUp && req.Rating != model.FeedbackRatingDown {
+		return nil, errors.New("rating must be 1, -1 or 0")
+	}
+	message, conversation, err := l.ratableMessage(req.MessageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Rating == 0 {
+		return nil, l.dao.DeleteFeedback(message.ID, userID)
+	}
+
+	reasons, err := l.normalizeReasons(req.Reasons)
+	if err != nil {
+		return nil, err
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(comment) > l.maxCommentChars {
+		return nil, fmt.Errorf("comment longer than %d characters", l.maxCommentChars)
+	}
+	var encoded *string
+	if len(reasons) > 0 {
+		b, err := json.Marshal(reasons)
+		if err != nil {
+			return nil, err
+		}
+		s := string(b)
+		encoded = &s
+	}
+	if err := l.dao.UpsertFeedback(model.MessageFeedback{
+		ID:             l.newID(),
+		MessageID:      message.ID,
+		UserID:         userID,
+		ConversationID: message.ConversationID,
+		BotID:          conversation.BotID,
+		Model:          message.Model,
+		PromptVersion:  message.PromptVersion,
+		Rating:         req.Rating,
+		Reasons:        encoded,
+		Comment:        comment,
+	}); err != nil {
+		return nil, err
+	}
+	entity, err := l.dao.GetFeedback(message.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	item := ToFeedbackItem(*entity)
+	return &item, nil
+}
+
+// ratableMessage loads an assistant reply the user can see: in their own
+// conversation or in a group they take part in.
+func (l *logicImpl) ratableMessage(messageID int64, userID string) (*model.Message, *model.Conversation, error) {
+	message, err := l.chat.GetMessageByID(messageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("message not found")
+		}
+		return nil, nil, err
+	}
+	if message.Role != "assistant" || message.IsSummary {
+		return nil, nil, errors.New("only assistant replies can be rated")
+	}
+	conversation, err := l.chat.GetConversationByID(message.ConversationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("message not found")
+		}
+		return nil, nil, err
+	}
+	if conversation.UserID == userID {
+		return message, conversation, nil
+	}
+	if conversation.Kind == model.ConversationKindGroup {
+		_, err := l.chat.GetParticipant(conversation.UUID, userID)
+		if err == nil {
+			return message, conversation, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, errors.New("forbidden")
+}
+
+func (l *logicImpl) normalizeReasons(reasons []string) ([]string, error) {
+	out := make([]string, 0, len(reasons))
+	seen := map[string]bool{}
+	for _, r := range reasons {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "" || seen[r] {
+			continue
+		}
+		if len(l.reasonTags) > 0 && !l.reasonTags[r] {
+			return nil, fmt.Errorf("unknown reason %q", r)
+		}
+		if utf8.RuneCountInString(r) > maxReasonChars {
+			return nil, fmt.Errorf("reason longer than %d characters", maxReasonChars)
+		}
+		seen[r] = true
+		out = append(out, r)
+	}
+	if len(out) > maxReasons {
+		return nil, fmt.Errorf("at most %d reasons", maxReasons)
+	}
+	return out, nil
+}
+
+func (l *logicImpl) GetFeedbackStats(ctx context.Context, req *FeedbackStatsReq, userID string) (*FeedbackStatsResp, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	if !l.admins[userID] {
+		return nil, errors.New("forbidden")
+	}
+	if req.From > 0 && req.To > 0 && req.From >= req.To {
+		return nil, errors.New("from must be before to")
+	}
+	groupBy := make([]string, 0, len(req.GroupBy))
+	seen := map[string]bool{}
+	for _, g := range req.GroupBy {
+		if !groupBys[g] {
+			return nil, fmt.Errorf("invalid group_by %q", g)
+		}
+		if !seen[g] {
+			seen[g] = true
+			groupBy = append(groupBy, g)
+		}
+	}
+	stats, err := l.dao.AggregateFeedback(feedback.StatsFilter{
+		BotID:         req.BotID,
+		Model:         req.Model,
+		PromptVersion: req.PromptVersion,
+		From:          req.From,
+		To:            req.To,
+	}, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]FeedbackStat, 0, len(stats))
+	for _, s := range stats {
+		item := FeedbackStat{
+			BotID:         s.BotID,
+			Model:         s.Model,
+			PromptVersion: s.PromptVersion,
+			Date:          s.Date,
+			Up:            s.Up,
+			Down:          s.Down,
+			Total:         s.Up + s.Down,
+		}
+		if item.Total > 0 {
+			item.Satisfaction = float64(item.Up) / float64(item.Total)
+		}
+		items = append(items, item)
+	}
+	return &FeedbackStatsResp{Items: items}, nil
+}
+
+// ToFeedbackItem converts a stored rating for responses.
+func ToFeedbackItem(entity model.MessageFeedback) FeedbackItem {
+	item := FeedbackItem{
+		MessageID: entity.MessageID,
+		Rating:    entity.Rating,
+		Comment:   entity.Comment,
+		UpdatedAt: entity.UpdatedAt,
+	}
+	if entity.Reasons != nil {
+		_ = json.Unmarshal([]byte(*entity.Reasons), &item.Reasons)
+	}
+	return item
+}
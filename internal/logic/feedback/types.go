@@ -0,0 +1,45 @@
+package feedback
+
+type RateMessageReq struct {
+	MessageID int64
+	// Rating is 1 for thumbs up, -1 for thumbs down and 0 to withdraw.
+	Rating  int
+	Reasons []string
+	Comment string
+}
+
+type FeedbackItem struct {
+	MessageID int64
+	Rating    int
+	Reasons   []string
+	Comment   string
+	UpdatedAt int64
+}
+
+type FeedbackStatsReq struct {
+	BotID         string
+	Model         string
+	PromptVersion string
+	// From and To bound the rating time in unix seconds, To exclusive.
+	From int64
+	To   int64
+	// GroupBy lists the dimensions to break down by: bot, model,
+	// prompt_version and date. Empty gives one overall row.
+	GroupBy []string
+}
+
+type FeedbackStat struct {
+	BotID         string
+	Model         string
+	PromptVersion string
+	Date          string
+	Up            int64
+	Down          int64
+	Total         int64
+	// Satisfaction is the share of thumbs up among all ratings.
+	Satisfaction float64
+}
+
+type FeedbackStatsResp struct {
+	Items []FeedbackStat
+}
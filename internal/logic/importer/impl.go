@@ -0,0 +1,249 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+)
+
+const (
+	defaultMaxBytes = 50 << 20
+	defaultTitle    = "Imported conversation"
+	maxTitleRunes   = 255
+	maxErrorLen     = 512
+)
+
+type logicImpl struct {
+	dao      chat.Dao
+	newID    func() int64
+	newUUID  func() string
+	now      func() time.Time
+	maxBytes int64
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	maxBytes := svcCtx.Config.ImportConf.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+	return &logicImpl{
+		dao:      svcCtx.Dao.ChatDao,
+		newID:    func() int64 { return svcCtx.Utils.SnowFlake.Generate().Int64() },
+		newUUID:  func() string { return svcCtx.Utils.UUID.New() },
+		now:      time.Now,
+		maxBytes: maxBytes,
+	}
+}
+
+// ImportConversations parses the whole input up front and then imports the
+// conversations one by one; a bad conversation is reported in its item and
+// does not stop the rest.
+func (l *logicImpl) ImportConversations(ctx context.Context, req *ImportReq, r io.Reader, userID string) (*ImportResp, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	data, err := io.ReadAll(io.LimitReader(r, l.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > l.maxBytes {
+		return nil, fmt.Errorf("import exceeds %d bytes", l.maxBytes)
+	}
+	format := req.Format
+	if format == FormatAuto {
+		if format, err = detectFormat(data); err != nil {
+			return nil, fmt.Errorf("unrecognized import file: %w", err)
+		}
+	}
+	conversations, err := parse(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s import: %w", format, err)
+	}
+
+	resp := &ImportResp{Format: format, Items: make([]ItemResult, 0, len(conversations))}
+	for i, conv := range conversations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item := l.importOne(conv, req.BotID, userID)
+		item.Index = i
+		switch item.Status {
+		case StatusImported:
+			resp.Imported++
+		case StatusSkipped:
+			resp.Skipped++
+		default:
+			resp.Failed++
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+func (l *logicImpl) importOne(conv conversation, botID, userID string) ItemResult {
+	item := ItemResult{SourceID: conv.SourceID, Title: conv.Title}
+	fail := func(err error) ItemResult {
+		item.Status = StatusFailed
+		item.Error = truncate(err.Error(), maxErrorLen)
+		return item
+	}
+	if conv.Err != nil {
+		return fail(conv.Err)
+	}
+
+	entity, messages, err := l.build(conv, botID, userID)
+	if err != nil {
+		return fail(err)
+	}
+	// The unique import key decides; a concurrent import of the same
+	// export loses the insert and is skipped like a repeated one.
+	created, err := l.dao.ImportConversation(entity, messages)
+	if err != nil {
+		logger.L().Errorf("import conversation %s for user %s error: %v", conv.SourceID, userID, err)
+		return fail(err)
+	}
+	if !created {
+		existing, err := l.dao.GetConversationByImportKey(userID, conv.Key)
+		if err != nil {
+			return fail(err)
+		}
+		item.Status = StatusSkipped
+		item.ConversationID = existing.UUID
+		item.Title = existing.Title
+		return item
+	}
+	item.Status = StatusImported
+	item.ConversationID = entity.UUID
+	item.Title = entity.Title
+	item.MessageCount = len(messages)
+	return item
+}
+
+// build converts a parsed conversation into rows. Missing timestamps are
+// filled from the neighbouring message so the stored order and the
+// timestamps agree; sequences are allocated in source order.
+func (l *logicImpl) build(conv conversation, botID, userID string) (model.Conversation, []model.Message, error) {
+	now := l.now().Unix()
+	createdAt := conv.CreatedAt
+	if createdAt <= 0 {
+		createdAt = now
+		for _, m := range conv.Messages {
+			if m.CreatedAt > 0 {
+				createdAt = m.CreatedAt
+				break
+			}
+		}
+	}
+
+	entity := model.Conversation{
+		UUID:      l.newUUID(),
+		UserID:    userID,
+		BotID:     botID,
+		ImportKey: &conv.Key,
+		Title:     title(conv.Title),
+	}
+	messages := make([]model.Message, 0, len(conv.Messages))
+	last := createdAt
+	for i, m := range conv.Messages {
+		// System prompts belong to the source's configuration, not the
+		// transcript; the bot applies its own.
+		if m.Role == "system" {
+			continue
+		}
+		if m.Role != "user" && m.Role != "assistant" {
+			return entity, nil, fmt.Errorf("message %d: unsupported role %q", i, m.Role)
+		}
+		images, err := encodeImages(m.Images)
+		if err != nil {
+			return entity, nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		if strings.TrimSpace(m.Content) == "" && images == nil {
+			continue
+		}
+		if m.CreatedAt > last {
+			last = m.CreatedAt
+		}
+		contentType := m.ContentType
+		if contentType == "" {
+			contentType = "text"
+		}
+		id := l.newID()
+		msg := model.Message{
+			ID:             id,
+			Sequence:       id,
+			ConversationID: entity.UUID,
+			Role:           m.Role,
+			ContentType:    contentType,
+			Content:        m.Content,
+			Reasoning:      m.Reasoning,
+			Images:         images,
+		}
+		if m.Meta != "" && json.Valid([]byte(m.Meta)) {
+			meta := m.Meta
+			msg.Meta = &meta
+		}
+		msg.CreatedAt = last
+		msg.UpdatedAt = last
+		messages = append(messages, msg)
+	}
+	if len(messages) == 0 {
+		return entity, nil, errors.New("no importable messages")
+	}
+
+	entity.CreatedAt = createdAt
+	entity.UpdatedAt = max(conv.UpdatedAt, last)
+	return entity, messages, nil
+}
+
+// encodeImages keeps only remote URLs; blob keys of another deployment
+// cannot be resolved here.
+func encodeImages(urls []string) (*string, error) {
+	var images []memory.ImagePart
+	for _, u := range urls {
+		if strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+			images = append(images, memory.ImagePart{URL: u})
+		}
+	}
+	if len(images) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(images)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	return &s, nil
+}
+
+func title(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return defaultTitle
+	}
+	if utf8.RuneCountInString(s) > maxTitleRunes {
+		s = string([]rune(s)[:maxTitleRunes])
+	}
+	return s
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,10 @@
+package importer
+
+import (
+	"context"
+	"io"
+)
+
+type Logic interface {
+	ImportConversations(ctx context.Context, req *ImportReq, r io.Reader, userID string) (*ImportResp, error)
+}
@@ -0,0 +1,410 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// maxImportKeyLen is the size of the import_key column.
+const maxImportKeyLen = 128
+
+// conversation is the format-neutral shape every parser produces.
+type conversation struct {
+	SourceID  string
+	Key       string
+	Title     string
+	CreatedAt int64
+	UpdatedAt int64
+	Messages  []message
+	Err       error
+}
+
+type message struct {
+	Role        string
+	ContentType string
+	Content     string
+	Reasoning   string
+	Images      []string
+	Meta        string
+	CreatedAt   int64
+}
+
+func detectFormat(data []byte) (Format, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return "", errors.New("empty input")
+	}
+	switch trimmed[0] {
+	case '{':
+		var probe struct {
+			Schema string `json:"schema"`
+		}
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return "", err
+		}
+		if probe.Schema == nativeSchema {
+			return FormatNative, nil
+		}
+		return FormatGeneric, nil
+	case '[':
+		var probe []map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return "", err
+		}
+		if len(probe) > 0 {
+			if _, ok := probe[0]["mapping"]; ok {
+				return FormatChatGPT, nil
+			}
+		}
+		return FormatGeneric, nil
+	default:
+		return "", errors.New("input is not a JSON object or array")
+	}
+}
+
+func parse(format Format, data []byte) ([]conversation, error) {
+	switch format {
+	case FormatChatGPT:
+		return parseChatGPT(data)
+	case FormatNative:
+		return parseNative(data)
+	case FormatGeneric:
+		return parseGeneric(data)
+	default:
+		return nil, fmt.Errorf("unknown import format %s", format)
+	}
+}
+
+// ChatGPT exports store each conversation as a tree of nodes keyed by ID;
+// edits and regenerations create sibling branches.
+type chatGPTConversation struct {
+	ID             string                 `json:"id"`
+	ConversationID string                 `json:"conversation_id"`
+	Title          string                 `json:"title"`
+	CreateTime     float64                `json:"create_time"`
+	UpdateTime     float64                `json:"update_time"`
+	CurrentNode    string                 `json:"current_node"`
+	Mapping        map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	ID       string          `json:"id"`
+	Parent   string          `json:"parent"`
+	Children []string        `json:"children"`
+	Message  *chatGPTMessage `json:"message"`
+}
+
+type chatGPTMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+		Text        string            `json:"text"`
+	} `json:"content"`
+	Metadata struct {
+		IsVisuallyHidden bool `json:"is_visually_hidden_from_conversation"`
+	} `json:"metadata"`
+}
+
+func parseChatGPT(data []byte) ([]conversation, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	out := make([]conversation, 0, len(items))
+	for _, raw := range items {
+		var src chatGPTConversation
+		if err := json.Unmarshal(raw, &src); err != nil {
+			out = append(out, conversation{Err: err})
+			continue
+		}
+		id := src.ConversationID
+		if id == "" {
+			id = src.ID
+		}
+		conv := conversation{
+			SourceID:  id,
+			Title:     src.Title,
+			CreatedAt: unixSeconds(src.CreateTime),
+			UpdatedAt: unixSeconds(src.UpdateTime),
+		}
+		if id != "" {
+			conv.Key = importKey("chatgpt", id)
+		}
+		for _, node := range chatGPTBranch(src) {
+			if msg, ok := chatGPTToMessage(node.Message); ok {
+				conv.Messages = append(conv.Messages, msg)
+			}
+		}
+		if conv.Key == "" {
+			conv.Key = "chatgpt:" + contentHash(conv)
+		}
+		out = append(out, conv)
+	}
+	return out, nil
+}
+
+// chatGPTBranch returns the branch the user last saw, root first: the path
+// from current_node up to the root, or the last-child path when the export
+// has no current_node.
+func chatGPTBranch(src chatGPTConversation) []chatGPTNode {
+	var path []chatGPTNode
+	if node, ok := src.Mapping[src.CurrentNode]; ok {
+		seen := make(map[string]bool)
+		for ok && !seen[node.ID] {
+			seen[node.ID] = true
+			path = append(path, node)
+			node, ok = src.Mapping[node.Parent]
+		}
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		return path
+	}
+	var roots []string
+	for id, node := range src.Mapping {
+		if _, ok := src.Mapping[node.Parent]; !ok {
+			roots = append(roots, id)
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+	sort.Strings(roots)
+	seen := make(map[string]bool)
+	node, ok := src.Mapping[roots[0]]
+	for ok && !seen[node.ID] {
+		seen[node.ID] = true
+		path = append(path, node)
+		if len(node.Children) == 0 {
+			break
+		}
+		node, ok = src.Mapping[node.Children[len(node.Children)-1]]
+	}
+	return path
+}
+
+func chatGPTToMessage(src *chatGPTMessage) (message, bool) {
+	if src == nil || src.Metadata.IsVisuallyHidden {
+		return message{}, false
+	}
+	role := src.Author.Role
+	if role != "user" && role != "assistant" {
+		return message{}, false
+	}
+	var parts []string
+	for _, raw := range src.Content.Parts {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil && strings.TrimSpace(s) != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 && src.Content.Text != "" {
+		parts = append(parts, src.Content.Text)
+	}
+	content := strings.TrimSpace(strings.Join(parts, "\n"))
+	if content == "" {
+		return message{}, false
+	}
+	return message{
+		Role:        role,
+		ContentType: "text",
+		Content:     content,
+		CreatedAt:   unixSeconds(src.CreateTime),
+	}, true
+}
+
+const (
+	nativeSchema  = "im-core-bot.conversation-export"
+	nativeVersion = 1
+)
+
+// nativeExport mirrors the JSON written by the export package.
+type nativeExport struct {
+	Schema       string `json:"schema"`
+	Version      int    `json:"version"`
+	Conversation struct {
+		ID        string `json:"id"`
+		Title     string `json:"title"`
+		CreatedAt int64  `json:"created_at"`
+		UpdatedAt int64  `json:"updated_at"`
+	} `json:"conversation"`
+	Messages []struct {
+		Role        string `json:"role"`
+		ContentType string `json:"content_type"`
+		Content     string `json:"content"`
+		Reasoning   string `json:"reasoning"`
+		Images      []struct {
+			URL string `json:"url"`
+		} `json:"images"`
+		Meta      json.RawMessage `json:"meta"`
+		IsSummary bool            `json:"is_summary"`
+		CreatedAt int64           `json:"created_at"`
+	} `json:"messages"`
+}
+
+func parseNative(data []byte) ([]conversation, error) {
+	var src nativeExport
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, err
+	}
+	if src.Schema != nativeSchema {
+		return nil, errors.New("not a conversation export")
+	}
+	if src.Version > nativeVersion {
+		return nil, fmt.Errorf("unsupported export version %d", src.Version)
+	}
+	conv := conversation{
+		SourceID:  src.Conversation.ID,
+		Title:     src.Conversation.Title,
+		CreatedAt: src.Conversation.CreatedAt,
+		UpdatedAt: src.Conversation.UpdatedAt,
+	}
+	for _, m := range src.Messages {
+		// Summaries point at message IDs of the source and are rebuilt
+		// from the imported history instead.
+		if m.IsSummary {
+			continue
+		}
+		msg := message{
+			Role:        m.Role,
+			ContentType: m.ContentType,
+			Content:     m.Content,
+			Reasoning:   m.Reasoning,
+			CreatedAt:   m.CreatedAt,
+		}
+		for _, img := range m.Images {
+			msg.Images = append(msg.Images, img.URL)
+		}
+		if len(m.Meta) > 0 && string(m.Meta) != "null" {
+			msg.Meta = string(m.Meta)
+		}
+		conv.Messages = append(conv.Messages, msg)
+	}
+	if conv.SourceID != "" {
+		conv.Key = importKey("native", conv.SourceID)
+	} else {
+		conv.Key = "native:" + contentHash(conv)
+	}
+	return []conversation{conv}, nil
+}
+
+type genericMessage struct {
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type genericConversation struct {
+	ID        string           `json:"id"`
+	Title     string           `json:"title"`
+	CreatedAt int64            `json:"created_at"`
+	Messages  []genericMessage `json:"messages"`
+
+	err error
+}
+
+// parseGeneric accepts a {title, messages} object, an array of them, or a
+// bare array of {role, content} messages.
+func parseGeneric(data []byte) ([]conversation, error) {
+	data = bytes.TrimSpace(data)
+	var sources []genericConversation
+	if len(data) > 0 && data[0] == '{' {
+		var one genericConversation
+		if err := json.Unmarshal(data, &one); err != nil {
+			return nil, err
+		}
+		sources = append(sources, one)
+	} else {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		var probe map[string]json.RawMessage
+		if len(items) > 0 && json.Unmarshal(items[0], &probe) == nil && probe["role"] != nil {
+			var messages []genericMessage
+			if err := json.Unmarshal(data, &messages); err != nil {
+				return nil, err
+			}
+			sources = append(sources, genericConversation{Messages: messages})
+		} else {
+			for _, raw := range items {
+				var src genericConversation
+				if err := json.Unmarshal(raw, &src); err != nil {
+					sources = append(sources, genericConversation{err: err})
+					continue
+				}
+				sources = append(sources, src)
+			}
+		}
+	}
+	out := make([]conversation, 0, len(sources))
+	for _, src := range sources {
+		if src.err != nil {
+			out = append(out, conversation{Err: src.err})
+			continue
+		}
+		conv := conversation{
+			SourceID:  src.ID,
+			Title:     src.Title,
+			CreatedAt: src.CreatedAt,
+		}
+		for _, m := range src.Messages {
+			conv.Messages = append(conv.Messages, message{
+				Role:        strings.ToLower(strings.TrimSpace(m.Role)),
+				ContentType: "text",
+				Content:     m.Content,
+				CreatedAt:   m.CreatedAt,
+			})
+		}
+		if src.ID != "" {
+			conv.Key = importKey("generic", src.ID)
+		} else {
+			conv.Key = "generic:" + contentHash(conv)
+		}
+		out = append(out, conv)
+	}
+	return out, nil
+}
+
+// contentHash keys conversations that carry no source ID, so importing the
+// same file twice still deduplicates.
+// importKey builds the dedup key of a conversation from its source ID. IDs
+// too long for the import_key column are replaced by their hash.
+func importKey(source, id string) string {
+	key := source + ":" + id
+	if len(key) <= maxImportKeyLen {
+		return key
+	}
+	sum := sha256.Sum256([]byte(id))
+	return source + ":sha256:" + hex.EncodeToString(sum[:])
+}
+
+func contentHash(c conversation) string {
+	h := sha256.New()
+	h.Write([]byte(c.Title))
+	for _, m := range c.Messages {
+		h.Write([]byte{0})
+		h.Write([]byte(m.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(m.Content))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:40]
+}
+
+func unixSeconds(v float64) int64 {
+	if v <= 0 || math.IsNaN(v) {
+		return 0
+	}
+	return int64(v)
+}
@@ -0,0 +1,41 @@
+package importer
+
+type Format string
+
+const (
+	FormatAuto    Format = ""
+	FormatChatGPT Format = "chatgpt"
+	FormatNative  Format = "native"
+	FormatGeneric Format = "generic"
+)
+
+const (
+	StatusImported = "imported"
+	StatusSkipped  = "skipped"
+	StatusFailed   = "failed"
+)
+
+type ImportReq struct {
+	Format Format
+	BotID  string
+}
+
+// ItemResult reports the outcome for one conversation of the input, in
+// input order.
+type ItemResult struct {
+	Index          int
+	SourceID       string
+	Title          string
+	ConversationID string
+	Status         string
+	Error          string
+	MessageCount   int
+}
+
+type ImportResp struct {
+	Format   Format
+	Imported int
+	Skipped  int
+	Failed   int
+	Items    []ItemResult
+}
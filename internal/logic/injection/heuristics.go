@@ -0,0 +1,81 @@
+package injection
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/pkg/regexp"
+)
+
+type rule struct {
+	name    string
+	pattern string
+	weight  float64
+}
+
+// builtinRules cover common phrasings of instruction overrides, role
+// hijacking, prompt extraction and chat-template smuggling.
+var builtinRules = []rule{
+	{"ignore_instructions", `\b(ignore|disregard|forget|override)\b.{0,30}\b(previous|prior|above|earlier|all|your|the)\b.{0,20}\b(instructions?|prompts?|rules|directions|guidelines)\b`, 0.6},
+	{"ignore_instructions_zh", `(忽略|无视|忘记|忘掉).{0,10}(之前|以上|前面|上述|所有).{0,6}(指令|指示|提示|规则|设定)`, 0.6},
+	{"new_instructions", `\b(new|updated|real)\s+(instructions|system\s+prompt)\b\s*:`, 0.4},
+	{"role_hijack", `\byou\s+are\s+(now|no\s+longer)\b|\bfrom\s+now\s+on,?\s+you\b|\bact\s+as\b.{0,40}\b(unrestricted|unfiltered|without\s+(any\s+)?(rules|restrictions|limits))`, 0.35},
+	{"jailbreak", `\b(jailbreak|DAN|do\s+anything\s+now|developer\s+mode|god\s+mode)\b`, 0.4},
+	{"prompt_extraction", `\b(reveal|show|print|repeat|output|leak)\b.{0,30}\b(system\s+prompt|initial\s+instructions|hidden\s+instructions|your\s+instructions)\b`, 0.5},
+	{"prompt_extraction_zh", `(输出|显示|告诉我|重复).{0,10}(系统提示|系统指令|初始指令)`, 0.5},
+	{"template_tokens", `<\|(im_start|im_end|system|endoftext)\|>|\[/?INST\]|<</?SYS>>|(?m:^\s*#{2,}\s*(system|instruction)s?\b)`, 0.6},
+	{"safety_bypass", `\b(bypass|disable|turn\s+off)\b.{0,20}\b(safety|filters?|guardrails|restrictions|moderation)\b`, 0.4},
+}
+
+// Heuristics scores text by the rules it matches. Each rule counts once and
+// weights combine as independent evidence, so several weak signals add up
+// without the score exceeding 1.
+type Heuristics struct {
+	rules   []rule
+	matcher *regexp.Matcher
+}
+
+func NewHeuristics(extra []configs.InjectionRule) (*Heuristics, error) {
+	rules := append([]rule(nil), builtinRules...)
+	for _, r := range extra {
+		if r.Name == "" || r.Pattern == "" {
+			return nil, errors.New("injection rule needs a name and a pattern")
+		}
+		if r.Weight <= 0 || r.Weight > 1 {
+			return nil, fmt.Errorf("injection rule %s: weight must be in (0, 1]", r.Name)
+		}
+		rules = append(rules, rule{name: r.Name, pattern: r.Pattern, weight: r.Weight})
+	}
+	patterns := make([]string, 0, len(rules))
+	for _, r := range rules {
+		patterns = append(patterns, r.pattern)
+	}
+	matcher, err := regexp.NewMatcher(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return &Heuristics{rules: rules, matcher: matcher}, nil
+}
+
+// Score returns the combined score and the names of the matched rules.
+func (h *Heuristics) Score(text string) (float64, []string, error) {
+	matches, err := h.matcher.FindAll(text)
+	if err != nil {
+		return 0, nil, err
+	}
+	seen := map[int]bool{}
+	var signals []string
+	clean := 1.0
+	for _, m := range matches {
+		if seen[m.Pattern] {
+			continue
+		}
+		seen[m.Pattern] = true
+		r := h.rules[m.Pattern]
+		signals = append(signals, r.name)
+		clean *= 1 - r.weight
+	}
+	return math.Round((1-clean)*100) / 100, signals, nil
+}
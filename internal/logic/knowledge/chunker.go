@@ -0,0 +1,97 @@
+package knowledge
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+)
+
+var paragraphSep = regexp.MustCompile(`\n\s*\n`)
+
+// splitText packs paragraphs into chunks of at most chunkTokens, carrying the
+// last overlap tokens of a chunk over into the next one so that a sentence
+// cut at a boundary is still retrievable from either side.
+func splitText(text string, chunkTokens, overlap int) []string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil
+	}
+	if overlap >= chunkTokens {
+		overlap = chunkTokens / 4
+	}
+
+	var pieces []string
+	for _, para := range paragraphSep.Split(text, -1) {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		pieces = append(pieces, hardSplit(para, chunkTokens)...)
+	}
+
+	var (
+		chunks []string
+		cur    string
+	)
+	for _, piece := range pieces {
+		if cur != "" && memory.EstimateTokens(cur)+memory.EstimateTokens(piece) > chunkTokens {
+			chunks = append(chunks, cur)
+			cur = tailTokens(cur, overlap)
+		}
+		if cur == "" {
+			cur = piece
+		} else {
+			cur += "\n\n" + piece
+		}
+	}
+	if strings.TrimSpace(cur) != "" {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+func hardSplit(s string, budget int) []string {
+	var out []string
+	for memory.EstimateTokens(s) > budget {
+		cut := memory.TokenPrefixLen(s, budget)
+		if i := strings.LastIndexAny(s[:cut], " \n.。!?！？;；"); i > cut/2 {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			cut = i + size
+		}
+		if cut <= 0 {
+			break
+		}
+		out = append(out, strings.TrimSpace(s[:cut]))
+		s = strings.TrimSpace(s[cut:])
+	}
+	if s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+func tailTokens(s string, budget int) string {
+	if budget <= 0 {
+		return ""
+	}
+	var ascii, wide int
+	start := len(s)
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:start])
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			wide++
+		}
+		if (ascii+3)/4+wide > budget {
+			break
+		}
+		start -= size
+	}
+	if i := strings.IndexAny(s[start:], " \n"); i >= 0 && start > 0 {
+		start += i + 1
+	}
+	return strings.TrimSpace(s[start:])
+}
@@ -0,0 +1,447 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/attachment"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/knowledge"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
+)
+
+const (
+	defaultChunkTokens  = 400
+	defaultChunkOverlap = 50
+	defaultTopK         = 4
+	defaultPageSize     = 20
+	maxPageSize         = 100
+	embedBatchSize      = 64
+	maxErrorLen         = 512
+)
+
+type logicImpl struct {
+	dao          knowledge.Dao
+	attachments  attachment.Dao
+	index        vector.Index
+	embedder     chat.Embedder
+	newID        func() int64
+	newUUID      func() string
+	chunkTokens  int
+	chunkOverlap int
+	topK         int
+	minScore     float32
+	botOwners    map[string]map[string]bool
+}
+
+func NewLogic(svcCtx *svc.Context, embedder chat.Embedder) Logic {
+	conf := svcCtx.Config.KnowledgeConf
+	l := &logicImpl{
+		dao:          svcCtx.Dao.KnowledgeDao,
+		attachments:  svcCtx.Dao.AttachmentDao,
+		index:        svcCtx.VectorIndex,
+		embedder:     embedder,
+		newID:        func() int64 { return svcCtx.Utils.SnowFlake.Generate().Int64() },
+		newUUID:      func() string { return svcCtx.Utils.UUID.New() },
+		chunkTokens:  conf.ChunkTokens,
+		chunkOverlap: conf.ChunkOverlap,
+		topK:         conf.TopK,
+		minScore:     conf.MinScore,
+		botOwners:    make(map[string]map[string]bool, len(conf.BotOwners)),
+	}
+	for botID, owners := range conf.BotOwners {
+		l.botOwners[botID] = make(map[string]bool, len(owners))
+		for _, owner := range owners {
+			l.botOwners[botID][owner] = true
+		}
+	}
+	if l.chunkTokens <= 0 {
+		l.chunkTokens = defaultChunkTokens
+	}
+	if l.chunkOverlap <= 0 {
+		l.chunkOverlap = defaultChunkOverlap
+	}
+	if l.topK <= 0 {
+		l.topK = defaultTopK
+	}
+	return l
+}
+
+func (l *logicImpl) CreateKnowledgeBase(ctx context.Context, req *CreateKnowledgeBaseReq, userID string) (*KnowledgeBaseItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	if req.BotID == "" {
+		return nil, errors.New("missing bot_id")
+	}
+	if !l.botOwners[req.BotID][userID] {
+		return nil, errors.New("forbidden")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("empty name")
+	}
+	entity := model.KnowledgeBase{
+		UUID:        l.newUUID(),
+		BotID:       req.BotID,
+		OwnerID:     userID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+	}
+	if err := l.dao.CreateKnowledgeBase(entity); err != nil {
+		return nil, err
+	}
+	created, err := l.dao.GetKnowledgeBaseByID(entity.UUID)
+	if err != nil {
+		return nil, err
+	}
+	item := toKnowledgeBaseItem(*created)
+	return &item, nil
+}
+
+func (l *logicImpl) ListKnowledgeBases(ctx context.Context, req *ListKnowledgeBasesReq, userID string) ([]KnowledgeBaseItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	items, err := l.dao.ListKnowledgeBasesByOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]KnowledgeBaseItem, 0, len(items))
+	for _, item := range items {
+		if req.BotID != "" && item.BotID != req.BotID {
+			continue
+		}
+		out = append(out, toKnowledgeBaseItem(item))
+	}
+	return out, nil
+}
+
+func (l *logicImpl) DeleteKnowledgeBase(ctx context.Context, req *DeleteKnowledgeBaseReq, userID string) error {
+	kb, err := l.ownedKnowledgeBase(req.KnowledgeBaseID, userID)
+	if err != nil {
+		return err
+	}
+	if err := l.index.DeleteNamespace(ctx, namespace(kb.UUID)); err != nil {
+		return err
+	}
+	if err := l.dao.DeleteChunksByKnowledgeBase(kb.UUID); err != nil {
+		return err
+	}
+	if err := l.dao.DeleteDocumentsByKnowledgeBase(kb.UUID); err != nil {
+		return err
+	}
+	return l.dao.DeleteKnowledgeBase(kb.UUID)
+}
+
+func (l *logicImpl) IngestDocument(ctx context.Context, req *IngestDocumentReq, userID string) (*DocumentItem, error) {
+	kb, err := l.ownedKnowledgeBase(req.KnowledgeBaseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !l.botOwners[kb.BotID][userID] {
+		return nil, errors.New("forbidden")
+	}
+	title := strings.TrimSpace(req.Title)
+	content := req.Content
+	if req.AttachmentID != "" {
+		att, err := l.attachments.GetAttachmentByID(req.AttachmentID)
+		if err != nil {
+			return nil, err
+		}
+		if att.UserID != userID {
+			return nil, errors.New("forbidden")
+		}
+		if att.Status != model.AttachmentStatusReady {
+			return nil, errors.New("attachment has no extracted text")
+		}
+		content = att.Text
+		if title == "" {
+			title = att.FileName
+		}
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("empty document")
+	}
+	if title == "" {
+		title = "Untitled"
+	}
+
+	doc := model.KnowledgeDocument{
+		UUID:            l.newUUID(),
+		KnowledgeBaseID: kb.UUID,
+		Title:           title,
+		Source:          strings.TrimSpace(req.Source),
+		AttachmentID:    req.AttachmentID,
+		Status:          model.KnowledgeDocumentStatusIndexing,
+	}
+	if err := l.dao.CreateDocument(doc); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"status": model.KnowledgeDocumentStatusReady}
+	count, err := l.indexDocument(ctx, doc, content)
+	if err != nil {
+		logger.L().Errorf("index document %s error: %v", doc.UUID, err)
+		updates = map[string]interface{}{
+			"status": model.KnowledgeDocumentStatusFailed,
+			"error":  truncate(err.Error(), maxErrorLen),
+		}
+	} else {
+		updates["chunk_count"] = count
+	}
+	if err := l.dao.UpdateDocument(doc.UUID, updates); err != nil {
+		return nil, err
+	}
+	saved, err := l.dao.GetDocumentByID(doc.UUID)
+	if err != nil {
+		return nil, err
+	}
+	item := toDocumentItem(*saved)
+	return &item, nil
+}
+
+func (l *logicImpl) indexDocument(ctx context.Context, doc model.KnowledgeDocument, content string) (int, error) {
+	pieces := splitText(content, l.chunkTokens, l.chunkOverlap)
+	if len(pieces) == 0 {
+		return 0, errors.New("no text to index")
+	}
+	chunks := make([]model.KnowledgeChunk, 0, len(pieces))
+	records := make([]vector.Record, 0, len(pieces))
+	for start := 0; start < len(pieces); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(pieces))
+		vectors, err := l.embedder.Embed(ctx, pieces[start:end])
+		if err != nil {
+			return 0, err
+		}
+		if len(vectors) != end-start {
+			return 0, errors.New("embedding count mismatch")
+		}
+		for i, v := range vectors {
+			chunk := model.KnowledgeChunk{
+				ID:              l.newID(),
+				KnowledgeBaseID: doc.KnowledgeBaseID,
+				DocumentID:      doc.UUID,
+				ChunkIndex:      start + i,
+				Content:         pieces[start+i],
+			}
+			chunks = append(chunks, chunk)
+			records = append(records, vector.Record{ID: strconv.FormatInt(chunk.ID, 10), Vector: v})
+		}
+	}
+	if err := l.dao.CreateChunks(chunks); err != nil {
+		return 0, err
+	}
+	if err := l.index.Upsert(ctx, namespace(doc.KnowledgeBaseID), records); err != nil {
+		_ = l.dao.DeleteChunksByDocument(doc.UUID)
+		return 0, err
+	}
+	return len(chunks), nil
+}
+
+func (l *logicImpl) ListDocuments(ctx context.Context, req *ListDocumentsReq, userID string) (*ListDocumentsResp, error) {
+	kb, err := l.ownedKnowledgeBase(req.KnowledgeBaseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	items, total, err := l.dao.ListDocumentsByKnowledgeBase(kb.UUID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DocumentItem, 0, len(items))
+	for _, item := range items {
+		out = append(out, toDocumentItem(item))
+	}
+	return &ListDocumentsResp{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Items:    out,
+	}, nil
+}
+
+func (l *logicImpl) DeleteDocument(ctx context.Context, req *DeleteDocumentReq, userID string) error {
+	if req.DocumentID == "" {
+		return errors.New("missing document_id")
+	}
+	doc, err := l.dao.GetDocumentByID(req.DocumentID)
+	if err != nil {
+		return err
+	}
+	if _, err := l.ownedKnowledgeBase(doc.KnowledgeBaseID, userID); err != nil {
+		return err
+	}
+	chunkIDs, err := l.dao.ListChunkIDsByDocument(doc.UUID)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(chunkIDs))
+	for _, id := range chunkIDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	if err := l.index.Delete(ctx, namespace(doc.KnowledgeBaseID), ids); err != nil {
+		return err
+	}
+	if err := l.dao.DeleteChunksByDocument(doc.UUID); err != nil {
+		return err
+	}
+	return l.dao.DeleteDocument(doc.UUID)
+}
+
+// Retrieve searches the knowledge bases bound to the bot that one of its
+// owners or the asking user created, and returns the best topK chunks above
+// the configured score threshold.
+func (l *logicImpl) Retrieve(ctx context.Context, botID, userID, query string) ([]RetrievedChunk, error) {
+	query = strings.TrimSpace(query)
+	if botID == "" || query == "" {
+		return nil, nil
+	}
+	all, err := l.dao.ListKnowledgeBasesByBot(botID)
+	if err != nil {
+		return nil, err
+	}
+	kbs := make([]model.KnowledgeBase, 0, len(all))
+	private := make(map[string]bool, len(all))
+	for _, kb := range all {
+		shared := l.botOwners[botID][kb.OwnerID]
+		if shared || kb.OwnerID == userID {
+			kbs = append(kbs, kb)
+			private[kb.UUID] = !shared
+		}
+	}
+	if len(kbs) == 0 {
+		return nil, nil
+	}
+	vectors, err := l.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, errors.New("empty query embedding")
+	}
+
+	var hits []vector.Hit
+	for _, kb := range kbs {
+		kbHits, err := l.index.Search(ctx, namespace(kb.UUID), vectors[0], l.topK)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, kbHits...)
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+
+	scores := make(map[int64]float32, l.topK)
+	ids := make([]int64, 0, l.topK)
+	for _, hit := range hits {
+		if len(ids) >= l.topK || hit.Score < l.minScore {
+			break
+		}
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		scores[id] = hit.Score
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	chunks, err := l.dao.ListChunksByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[string]string)
+	out := make([]RetrievedChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		title, ok := titles[chunk.DocumentID]
+		if !ok {
+			if doc, err := l.dao.GetDocumentByID(chunk.DocumentID); err == nil {
+				title = doc.Title
+			}
+			titles[chunk.DocumentID] = title
+		}
+		out = append(out, RetrievedChunk{
+			KnowledgeBaseID: chunk.KnowledgeBaseID,
+			DocumentID:      chunk.DocumentID,
+			DocumentTitle:   title,
+			ChunkIndex:      chunk.ChunkIndex,
+			Content:         chunk.Content,
+			Score:           scores[chunk.ID],
+			Private:         private[chunk.KnowledgeBaseID],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out, nil
+}
+
+func (l *logicImpl) ownedKnowledgeBase(kbID, userID string) (*model.KnowledgeBase, error) {
+	if kbID == "" {
+		return nil, errors.New("missing knowledge_base_id")
+	}
+	kb, err := l.dao.GetKnowledgeBaseByID(kbID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && kb.OwnerID != userID {
+		return nil, errors.New("forbidden")
+	}
+	return kb, nil
+}
+
+func namespace(kbID string) string {
+	return "kb:" + kbID
+}
+
+func toKnowledgeBaseItem(kb model.KnowledgeBase) KnowledgeBaseItem {
+	return KnowledgeBaseItem{
+		KnowledgeBaseID: kb.UUID,
+		BotID:           kb.BotID,
+		Name:            kb.Name,
+		Description:     kb.Description,
+		CreatedAt:       kb.CreatedAt,
+		UpdatedAt:       kb.UpdatedAt,
+	}
+}
+
+func toDocumentItem(doc model.KnowledgeDocument) DocumentItem {
+	return DocumentItem{
+		DocumentID:      doc.UUID,
+		KnowledgeBaseID: doc.KnowledgeBaseID,
+		Title:           doc.Title,
+		Source:          doc.Source,
+		AttachmentID:    doc.AttachmentID,
+		Status:          doc.Status,
+		Error:           doc.Error,
+		ChunkCount:      doc.ChunkCount,
+		CreatedAt:       doc.CreatedAt,
+		UpdatedAt:       doc.UpdatedAt,
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
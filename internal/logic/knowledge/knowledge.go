@@ -0,0 +1,13 @@
+package knowledge
+
+import "context"
+
+type Logic interface {
+	CreateKnowledgeBase(ctx context.Context, req *CreateKnowledgeBaseReq, userID string) (*KnowledgeBaseItem, error)
+	ListKnowledgeBases(ctx context.Context, req *ListKnowledgeBasesReq, userID string) ([]KnowledgeBaseItem, error)
+	DeleteKnowledgeBase(ctx context.Context, req *DeleteKnowledgeBaseReq, userID string) error
+	IngestDocument(ctx context.Context, req *IngestDocumentReq, userID string) (*DocumentItem, error)
+	ListDocuments(ctx context.Context, req *ListDocumentsReq, userID string) (*ListDocumentsResp, error)
+	DeleteDocument(ctx context.Context, req *DeleteDocumentReq, userID string) error
+	Retrieve(ctx context.Context, botID, userID, query string) ([]RetrievedChunk, error)
+}
@@ -0,0 +1,74 @@
+package knowledge
+
+type CreateKnowledgeBaseReq struct {
+	BotID       string
+	Name        string
+	Description string
+}
+
+type KnowledgeBaseItem struct {
+	KnowledgeBaseID string
+	BotID           string
+	Name            string
+	Description     string
+	CreatedAt       int64
+	UpdatedAt       int64
+}
+
+type ListKnowledgeBasesReq struct {
+	BotID string
+}
+
+type DeleteKnowledgeBaseReq struct {
+	KnowledgeBaseID string
+}
+
+type IngestDocumentReq struct {
+	KnowledgeBaseID string
+	Title           string
+	Source          string
+	Content         string
+	AttachmentID    string
+}
+
+type DocumentItem struct {
+	DocumentID      string
+	KnowledgeBaseID string
+	Title           string
+	Source          string
+	AttachmentID    string
+	Status          string
+	Error           string
+	ChunkCount      int
+	CreatedAt       int64
+	UpdatedAt       int64
+}
+
+type ListDocumentsReq struct {
+	KnowledgeBaseID string
+	Page            int
+	PageSize        int
+}
+
+type ListDocumentsResp struct {
+	Total    int64
+	Page     int
+	PageSize int
+	Items    []DocumentItem
+}
+
+type DeleteDocumentReq struct {
+	DocumentID string
+}
+
+type RetrievedChunk struct {
+	KnowledgeBaseID string
+	DocumentID      string
+	DocumentTitle   string
+	ChunkIndex      int
+	Content         string
+	Score           float32
+	// Private marks chunks of a knowledge base that only the asking user
+	// owns, not one of the bot's owners.
+	Private bool
+}
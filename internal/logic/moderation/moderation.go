@@ -0,0 +1,182 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+)
+
+// Action is what happens to text a moderator flags.
+type Action string
+
+const (
+	ActionNone   Action = ""
+	ActionFlag   Action = "flag"
+	ActionRedact Action = "redact"
+	ActionBlock  Action = "block"
+)
+
+const redaction = "[redacted]"
+
+// Result is the verdict on one piece of text. Spans locate the flagged
+// parts when the moderator can tell; rune offsets into the text.
+type Result struct {
+	Flagged    bool
+	Categories []string
+	Spans      []Span
+}
+
+type Span struct {
+	Index  int
+	Length int
+}
+
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (*Result, error)
+}
+
+// Chain runs every moderator and merges their results.
+func Chain(moderators ...Moderator) Moderator {
+	if len(moderators) == 1 {
+		return moderators[0]
+	}
+	return chain(moderators)
+}
+
+type chain []Moderator
+
+func (c chain) Moderate(ctx context.Context, text string) (*Result, error) {
+	out := &Result{}
+	for _, m := range c {
+		r, err := m.Moderate(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		out.Merge(r)
+	}
+	return out, nil
+}
+
+// Merge adds r's verdict to the result.
+func (r *Result) Merge(other *Result) {
+	if other == nil || !other.Flagged {
+		return
+	}
+	r.Flagged = true
+	r.Spans = append(r.Spans, other.Spans...)
+	for _, c := range other.Categories {
+		if !contains(r.Categories, c) {
+			r.Categories = append(r.Categories, c)
+		}
+	}
+}
+
+// Redact masks the flagged spans, or the whole text when the moderator
+// could not locate them.
+func Redact(text string, r *Result) string {
+	if r == nil || !r.Flagged {
+		return text
+	}
+	if len(r.Spans) == 0 {
+		return redaction
+	}
+	runes := []rune(text)
+	spans := append([]Span(nil), r.Spans...)
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Index < spans[j].Index })
+	var b strings.Builder
+	pos := 0
+	for _, s := range spans {
+		end := s.Index + s.Length
+		if end > len(runes) {
+			end = len(runes)
+		}
+		if end <= pos {
+			continue
+		}
+		if s.Index > pos {
+			b.WriteString(string(runes[pos:s.Index]))
+		}
+		b.WriteString(redaction)
+		pos = end
+	}
+	b.WriteString(string(runes[pos:]))
+	return b.String()
+}
+
+// Record is the moderation outcome stored on a message.
+type Record struct {
+	Action     Action   `json:"action"`
+	Categories []string `json:"categories,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Merge folds another outcome into the record, keeping the most severe
+// action.
+func (r *Record) Merge(other Record) {
+	if severity(other.Action) > severity(r.Action) {
+		r.Action = other.Action
+	}
+	for _, c := range other.Categories {
+		if !contains(r.Categories, c) {
+			r.Categories = append(r.Categories, c)
+		}
+	}
+	if r.Error == "" {
+		r.Error = other.Error
+	}
+}
+
+// String encodes the record for storage; an empty record encodes to "".
+func (r Record) String() string {
+	if r.Action == ActionNone && len(r.Categories) == 0 && r.Error == "" {
+		return ""
+	}
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// Policy holds the actions applied to a bot's input and output.
+type Policy struct {
+	Input  Action
+	Output Action
+}
+
+func PolicyFor(conf configs.ModerationConfig, botID string) Policy {
+	p := Policy{Input: Action(conf.InputAction), Output: Action(conf.OutputAction)}
+	if override, ok := conf.Bots[botID]; ok {
+		p = Policy{Input: Action(override.InputAction), Output: Action(override.OutputAction)}
+	}
+	return p
+}
+
+func ValidAction(a Action) bool {
+	switch a {
+	case ActionNone, ActionFlag, ActionRedact, ActionBlock:
+		return true
+	}
+	return false
+}
+
+func severity(a Action) int {
+	switch a {
+	case ActionFlag:
+		return 1
+	case ActionRedact:
+		return 2
+	case ActionBlock:
+		return 3
+	}
+	return 0
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/pkg/regexp"
+)
+
+type rule struct {
+	category string
+	matcher  *regexp.Matcher
+}
+
+// RuleModerator flags text matching configured keywords and patterns. It
+// runs locally and knows where each match is, so redaction masks only the
+// matches.
+type RuleModerator struct {
+	rules []rule
+}
+
+func NewRuleModerator(rules []configs.ModerationRule) (*RuleModerator, error) {
+	m := &RuleModerator{}
+	for i, r := range rules {
+		patterns := make([]string, 0, len(r.Keywords)+len(r.Patterns))
+		for _, k := range r.Keywords {
+			if k != "" {
+				patterns = append(patterns, regexp.QuoteMeta(k))
+			}
+		}
+		patterns = append(patterns, r.Patterns...)
+		matcher, err := regexp.NewMatcher(patterns)
+		if err != nil {
+			return nil, fmt.Errorf("moderation rule %d: %w", i, err)
+		}
+		category := r.Category
+		if category == "" {
+			category = "custom"
+		}
+		m.rules = append(m.rules, rule{category: category, matcher: matcher})
+	}
+	return m, nil
+}
+
+func (m *RuleModerator) Moderate(ctx context.Context, text string) (*Result, error) {
+	out := &Result{}
+	for _, r := range m.rules {
+		matches, err := r.matcher.FindAll(text)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		spans := make([]Span, 0, len(matches))
+		for _, match := range matches {
+			spans = append(spans, Span{Index: match.Index, Length: match.Length})
+		}
+		out.Merge(&Result{Flagged: true, Categories: []string{r.category}, Spans: spans})
+	}
+	return out, nil
+}
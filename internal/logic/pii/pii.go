@@ -0,0 +1,160 @@
+package pii
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/pkg/regexp"
+)
+
+const (
+	KindPhone    = "phone"
+	KindEmail    = "email"
+	KindIDCard   = "id_card"
+	KindBankCard = "bank_card"
+)
+
+// detector finds candidates with a loose pattern; validate, when set,
+// rejects candidates that only look like the kind.
+type detector struct {
+	kind     string
+	pattern  string
+	validate func(candidate string) (bool, error)
+}
+
+// Engine detects personal data. Detectors are listed by priority: where
+// candidates overlap, the earlier kind wins, so an ID card number is not
+// also taken for a bank card.
+type Engine struct {
+	conf      configs.PIIConfig
+	detectors []detector
+	matcher   *regexp.Matcher
+}
+
+func NewEngine(conf configs.PIIConfig, handler *regexp.Handler) (*Engine, error) {
+	detectors := []detector{
+		{
+			kind:     KindEmail,
+			pattern:  `[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`,
+			validate: handler.ValidateEmail,
+		},
+		{
+			kind:     KindIDCard,
+			pattern:  `(?<![0-9a-z])[0-9]{17}[0-9x](?![0-9a-z])`,
+			validate: handler.ValidateIDCard,
+		},
+		{
+			kind:    KindBankCard,
+			pattern: `(?<![0-9-])[0-9](?:[ -]?[0-9]){12,18}(?![0-9-])`,
+			validate: func(candidate string) (bool, error) {
+				return handler.ValidateBankCard(stripSeparators(candidate))
+			},
+		},
+		{
+			kind:    KindPhone,
+			pattern: `(?<!\d)(?:\+?86[ -]?)?1[3-9]\d(?:[ -]?\d{4}){2}(?!\d)`,
+			validate: func(candidate string) (bool, error) {
+				number := stripSeparators(strings.TrimPrefix(candidate, "+"))
+				if len(number) == 13 {
+					number = strings.TrimPrefix(number, "86")
+				}
+				return handler.ValidatePhone(number)
+			},
+		},
+	}
+	for _, c := range conf.Custom {
+		name := strings.ToLower(strings.TrimSpace(c.Name))
+		if name == "" || c.Pattern == "" {
+			return nil, errors.New("custom pii pattern needs a name and a pattern")
+		}
+		for _, d := range detectors {
+			if d.kind == name {
+				return nil, fmt.Errorf("duplicate pii kind %q", name)
+			}
+		}
+		detectors = append(detectors, detector{kind: name, pattern: c.Pattern})
+	}
+	patterns := make([]string, 0, len(detectors))
+	for _, d := range detectors {
+		patterns = append(patterns, d.pattern)
+	}
+	matcher, err := regexp.NewMatcher(patterns)
+	if err != nil {
+		return nil, err
+	}
+	e := &Engine{conf: conf, detectors: detectors, matcher: matcher}
+	for _, kinds := range append([][]string{conf.DefaultKinds}, botKinds(conf)...) {
+		for _, kind := range kinds {
+			if !e.known(kind) {
+				return nil, fmt.Errorf("unknown pii kind %q", kind)
+			}
+		}
+	}
+	return e, nil
+}
+
+// NewSession starts redacting for one request to the bot, or returns nil
+// when the bot redacts nothing.
+func (e *Engine) NewSession(botID string) *Session {
+	kinds, ok := e.conf.Bots[botID]
+	if !ok {
+		kinds = e.conf.DefaultKinds
+		if len(kinds) == 0 {
+			for _, d := range e.detectors {
+				kinds = append(kinds, d.kind)
+			}
+		}
+	}
+	return e.newSession(kinds)
+}
+
+// NewFullSession redacts every kind the engine detects. It is for text that
+// leaves the process outside any one bot's request, such as embedding
+// inputs.
+func (e *Engine) NewFullSession() *Session {
+	kinds := make([]string, 0, len(e.detectors))
+	for _, d := range e.detectors {
+		kinds = append(kinds, d.kind)
+	}
+	return e.newSession(kinds)
+}
+
+func (e *Engine) newSession(kinds []string) *Session {
+	if len(kinds) == 0 {
+		return nil
+	}
+	enabled := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		enabled[strings.ToLower(kind)] = true
+	}
+	return &Session{
+		engine:       e,
+		kinds:        enabled,
+		placeholders: map[string]string{},
+		values:       map[string]string{},
+		counts:       map[string]int{},
+	}
+}
+
+func (e *Engine) known(kind string) bool {
+	for _, d := range e.detectors {
+		if d.kind == strings.ToLower(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func botKinds(conf configs.PIIConfig) [][]string {
+	out := make([][]string, 0, len(conf.Bots))
+	for _, kinds := range conf.Bots {
+		out = append(out, kinds)
+	}
+	return out
+}
+
+func stripSeparators(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(s)
+}
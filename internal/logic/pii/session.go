@@ -0,0 +1,164 @@
+package pii
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Session redacts the prompts of one request and restores the reply. The
+// same value always gets the same placeholder, so the model can refer to
+// it consistently. A nil Session redacts nothing.
+type Session struct {
+	engine       *Engine
+	kinds        map[string]bool
+	placeholders map[string]string // kind + value -> placeholder
+	values       map[string]string // placeholder -> value
+	counts       map[string]int
+	maxLen       int
+}
+
+type span struct {
+	detector int
+	index    int
+	length   int
+}
+
+// Redact replaces personal data in text with placeholders such as
+// [PHONE_1].
+func (s *Session) Redact(text string) (string, error) {
+	if s == nil || text == "" {
+		return text, nil
+	}
+	matches, err := s.engine.matcher.FindAll(text)
+	if err != nil {
+		return "", err
+	}
+	var spans []span
+	for _, m := range matches {
+		d := s.engine.detectors[m.Pattern]
+		if !s.kinds[d.kind] {
+			continue
+		}
+		if d.validate != nil {
+			ok, err := d.validate(m.Text)
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				continue
+			}
+		}
+		spans = append(spans, span{detector: m.Pattern, index: m.Index, length: m.Length})
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].index != spans[j].index {
+			return spans[i].index < spans[j].index
+		}
+		return spans[i].detector < spans[j].detector
+	})
+
+	runes := []rune(text)
+	var b strings.Builder
+	pos := 0
+	for _, sp := range spans {
+		if sp.index < pos {
+			continue
+		}
+		end := sp.index + sp.length
+		b.WriteString(string(runes[pos:sp.index]))
+		b.WriteString(s.placeholder(s.engine.detectors[sp.detector].kind, string(runes[sp.index:end])))
+		pos = end
+	}
+	b.WriteString(string(runes[pos:]))
+	return b.String(), nil
+}
+
+func (s *Session) placeholder(kind, value string) string {
+	key := kind + "\x00" + value
+	if p, ok := s.placeholders[key]; ok {
+		return p
+	}
+	s.counts[kind]++
+	p := fmt.Sprintf("[%s_%d]", strings.ToUpper(kind), s.counts[kind])
+	s.placeholders[key] = p
+	s.values[p] = value
+	if len(p) > s.maxLen {
+		s.maxLen = len(p)
+	}
+	return p
+}
+
+// Restore puts the original values back in place of placeholders.
+func (s *Session) Restore(text string) string {
+	if s == nil || len(s.values) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, len(s.values)*2)
+	for p, v := range s.values {
+		pairs = append(pairs, p, v)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Redacted reports whether any value has been replaced.
+func (s *Session) Redacted() bool {
+	return s != nil && len(s.values) > 0
+}
+
+// Counts returns the number of distinct values redacted per kind.
+func (s *Session) Counts() map[string]int {
+	out := map[string]int{}
+	if s == nil {
+		return out
+	}
+	for kind, n := range s.counts {
+		out[kind] = n
+	}
+	return out
+}
+
+// Instruction tells the model to keep placeholders intact so they can be
+// restored in its reply.
+func (s *Session) Instruction() string {
+	if !s.Redacted() {
+		return ""
+	}
+	return "Some personal data in this conversation was replaced by placeholders such as [PHONE_1] or [EMAIL_1]. " +
+		"Repeat a placeholder exactly as written when you refer to that data, and do not guess the original value."
+}
+
+// Restorer restores placeholders in text that arrives in pieces, holding
+// back a trailing fragment that may be the start of a placeholder.
+type Restorer struct {
+	session *Session
+	pending string
+}
+
+func (s *Session) NewRestorer() *Restorer {
+	return &Restorer{session: s}
+}
+
+// Write returns the restored text that can be released so far.
+func (r *Restorer) Write(delta string) string {
+	if !r.session.Redacted() {
+		return delta
+	}
+	text := r.pending + delta
+	hold := 0
+	if i := strings.LastIndexByte(text, '['); i >= 0 && !strings.Contains(text[i:], "]") && len(text)-i < r.session.maxLen {
+		hold = len(text) - i
+	}
+	r.pending = text[len(text)-hold:]
+	return r.session.Restore(text[:len(text)-hold])
+}
+
+// Flush returns whatever is still held back.
+func (r *Restorer) Flush() string {
+	text := r.pending
+	r.pending = ""
+	return r.session.Restore(text)
+}
@@ -0,0 +1,240 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/reminder"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/cron"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxPerUser  = 100
+	defaultMinInterval = 15 * time.Minute
+	maxMessageChars    = 2000
+	localTimeLayout    = "2006-01-02 15:04"
+)
+
+type logicImpl struct {
+	dao             reminder.Dao
+	chat            chat.Dao
+	newUUID         func() string
+	now             func() time.Time
+	defaultTimezone string
+	maxPerUser      int
+	minInterval     time.Duration
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	conf := svcCtx.Config.ReminderConf
+	l := &logicImpl{
+		dao:             svcCtx.Dao.ReminderDao,
+		chat:            svcCtx.Dao.ChatDao,
+		newUUID:         func() string { return svcCtx.Utils.UUID.New() },
+		now:             time.Now,
+		defaultTimezone: conf.DefaultTimezone,
+		maxPerUser:      conf.MaxPerUser,
+		minInterval:     time.Duration(conf.MinIntervalMinutes) * time.Minute,
+	}
+	if l.defaultTimezone == "" {
+		l.defaultTimezone = "UTC"
+	}
+	if l.maxPerUser <= 0 {
+		l.maxPerUser = defaultMaxPerUser
+	}
+	if l.minInterval <= 0 {
+		l.minInterval = defaultMinInterval
+	}
+	return l
+}
+
+func (l *logicImpl) CreateReminder(ctx context.Context, req *CreateReminderReq, userID string) (*ReminderItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return nil, errors.New("missing message")
+	}
+	if utf8.RuneCountInString(message) > maxMessageChars {
+		return nil, fmt.Errorf("message too long (max %d chars)", maxMessageChars)
+	}
+	if err := l.checkMember(req.ConversationID, userID); err != nil {
+		return nil, err
+	}
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone == "" {
+		timezone = l.defaultTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.New("invalid timezone")
+	}
+	entity := model.Reminder{
+		UUID:           l.newUUID(),
+		UserID:         userID,
+		ConversationID: req.ConversationID,
+		Message:        message,
+		Timezone:       timezone,
+		Status:         model.ReminderStatusActive,
+	}
+	now := l.now()
+	if err := schedule(&entity, req, loc, now, l.minInterval); err != nil {
+		return nil, err
+	}
+	count, err := l.dao.CountActiveReminders(userID)
+	if err != nil {
+		return nil, err
+	}
+	if int(count) >= l.maxPerUser {
+		return nil, fmt.Errorf("too many reminders (max %d)", l.maxPerUser)
+	}
+	if err := l.dao.CreateReminder(entity); err != nil {
+		return nil, err
+	}
+	entity.CreatedAt = now.Unix()
+	item := toReminderItem(entity)
+	return &item, nil
+}
+
+// schedule sets the kind and first run of a new reminder. A cron reminder
+// whose next two runs are closer than minInterval is rejected.
+func schedule(entity *model.Reminder, req *CreateReminderReq, loc *time.Location, now time.Time, minInterval time.Duration) error {
+	set := 0
+	for _, ok := range []bool{req.RunAt != 0, req.At != "", req.Cron != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of run_at, at and cron is required")
+	}
+	switch {
+	case req.Cron != "":
+		next, err := NextRun(req.Cron, entity.Timezone, now)
+		if err != nil {
+			return err
+		}
+		if following, err := NextRun(req.Cron, entity.Timezone, next); err == nil && following.Sub(next) < minInterval {
+			return fmt.Errorf("cron fires too often (min interval %s)", minInterval)
+		}
+		entity.Kind = model.ReminderKindCron
+		entity.CronExpr = strings.Join(strings.Fields(req.Cron), " ")
+		entity.NextRunAt = next.Unix()
+		return nil
+	case req.At != "":
+		at, err := time.ParseInLocation(localTimeLayout, strings.TrimSpace(req.At), loc)
+		if err != nil {
+			return errors.New("invalid at, want YYYY-MM-DD HH:MM")
+		}
+		entity.NextRunAt = at.Unix()
+	default:
+		entity.NextRunAt = req.RunAt
+	}
+	if entity.NextRunAt <= now.Unix() {
+		return errors.New("reminder time is in the past")
+	}
+	entity.Kind = model.ReminderKindOnce
+	return nil
+}
+
+// NextRun returns the first run of a cron reminder after t, evaluating the
+// expression in the reminder's timezone.
+func NextRun(expr, timezone string, after time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, errors.New("invalid timezone")
+	}
+	s, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.Next(after.In(loc))
+}
+
+func (l *logicImpl) ListReminders(ctx context.Context, req *ListRemindersReq, userID string) ([]ReminderItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	items, err := l.dao.ListReminders(userID, req.ConversationID, !req.IncludeInactive)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ReminderItem, 0, len(items))
+	for _, item := range items {
+		out = append(out, toReminderItem(item))
+	}
+	return out, nil
+}
+
+func (l *logicImpl) CancelReminder(ctx context.Context, req *CancelReminderReq, userID string) error {
+	if userID == "" {
+		return errors.New("missing user")
+	}
+	if req.ReminderID == "" {
+		return errors.New("missing reminder_id")
+	}
+	entity, err := l.dao.GetReminder(req.ReminderID)
+	if err != nil {
+		return err
+	}
+	if entity.UserID != userID {
+		return errors.New("forbidden")
+	}
+	if entity.Status != model.ReminderStatusActive {
+		return nil
+	}
+	return l.dao.UpdateReminder(entity.UUID, map[string]interface{}{
+		"status": model.ReminderStatusCancelled,
+	})
+}
+
+// checkMember allows the conversation's creator and, in groups, its
+// participants.
+func (l *logicImpl) checkMember(conversationID, userID string) error {
+	if conversationID == "" {
+		return errors.New("missing conversation_id")
+	}
+	conversation, err := l.chat.GetConversationByID(conversationID)
+	if err != nil {
+		return err
+	}
+	if conversation.UserID == userID {
+		return nil
+	}
+	if conversation.Kind == model.ConversationKindGroup {
+		_, err := l.chat.GetParticipant(conversationID, userID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	return errors.New("forbidden")
+}
+
+func toReminderItem(entity model.Reminder) ReminderItem {
+	return ReminderItem{
+		ReminderID:     entity.UUID,
+		ConversationID: entity.ConversationID,
+		Message:        entity.Message,
+		Kind:           entity.Kind,
+		Cron:           entity.CronExpr,
+		Timezone:       entity.Timezone,
+		Status:         entity.Status,
+		NextRunAt:      entity.NextRunAt,
+		LastRunAt:      entity.LastRunAt,
+		RunCount:       entity.RunCount,
+		CreatedAt:      entity.CreatedAt,
+	}
+}
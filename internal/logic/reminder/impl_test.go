@@ -0,0 +1,38 @@
+package reminder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+func TestScheduleMinInterval(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cron    string
+		wantErr bool
+	}{
+		{cron: "* * * * *", wantErr: true},
+		{cron: "*/5 * * * *", wantErr: true},
+		{cron: "0,10 9 * * *", wantErr: true},
+		{cron: "*/15 * * * *"},
+		{cron: "0 9 * * 1-5"},
+	}
+	for _, tt := range tests {
+		entity := model.Reminder{Timezone: "UTC"}
+		err := schedule(&entity, &CreateReminderReq{Cron: tt.cron}, time.UTC, now, 15*time.Minute)
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "too often") {
+				t.Errorf("%q: err = %v, want the interval rejected", tt.cron, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.cron, err)
+		} else if entity.Kind != model.ReminderKindCron || entity.NextRunAt <= now.Unix() {
+			t.Errorf("%q: scheduled %+v, want a future cron run", tt.cron, entity)
+		}
+	}
+}
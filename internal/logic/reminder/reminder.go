@@ -0,0 +1,12 @@
+package reminder
+
+import "context"
+
+// Logic schedules messages the bot posts into a conversation later, once
+// or on a cron schedule. Any member of the conversation may create them;
+// each user sees and cancels only their own.
+type Logic interface {
+	CreateReminder(ctx context.Context, req *CreateReminderReq, userID string) (*ReminderItem, error)
+	ListReminders(ctx context.Context, req *ListRemindersReq, userID string) ([]ReminderItem, error)
+	CancelReminder(ctx context.Context, req *CancelReminderReq, userID string) error
+}
@@ -0,0 +1,40 @@
+package reminder
+
+// CreateReminderReq schedules Message by exactly one of RunAt, At and Cron.
+type CreateReminderReq struct {
+	ConversationID string
+	Message        string
+	// RunAt fires once at this unix time.
+	RunAt int64
+	// At fires once at a wall-clock time, "2006-01-02 15:04", in Timezone.
+	At string
+	// Cron repeats on a five-field cron expression evaluated in Timezone.
+	Cron string
+	// Timezone is an IANA name such as "Asia/Shanghai"; empty uses the
+	// configured default.
+	Timezone string
+}
+
+type ListRemindersReq struct {
+	ConversationID string
+	// IncludeInactive adds reminders that are done or cancelled.
+	IncludeInactive bool
+}
+
+type CancelReminderReq struct {
+	ReminderID string
+}
+
+type ReminderItem struct {
+	ReminderID     string
+	ConversationID string
+	Message        string
+	Kind           string
+	Cron           string
+	Timezone       string
+	Status         string
+	NextRunAt      int64
+	LastRunAt      int64
+	RunCount       int
+	CreatedAt      int64
+}
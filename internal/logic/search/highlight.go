@@ -0,0 +1,110 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	snippetRunes = 160
+	markOpen     = "<mark>"
+	markClose    = "</mark>"
+)
+
+// highlight cuts a snippet of content around the first query term and wraps
+// every term occurrence in <mark>. Text outside the marks is HTML-escaped so
+// clients can render the snippet as-is.
+func highlight(content, query string) string {
+	text := []rune(content)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	terms := queryTerms(query)
+
+	first := -1
+	for _, term := range terms {
+		if i := indexRunes(lower, term, 0); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	start := 0
+	if first > snippetRunes/3 {
+		start = first - snippetRunes/3
+	}
+	end := min(len(text), start+snippetRunes)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	plain := start
+	for i := start; i < end; {
+		n := matchAt(lower, terms, i)
+		if n == 0 {
+			i++
+			continue
+		}
+		b.WriteString(html.EscapeString(string(text[plain:i])))
+		b.WriteString(markOpen)
+		b.WriteString(html.EscapeString(string(text[i : i+n])))
+		b.WriteString(markClose)
+		i += n
+		plain = i
+	}
+	if plain < end {
+		b.WriteString(html.EscapeString(string(text[plain:end])))
+	}
+	if max(plain, end) < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// queryTerms splits the query on whitespace, longest term first so that
+// overlapping terms mark the longer match.
+func queryTerms(query string) [][]rune {
+	seen := make(map[string]struct{})
+	var terms [][]rune
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if _, ok := seen[field]; ok {
+			continue
+		}
+		seen[field] = struct{}{}
+		terms = append(terms, []rune(field))
+	}
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	return terms
+}
+
+func matchAt(text []rune, terms [][]rune, pos int) int {
+	for _, term := range terms {
+		if hasPrefixAt(text, term, pos) {
+			return len(term)
+		}
+	}
+	return 0
+}
+
+func indexRunes(text, term []rune, from int) int {
+	for i := from; i+len(term) <= len(text); i++ {
+		if hasPrefixAt(text, term, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasPrefixAt(text, term []rune, pos int) bool {
+	if len(term) == 0 || pos+len(term) > len(text) {
+		return false
+	}
+	for j, r := range term {
+		if text[pos+j] != r {
+			return false
+		}
+	}
+	return true
+}
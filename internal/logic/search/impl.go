@@ -0,0 +1,220 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	chatdao "github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
+)
+
+const (
+	defaultLimit   = 20
+	maxLimit       = 50
+	maxIndexTokens = 2000
+)
+
+type logicImpl struct {
+	dao      chatdao.Dao
+	index    vector.Index
+	embedder chat.Embedder
+	semantic bool
+	minScore float32
+}
+
+func NewLogic(svcCtx *svc.Context, embedder chat.Embedder) Logic {
+	return &logicImpl{
+		dao:      svcCtx.Dao.ChatDao,
+		index:    svcCtx.VectorIndex,
+		embedder: embedder,
+		semantic: svcCtx.Config.SearchConf.Semantic,
+		minScore: svcCtx.Config.SearchConf.MinScore,
+	}
+}
+
+// Namespace holds the message embeddings visible to one user: a message is
+// indexed for every member of its conversation, scoped by conversation ID.
+// Semantic search ranks only the caller's namespace and, within it, the
+// conversations the caller still belongs to. Members who join a group later
+// find its earlier messages through keyword search only.
+func Namespace(userID string) string {
+	return "msg:" + userID
+}
+
+func (l *logicImpl) Search(ctx context.Context, req *SearchReq, userID string) (*SearchResp, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, errors.New("empty query")
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	var (
+		hits []Hit
+		err  error
+	)
+	switch req.Mode {
+	case "", ModeKeyword:
+		hits, err = l.keywordSearch(userID, query, limit)
+	case ModeSemantic:
+		if !l.semantic {
+			return nil, errors.New("semantic search disabled")
+		}
+		hits, err = l.semanticSearch(ctx, userID, query, limit)
+	default:
+		return nil, fmt.Errorf("unknown search mode %s", req.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResp{Items: hits}, nil
+}
+
+func (l *logicImpl) keywordSearch(userID, query string, limit int) ([]Hit, error) {
+	messages, err := l.dao.SearchMessages(userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	titles, err := l.dao.SearchConversationTitles(userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]Hit, 0, len(messages)+len(titles))
+	for _, m := range messages {
+		hits = append(hits, toHit(m, query, float32(m.Score)))
+	}
+	for _, c := range titles {
+		hits = append(hits, Hit{
+			ConversationID:    c.UUID,
+			ConversationTitle: c.Title,
+			Snippet:           highlight(c.Title, query),
+			Score:             float32(c.Score),
+			CreatedAt:         c.CreatedAt,
+		})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func (l *logicImpl) semanticSearch(ctx context.Context, userID, query string, limit int) ([]Hit, error) {
+	vectors, err := l.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, errors.New("empty query embedding")
+	}
+	conversationIDs, err := l.dao.ListMemberConversationIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+	matches, err := l.index.Search(ctx, Namespace(userID), vectors[0], limit, conversationIDs...)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int64]float32, len(matches))
+	ids := make([]int64, 0, len(matches))
+	for _, match := range matches {
+		if match.Score < l.minScore {
+			break
+		}
+		id, err := strconv.ParseInt(match.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		scores[id] = match.Score
+		ids = append(ids, id)
+	}
+	// Vectors of cleared or deleted messages are not removed eagerly, and a
+	// member may have left since; the lookup below only returns live
+	// messages of the user's live conversations, which drops them.
+	messages, err := l.dao.ListUserMessagesByIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]Hit, 0, len(messages))
+	for _, m := range messages {
+		hits = append(hits, toHit(m, query, scores[m.ID]))
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits, nil
+}
+
+func (l *logicImpl) IndexMessages(ctx context.Context, messages ...model.Message) error {
+	if !l.semantic {
+		return nil
+	}
+	inputs := make([]string, 0, len(messages))
+	indexed := make([]model.Message, 0, len(messages))
+	for _, m := range messages {
+		content := strings.TrimSpace(m.Content)
+		if m.ID == 0 || m.ConversationID == "" || m.IsSummary || content == "" {
+			continue
+		}
+		inputs = append(inputs, memory.TruncateToTokens(content, maxIndexTokens))
+		indexed = append(indexed, m)
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+	vectors, err := l.embedder.Embed(ctx, inputs)
+	if err != nil {
+		return err
+	}
+	records := make(map[string][]vector.Record)
+	for i, v := range vectors {
+		m := indexed[i]
+		records[m.ConversationID] = append(records[m.ConversationID], vector.Record{
+			ID:     strconv.FormatInt(m.ID, 10),
+			Vector: v,
+			Scope:  m.ConversationID,
+		})
+	}
+	for conversationID, batch := range records {
+		members, err := l.dao.ListConversationMemberIDs(conversationID)
+		if err != nil {
+			return err
+		}
+		for _, userID := range members {
+			if err := l.index.Upsert(ctx, Namespace(userID), batch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func toHit(m chatdao.MessageMatch, query string, score float32) Hit {
+	return Hit{
+		ConversationID:    m.ConversationID,
+		ConversationTitle: m.Title,
+		MessageID:         m.ID,
+		Sequence:          m.Sequence,
+		Role:              m.Role,
+		Snippet:           highlight(m.Content, query),
+		Score:             score,
+		CreatedAt:         m.CreatedAt,
+	}
+}
@@ -0,0 +1,12 @@
+package search
+
+import (
+	"context"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+)
+
+type Logic interface {
+	Search(ctx context.Context, req *SearchReq, userID string) (*SearchResp, error)
+	IndexMessages(ctx context.Context, messages ...model.Message) error
+}
@@ -0,0 +1,31 @@
+package search
+
+type Mode string
+
+const (
+	ModeKeyword  Mode = "keyword"
+	ModeSemantic Mode = "semantic"
+)
+
+type SearchReq struct {
+	Query string
+	Mode  Mode
+	Limit int
+}
+
+// Hit is a single search result. Hits on a conversation title carry no
+// message, so MessageID and Sequence are zero.
+type Hit struct {
+	ConversationID    string
+	ConversationTitle string
+	MessageID         int64
+	Sequence          int64
+	Role              string
+	Snippet           string
+	Score             float32
+	CreatedAt         int64
+}
+
+type SearchResp struct {
+	Items []Hit
+}
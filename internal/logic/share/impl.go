@@ -0,0 +1,245 @@
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/chat"
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/share"
+	"github.com/im-core-go/im-core-bot-platform/internal/logic/chat/memory"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+
+	"gorm.io/gorm"
+)
+
+const (
+	messageBatch = 200
+	maxMessages  = 5000
+	tokenBytes   = 32
+)
+
+var (
+	// ErrNotFound is returned for unknown, expired and revoked tokens alike
+	// so callers cannot tell which, and for unknown shares and
+	// conversations.
+	ErrNotFound = errors.New("share not found")
+	// ErrForbidden is returned when the share or conversation belongs to
+	// another user.
+	ErrForbidden = errors.New("forbidden")
+)
+
+type logicImpl struct {
+	dao      share.Dao
+	chat     chat.Dao
+	blobURLs *blob.URLSigner
+	newID    func() int64
+	newUUID  func() string
+	now      func() time.Time
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	return &logicImpl{
+		dao:      svcCtx.Dao.ShareDao,
+		chat:     svcCtx.Dao.ChatDao,
+		blobURLs: svcCtx.BlobURLs,
+		newID:    func() int64 { return svcCtx.Utils.SnowFlake.Generate().Int64() },
+		newUUID:  func() string { return svcCtx.Utils.UUID.New() },
+		now:      time.Now,
+	}
+}
+
+// ShareConversation copies the conversation as it is now into a share. Only
+// what a reader of the chat would see is copied: summaries, reasoning,
+// message meta and attachments stay private.
+func (l *logicImpl) ShareConversation(ctx context.Context, req *ShareConversationReq, userID string) (*ShareItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	if req.ConversationID == "" {
+		return nil, errors.New("missing conversation_id")
+	}
+	if req.ExpiresInSeconds < 0 {
+		return nil, errors.New("invalid expires_in_seconds")
+	}
+	conversation, err := l.chat.GetConversationByID(req.ConversationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if conversation.UserID != userID {
+		return nil, ErrForbidden
+	}
+
+	entity := model.Share{
+		UUID:           l.newUUID(),
+		ConversationID: conversation.UUID,
+		UserID:         userID,
+		Title:          conversation.Title,
+	}
+	messages, err := l.snapshot(ctx, entity.UUID, conversation.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("conversation has no messages")
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	now := l.now().Unix()
+	entity.Token = token
+	entity.MessageCount = len(messages)
+	entity.CreatedAt = now
+	entity.UpdatedAt = now
+	if req.ExpiresInSeconds > 0 {
+		entity.ExpiresAt = now + req.ExpiresInSeconds
+	}
+	if err := l.dao.CreateShare(entity, messages); err != nil {
+		return nil, err
+	}
+	item := toShareItem(entity)
+	return &item, nil
+}
+
+func (l *logicImpl) snapshot(ctx context.Context, shareID, conversationID string) ([]model.ShareMessage, error) {
+	var (
+		out   []model.ShareMessage
+		after int64
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		messages, err := l.chat.ListMessagesAfterSequence(conversationID, after, messageBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range messages {
+			if msg.IsSummary || (msg.Role != "user" && msg.Role != "assistant") {
+				continue
+			}
+			if len(out) == maxMessages {
+				return nil, fmt.Errorf("conversation exceeds %d messages", maxMessages)
+			}
+			out = append(out, model.ShareMessage{
+				ID:          l.newID(),
+				ShareID:     shareID,
+				Position:    len(out),
+				Role:        msg.Role,
+				ContentType: msg.ContentType,
+				Content:     msg.Content,
+				Images:      msg.Images,
+				CreatedAt:   msg.CreatedAt,
+			})
+		}
+		if len(messages) < messageBatch {
+			return out, nil
+		}
+		after = messages[len(messages)-1].Sequence
+	}
+}
+
+func (l *logicImpl) ListShares(ctx context.Context, req *ListSharesReq, userID string) ([]ShareItem, error) {
+	if userID == "" {
+		return nil, errors.New("missing user")
+	}
+	items, err := l.dao.ListSharesByUser(userID, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ShareItem, 0, len(items))
+	for _, item := range items {
+		out = append(out, toShareItem(item))
+	}
+	return out, nil
+}
+
+func (l *logicImpl) RevokeShare(ctx context.Context, req *RevokeShareReq, userID string) error {
+	if userID == "" {
+		return errors.New("missing user")
+	}
+	if req.ShareID == "" {
+		return errors.New("missing share_id")
+	}
+	entity, err := l.dao.GetShareByID(req.ShareID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if entity.UserID != userID {
+		return ErrForbidden
+	}
+	return l.dao.RevokeShare(entity.UUID, l.now().Unix())
+}
+
+func (l *logicImpl) GetSharedConversation(ctx context.Context, req *GetSharedConversationReq) (*SharedConversation, error) {
+	if req.Token == "" {
+		return nil, ErrNotFound
+	}
+	entity, err := l.dao.GetShareByToken(req.Token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if entity.RevokedAt > 0 || (entity.ExpiresAt > 0 && entity.ExpiresAt <= l.now().Unix()) {
+		return nil, ErrNotFound
+	}
+	messages, err := l.dao.ListShareMessages(entity.UUID)
+	if err != nil {
+		return nil, err
+	}
+	out := &SharedConversation{
+		Title:     entity.Title,
+		SharedAt:  entity.CreatedAt,
+		ExpiresAt: entity.ExpiresAt,
+		Messages:  make([]SharedMessage, 0, len(messages)),
+	}
+	for _, msg := range messages {
+		item := SharedMessage{
+			Role:        msg.Role,
+			ContentType: msg.ContentType,
+			Content:     msg.Content,
+			CreatedAt:   msg.CreatedAt,
+		}
+		images := memory.DecodeImages(model.Message{Images: msg.Images})
+		for _, img := range memory.SignImages(images, l.blobURLs) {
+			item.Images = append(item.Images, SharedImage{URL: img.URL, MimeType: img.MimeType})
+		}
+		out.Messages = append(out.Messages, item)
+	}
+	return out, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func toShareItem(entity model.Share) ShareItem {
+	return ShareItem{
+		ShareID:        entity.UUID,
+		ConversationID: entity.ConversationID,
+		Token:          entity.Token,
+		Title:          entity.Title,
+		MessageCount:   entity.MessageCount,
+		ExpiresAt:      entity.ExpiresAt,
+		RevokedAt:      entity.RevokedAt,
+		CreatedAt:      entity.CreatedAt,
+	}
+}
@@ -0,0 +1,12 @@
+package share
+
+import "context"
+
+type Logic interface {
+	ShareConversation(ctx context.Context, req *ShareConversationReq, userID string) (*ShareItem, error)
+	ListShares(ctx context.Context, req *ListSharesReq, userID string) ([]ShareItem, error)
+	RevokeShare(ctx context.Context, req *RevokeShareReq, userID string) error
+	// GetSharedConversation is public: it takes no user and returns nothing
+	// that identifies the owner.
+	GetSharedConversation(ctx context.Context, req *GetSharedConversationReq) (*SharedConversation, error)
+}
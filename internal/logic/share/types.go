@@ -0,0 +1,50 @@
+package share
+
+type ShareConversationReq struct {
+	ConversationID string
+	// ExpiresInSeconds of 0 keeps the share until it is revoked.
+	ExpiresInSeconds int64
+}
+
+type ListSharesReq struct {
+	ConversationID string
+}
+
+type RevokeShareReq struct {
+	ShareID string
+}
+
+type GetSharedConversationReq struct {
+	Token string
+}
+
+type ShareItem struct {
+	ShareID        string
+	ConversationID string
+	Token          string
+	Title          string
+	MessageCount   int
+	ExpiresAt      int64
+	RevokedAt      int64
+	CreatedAt      int64
+}
+
+type SharedConversation struct {
+	Title     string
+	SharedAt  int64
+	ExpiresAt int64
+	Messages  []SharedMessage
+}
+
+type SharedMessage struct {
+	Role        string
+	ContentType string
+	Content     string
+	Images      []SharedImage
+	CreatedAt   int64
+}
+
+type SharedImage struct {
+	URL      string
+	MimeType string
+}
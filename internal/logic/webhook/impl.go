@@ -0,0 +1,241 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/im-core-go/im-core-bot-platform/internal/dao/webhook"
+	"github.com/im-core-go/im-core-bot-platform/internal/model"
+	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	signer "github.com/im-core-go/im-core-bot-platform/pkg/webhook"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var eventTypes = map[string]bool{
+	model.WebhookConversationCreated:        true,
+	model.WebhookConversationTitleGenerated: true,
+	model.WebhookConversationDeleted:        true,
+	model.WebhookMessageCompleted:           true,
+	model.WebhookReminderFired:              true,
+}
+
+var deliveryStatuses = map[string]bool{
+	model.WebhookDeliveryPending:   true,
+	model.WebhookDeliveryDelivered: true,
+	model.WebhookDeliveryDead:      true,
+}
+
+type logicImpl struct {
+	dao     webhook.Dao
+	admins  map[string]bool
+	newUUID func() string
+	now     func() time.Time
+}
+
+func NewLogic(svcCtx *svc.Context) Logic {
+	admins := make(map[string]bool, len(svcCtx.Config.WebhookConf.AdminUserIDs))
+	for _, id := range svcCtx.Config.WebhookConf.AdminUserIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = true
+		}
+	}
+	return &logicImpl{
+		dao:     svcCtx.Dao.WebhookDao,
+		admins:  admins,
+		newUUID: func() string { return svcCtx.Utils.UUID.New() },
+		now:     time.Now,
+	}
+}
+
+func (l *logicImpl) authorize(userID string) error {
+	if userID == "" {
+		return errors.New("missing user")
+	}
+	if !l.admins[userID] {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+func (l *logicImpl) CreateWebhook(ctx context.Context, req *CreateWebhookReq, userID string) (*WebhookItem, error) {
+	if err := l.authorize(userID); err != nil {
+		return nil, err
+	}
+	target := strings.TrimSpace(req.URL)
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("invalid url")
+	}
+	types := make([]string, 0, len(req.EventTypes))
+	seen := make(map[string]bool, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		t = strings.TrimSpace(t)
+		if !eventTypes[t] {
+			return nil, errors.New("invalid event type: " + t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	secret, err := signer.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	entity := model.WebhookSubscription{
+		UUID:    l.newUUID(),
+		OwnerID: userID,
+		BotID:   strings.TrimSpace(req.BotID),
+		URL:     target,
+		Secret:  secret,
+		Enabled: true,
+	}
+	if len(types) > 0 {
+		b, _ := json.Marshal(types)
+		s := string(b)
+		entity.EventTypes = &s
+	}
+	if err := l.dao.CreateSubscription(entity); err != nil {
+		return nil, err
+	}
+	item := toWebhookItem(entity)
+	item.Secret = secret
+	item.CreatedAt = l.now().Unix()
+	return &item, nil
+}
+
+func (l *logicImpl) ListWebhooks(ctx context.Context, userID string) ([]WebhookItem, error) {
+	if err := l.authorize(userID); err != nil {
+		return nil, err
+	}
+	items, err := l.dao.ListSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]WebhookItem, 0, len(items))
+	for _, item := range items {
+		out = append(out, toWebhookItem(item))
+	}
+	return out, nil
+}
+
+// DeleteWebhook stops new deliveries at once; deliveries already queued
+// are dead-lettered by the worker when it finds the subscription gone.
+func (l *logicImpl) DeleteWebhook(ctx context.Context, req *DeleteWebhookReq, userID string) error {
+	if err := l.authorize(userID); err != nil {
+		return err
+	}
+	if req.WebhookID == "" {
+		return errors.New("missing webhook_id")
+	}
+	if _, err := l.dao.GetSubscription(req.WebhookID); err != nil {
+		return err
+	}
+	return l.dao.DeleteSubscription(req.WebhookID)
+}
+
+func (l *logicImpl) ListDeliveries(ctx context.Context, req *ListDeliveriesReq, userID string) (*ListDeliveriesResp, error) {
+	if err := l.authorize(userID); err != nil {
+		return nil, err
+	}
+	if req.Status != "" && !deliveryStatuses[req.Status] {
+		return nil, errors.New("invalid status")
+	}
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	filter := webhook.DeliveryFilter{SubscriptionID: req.WebhookID, Status: req.Status}
+	items, total, err := l.dao.ListDeliveries(filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DeliveryItem, 0, len(items))
+	for _, item := range items {
+		out = append(out, toDeliveryItem(item))
+	}
+	return &ListDeliveriesResp{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Items:    out,
+	}, nil
+}
+
+func (l *logicImpl) ReplayDelivery(ctx context.Context, req *ReplayDeliveryReq, userID string) (*DeliveryItem, error) {
+	if err := l.authorize(userID); err != nil {
+		return nil, err
+	}
+	if req.DeliveryID == 0 {
+		return nil, errors.New("missing delivery_id")
+	}
+	delivery, err := l.dao.GetDelivery(req.DeliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.dao.GetSubscription(delivery.SubscriptionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook deleted")
+		}
+		return nil, err
+	}
+	fields := map[string]interface{}{
+		"status":          model.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": l.now().Unix(),
+		"last_error":      "",
+	}
+	if err := l.dao.UpdateDelivery(delivery.ID, fields); err != nil {
+		return nil, err
+	}
+	delivery, err = l.dao.GetDelivery(delivery.ID)
+	if err != nil {
+		return nil, err
+	}
+	item := toDeliveryItem(*delivery)
+	return &item, nil
+}
+
+func toWebhookItem(entity model.WebhookSubscription) WebhookItem {
+	return WebhookItem{
+		WebhookID:  entity.UUID,
+		BotID:      entity.BotID,
+		URL:        entity.URL,
+		EventTypes: entity.Types(),
+		Enabled:    entity.Enabled,
+		CreatedAt:  entity.CreatedAt,
+	}
+}
+
+func toDeliveryItem(entity model.WebhookDelivery) DeliveryItem {
+	return DeliveryItem{
+		DeliveryID:     entity.ID,
+		WebhookID:      entity.SubscriptionID,
+		EventID:        entity.EventID,
+		EventType:      entity.EventType,
+		Status:         entity.Status,
+		Attempts:       entity.Attempts,
+		NextAttemptAt:  entity.NextAttemptAt,
+		LastStatusCode: entity.LastStatusCode,
+		LastError:      entity.LastError,
+		DeliveredAt:    entity.DeliveredAt,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
@@ -0,0 +1,57 @@
+package webhook
+
+type CreateWebhookReq struct {
+	// BotID limits the webhook to one bot; empty subscribes to all bots.
+	BotID string
+	URL   string
+	// EventTypes filters events; empty subscribes to all types.
+	EventTypes []string
+}
+
+type DeleteWebhookReq struct {
+	WebhookID string
+}
+
+type ListDeliveriesReq struct {
+	WebhookID string
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+type ReplayDeliveryReq struct {
+	DeliveryID int64
+}
+
+type WebhookItem struct {
+	WebhookID  string
+	BotID      string
+	URL        string
+	EventTypes []string
+	Enabled    bool
+	// Secret is only set in the response to CreateWebhook.
+	Secret    string
+	CreatedAt int64
+}
+
+type ListDeliveriesResp struct {
+	Total    int64
+	Page     int
+	PageSize int
+	Items    []DeliveryItem
+}
+
+type DeliveryItem struct {
+	DeliveryID     int64
+	WebhookID      string
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int
+	NextAttemptAt  int64
+	LastStatusCode int
+	LastError      string
+	DeliveredAt    int64
+	CreatedAt      int64
+	UpdatedAt      int64
+}
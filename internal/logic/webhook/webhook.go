@@ -0,0 +1,17 @@
+package webhook
+
+import "context"
+
+// Logic manages webhook subscriptions and their deliveries. Subscriptions
+// receive every user's events, so every method is limited to the admins
+// listed in the webhook config.
+type Logic interface {
+	// CreateWebhook returns the signing secret; it is not shown again.
+	CreateWebhook(ctx context.Context, req *CreateWebhookReq, userID string) (*WebhookItem, error)
+	ListWebhooks(ctx context.Context, userID string) ([]WebhookItem, error)
+	DeleteWebhook(ctx context.Context, req *DeleteWebhookReq, userID string) error
+	ListDeliveries(ctx context.Context, req *ListDeliveriesReq, userID string) (*ListDeliveriesResp, error)
+	// ReplayDelivery queues the delivery to be sent again now, whatever its
+	// current status, with a fresh attempt budget.
+	ReplayDelivery(ctx context.Context, req *ReplayDeliveryReq, userID string) (*DeliveryItem, error)
+}
@@ -0,0 +1,24 @@
+package model
+
+type Attachment struct {
+	UUID           string `gorm:"primaryKey;type:varchar(36)"`
+	UserID         string `gorm:"column:user_id;index;type:varchar(36)"`
+	ConversationID string `gorm:"column:conversation_id;index;type:varchar(36)"`
+	MessageID      int64  `gorm:"column:message_id;index"`
+	FileName       string `gorm:"column:file_name;type:varchar(255)"`
+	MimeType       string `gorm:"column:mime_type;type:varchar(128)"`
+	Size           int64  `gorm:"column:size"`
+	BlobKey        string `gorm:"column:blob_key;type:varchar(512)"`
+	Status         string `gorm:"column:status;type:varchar(16)"`
+	Error          string `gorm:"column:error;type:varchar(512)"`
+	Text           string `gorm:"column:text;type:mediumtext"`
+	CommonPartNoUnique
+}
+
+func (Attachment) TableName() string { return "attachment" }
+
+const (
+	AttachmentStatusReady       = "ready"
+	AttachmentStatusFailed      = "failed"
+	AttachmentStatusUnsupported = "unsupported"
+)
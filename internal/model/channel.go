@@ -0,0 +1,16 @@
+package model
+
+// ChannelBinding maps an IM channel, or a thread within it, to the bot
+// conversation that holds its history. An empty ThreadID is the channel's
+// main timeline.
+type ChannelBinding struct {
+	ID             int64  `gorm:"primaryKey"`
+	Platform       string `gorm:"column:platform;uniqueIndex:uk_channel_binding;type:varchar(32)"`
+	ChannelID      string `gorm:"column:channel_id;uniqueIndex:uk_channel_binding;type:varchar(128)"`
+	ThreadID       string `gorm:"column:thread_id;uniqueIndex:uk_channel_binding;type:varchar(128)"`
+	BotID          string `gorm:"column:bot_id;uniqueIndex:uk_channel_binding;type:varchar(64)"`
+	ConversationID string `gorm:"column:conversation_id;index;type:varchar(36)"`
+	CreatedAt      int64  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ChannelBinding) TableName() string { return "im_channel_binding" }
@@ -1,22 +1,51 @@
 package model
 
 type Conversation struct {
-	UUID   string `gorm:"primaryKey;type:varchar(36)"`
-	UserID string `gorm:"column:user_id;index;type:varchar(36)"`
-	Title  string `gorm:"column:title;type:varchar(255);default:'New'"`
+	UUID     string `gorm:"primaryKey;type:varchar(36)"`
+	UserID   string `gorm:"column:user_id;index;uniqueIndex:idx_conversation_import;type:varchar(36)"`
+	BotID    string `gorm:"column:bot_id;index;type:varchar(64)"`
+	FolderID string `gorm:"column:folder_id;index;type:varchar(36)"`
+	Pinned   bool   `gorm:"column:pinned"`
+	PinnedAt int64  `gorm:"column:pinned_at"`
+	Archived bool   `gorm:"column:archived;index"`
+	// Kind is ConversationKindDirect or ConversationKindGroup. UserID is the
+	// creator; group members are listed in ConversationParticipant.
+	Kind string `gorm:"column:kind;type:varchar(16);default:'direct'"`
+	// ImportKey identifies the source of an imported conversation so that
+	// re-importing the same export is a no-op. It is unique per user and
+	// NULL for conversations started here.
+	ImportKey *string `gorm:"column:import_key;uniqueIndex:idx_conversation_import;type:varchar(128)"`
+	// Model, when set by the /model command, replaces the model requested
+	// by the client for this conversation.
+	Model string `gorm:"column:model;type:varchar(128)"`
+	Title string `gorm:"column:title;type:varchar(255);default:'New';index:idx_conversation_title,class:FULLTEXT,option:WITH PARSER ngram"`
 	CommonPartNoUnique
 }
 type Message struct {
-	ID             int64  `gorm:"primaryKey"`
-	Sequence       int64  `gorm:"column:sequence;index"`
-	ConversationID string `gorm:"column:conversation_id;index;type:varchar(36)"`
-	Role           string `gorm:"column:role;type:varchar(32)"`
-	ContentType    string `gorm:"column:content_type;type:varchar(32)"`
-	Content        string `gorm:"column:content;type:text"`
+	ID             int64   `gorm:"primaryKey"`
+	Sequence       int64   `gorm:"column:sequence;index"`
+	ConversationID string  `gorm:"column:conversation_id;index;type:varchar(36)"`
+	UserID         string  `gorm:"column:user_id;index;type:varchar(36)"`
+	Role           string  `gorm:"column:role;type:varchar(32)"`
+	ContentType    string  `gorm:"column:content_type;type:varchar(32)"`
+	Content        string  `gorm:"column:content;type:text;index:idx_message_content,class:FULLTEXT,option:WITH PARSER ngram"`
+	Reasoning      string  `gorm:"column:reasoning;type:text"`
+	Images         *string `gorm:"column:images;type:json"`
 	Meta           *string `gorm:"column:meta;type:json"`
-	IsSummary      bool   `gorm:"column:is_summary;index"`
-	SummaryFromID  int64  `gorm:"column:summary_from_id;index"`
-	SummaryToID    int64  `gorm:"column:summary_to_id;index"`
+	Moderation     *string `gorm:"column:moderation;type:json"`
+	Model          string  `gorm:"column:model;type:varchar(128)"`
+	PromptVersion  string  `gorm:"column:prompt_version;type:varchar(64)"`
+	// Experiment and Variant record the A/B assignment behind a reply;
+	// usage and latency come with it for comparing variants.
+	Experiment       string `gorm:"column:experiment;index:idx_message_experiment;type:varchar(64)"`
+	Variant          string `gorm:"column:variant;index:idx_message_experiment;type:varchar(64)"`
+	PromptTokens     int    `gorm:"column:prompt_tokens"`
+	CompletionTokens int    `gorm:"column:completion_tokens"`
+	LatencyMs        int64  `gorm:"column:latency_ms"`
+	FirstTokenMs     int64  `gorm:"column:first_token_ms"`
+	IsSummary        bool   `gorm:"column:is_summary;index"`
+	SummaryFromID    int64  `gorm:"column:summary_from_id;index"`
+	SummaryToID      int64  `gorm:"column:summary_to_id;index"`
 	CommonPartNoUnique
 }
 
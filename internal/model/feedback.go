@@ -0,0 +1,26 @@
+package model
+
+const (
+	FeedbackRatingUp   = 1
+	FeedbackRatingDown = -1
+)
+
+// MessageFeedback is one user's rating of an assistant message. Bot, model
+// and prompt version are copied from the message when it is rated so
+// analytics need no join. Reasons is a JSON array of tags.
+type MessageFeedback struct {
+	ID             int64   `gorm:"primaryKey"`
+	MessageID      int64   `gorm:"column:message_id;uniqueIndex:uk_message_feedback"`
+	UserID         string  `gorm:"column:user_id;uniqueIndex:uk_message_feedback;type:varchar(36)"`
+	ConversationID string  `gorm:"column:conversation_id;index;type:varchar(36)"`
+	BotID          string  `gorm:"column:bot_id;index;type:varchar(64)"`
+	Model          string  `gorm:"column:model;index;type:varchar(128)"`
+	PromptVersion  string  `gorm:"column:prompt_version;type:varchar(64)"`
+	Rating         int     `gorm:"column:rating"`
+	Reasons        *string `gorm:"column:reasons;type:json"`
+	Comment        string  `gorm:"column:comment;type:text"`
+	CreatedAt      int64   `gorm:"column:created_at;autoCreateTime;index"`
+	UpdatedAt      int64   `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (MessageFeedback) TableName() string { return "message_feedback" }
@@ -0,0 +1,21 @@
+package model
+
+type Folder struct {
+	UUID   string `gorm:"primaryKey;type:varchar(36)"`
+	UserID string `gorm:"column:user_id;index;type:varchar(36)"`
+	Name   string `gorm:"column:name;type:varchar(64)"`
+	CommonPartNoUnique
+}
+
+// ConversationTag is a user-defined label on a conversation. Tags are
+// replaced as a set, so rows are hard-deleted.
+type ConversationTag struct {
+	ID             int64  `gorm:"primaryKey"`
+	UserID         string `gorm:"column:user_id;index:idx_conversation_tag_user;type:varchar(36)"`
+	ConversationID string `gorm:"column:conversation_id;uniqueIndex:uk_conversation_tag;type:varchar(36)"`
+	Tag            string `gorm:"column:tag;uniqueIndex:uk_conversation_tag;index:idx_conversation_tag_user;type:varchar(32)"`
+	CreatedAt      int64  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Folder) TableName() string          { return "conversation_folder" }
+func (ConversationTag) TableName() string { return "conversation_tag" }
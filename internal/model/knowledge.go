@@ -0,0 +1,41 @@
+package model
+
+type KnowledgeBase struct {
+	UUID        string `gorm:"primaryKey;type:varchar(36)"`
+	BotID       string `gorm:"column:bot_id;index;type:varchar(64)"`
+	OwnerID     string `gorm:"column:owner_id;index;type:varchar(36)"`
+	Name        string `gorm:"column:name;type:varchar(255)"`
+	Description string `gorm:"column:description;type:varchar(1024)"`
+	CommonPartNoUnique
+}
+
+type KnowledgeDocument struct {
+	UUID            string `gorm:"primaryKey;type:varchar(36)"`
+	KnowledgeBaseID string `gorm:"column:knowledge_base_id;index;type:varchar(36)"`
+	Title           string `gorm:"column:title;type:varchar(255)"`
+	Source          string `gorm:"column:source;type:varchar(1024)"`
+	AttachmentID    string `gorm:"column:attachment_id;type:varchar(36)"`
+	Status          string `gorm:"column:status;type:varchar(16)"`
+	Error           string `gorm:"column:error;type:varchar(512)"`
+	ChunkCount      int    `gorm:"column:chunk_count"`
+	CommonPartNoUnique
+}
+
+type KnowledgeChunk struct {
+	ID              int64  `gorm:"primaryKey"`
+	KnowledgeBaseID string `gorm:"column:knowledge_base_id;index;type:varchar(36)"`
+	DocumentID      string `gorm:"column:document_id;index;type:varchar(36)"`
+	ChunkIndex      int    `gorm:"column:chunk_index"`
+	Content         string `gorm:"column:content;type:text"`
+	CommonPartNoUnique
+}
+
+func (KnowledgeBase) TableName() string     { return "knowledge_base" }
+func (KnowledgeDocument) TableName() string { return "knowledge_document" }
+func (KnowledgeChunk) TableName() string    { return "knowledge_chunk" }
+
+const (
+	KnowledgeDocumentStatusIndexing = "indexing"
+	KnowledgeDocumentStatusReady    = "ready"
+	KnowledgeDocumentStatusFailed   = "failed"
+)
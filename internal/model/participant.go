@@ -0,0 +1,25 @@
+package model
+
+const (
+	ConversationKindDirect = "direct"
+	ConversationKindGroup  = "group"
+)
+
+const (
+	ParticipantRoleOwner  = "owner"
+	ParticipantRoleMember = "member"
+)
+
+// ConversationParticipant is a member of a group conversation. The creator
+// is always present as an owner. Rows are hard-deleted when a member leaves.
+type ConversationParticipant struct {
+	ID             int64  `gorm:"primaryKey"`
+	ConversationID string `gorm:"column:conversation_id;uniqueIndex:uk_conversation_participant;type:varchar(36)"`
+	UserID         string `gorm:"column:user_id;uniqueIndex:uk_conversation_participant;index;type:varchar(36)"`
+	Role           string `gorm:"column:role;type:varchar(16)"`
+	DisplayName    string `gorm:"column:display_name;type:varchar(64)"`
+	CreatedAt      int64  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      int64  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (ConversationParticipant) TableName() string { return "conversation_participant" }
@@ -0,0 +1,32 @@
+package model
+
+const (
+	ReminderKindOnce = "once"
+	ReminderKindCron = "cron"
+)
+
+const (
+	ReminderStatusActive    = "active"
+	ReminderStatusDone      = "done"
+	ReminderStatusCancelled = "cancelled"
+)
+
+// Reminder posts Message into the conversation as the bot at NextRunAt.
+// Cron reminders are rescheduled after each run by evaluating CronExpr in
+// Timezone; one-shot reminders are done after they fire.
+type Reminder struct {
+	UUID           string `gorm:"primaryKey;type:varchar(36)"`
+	UserID         string `gorm:"column:user_id;index;type:varchar(36)"`
+	ConversationID string `gorm:"column:conversation_id;index;type:varchar(36)"`
+	Message        string `gorm:"column:message;type:text"`
+	Kind           string `gorm:"column:kind;type:varchar(16)"`
+	CronExpr       string `gorm:"column:cron_expr;type:varchar(128)"`
+	Timezone       string `gorm:"column:timezone;type:varchar(64)"`
+	Status         string `gorm:"column:status;index:idx_reminder_due;type:varchar(16)"`
+	NextRunAt      int64  `gorm:"column:next_run_at;index:idx_reminder_due"`
+	LastRunAt      int64  `gorm:"column:last_run_at"`
+	RunCount       int    `gorm:"column:run_count"`
+	CommonPartNoUnique
+}
+
+func (Reminder) TableName() string { return "reminder" }
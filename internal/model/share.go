@@ -0,0 +1,33 @@
+package model
+
+// Share is a read-only snapshot of a conversation reachable through an
+// unguessable token. Messages are copied at share time, so later messages,
+// edits and trashing of the conversation do not change what the link shows.
+type Share struct {
+	UUID           string `gorm:"primaryKey;type:varchar(36)"`
+	Token          string `gorm:"column:token;uniqueIndex;type:varchar(64)"`
+	ConversationID string `gorm:"column:conversation_id;index;type:varchar(36)"`
+	UserID         string `gorm:"column:user_id;index;type:varchar(36)"`
+	Title          string `gorm:"column:title;type:varchar(255)"`
+	MessageCount   int    `gorm:"column:message_count"`
+	// ExpiresAt and RevokedAt are unix seconds; 0 means never.
+	ExpiresAt int64 `gorm:"column:expires_at"`
+	RevokedAt int64 `gorm:"column:revoked_at"`
+	CommonPartNoUnique
+}
+
+// ShareMessage is a frozen copy of a message. CreatedAt is the time of the
+// original message, not of the copy.
+type ShareMessage struct {
+	ID          int64   `gorm:"primaryKey"`
+	ShareID     string  `gorm:"column:share_id;index;type:varchar(36)"`
+	Position    int     `gorm:"column:position"`
+	Role        string  `gorm:"column:role;type:varchar(32)"`
+	ContentType string  `gorm:"column:content_type;type:varchar(32)"`
+	Content     string  `gorm:"column:content;type:text"`
+	Images      *string `gorm:"column:images;type:json"`
+	CreatedAt   int64   `gorm:"column:created_at"`
+}
+
+func (Share) TableName() string        { return "conversation_share" }
+func (ShareMessage) TableName() string { return "conversation_share_message" }
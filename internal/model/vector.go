@@ -0,0 +1,14 @@
+package model
+
+type VectorRecord struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement"`
+	Namespace string `gorm:"column:namespace;type:varchar(128);uniqueIndex:idx_vector_ns_record,priority:1"`
+	RecordID  string `gorm:"column:record_id;type:varchar(64);uniqueIndex:idx_vector_ns_record,priority:2"`
+	Scope     string `gorm:"column:scope;type:varchar(64);index:idx_vector_scope"`
+	Dim       int    `gorm:"column:dim"`
+	Vector    []byte `gorm:"column:vector;type:mediumblob"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (VectorRecord) TableName() string { return "vector_record" }
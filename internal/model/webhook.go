@@ -0,0 +1,74 @@
+package model
+
+import "encoding/json"
+
+const (
+	WebhookConversationCreated        = "conversation.created"
+	WebhookConversationTitleGenerated = "conversation.title_generated"
+	WebhookConversationDeleted        = "conversation.deleted"
+	WebhookMessageCompleted           = "message.completed"
+	WebhookReminderFired              = "reminder.fired"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription receives events of one bot, or of every bot when
+// BotID is empty. EventTypes is a JSON array; empty means all types.
+type WebhookSubscription struct {
+	UUID       string  `gorm:"primaryKey;type:varchar(36)"`
+	OwnerID    string  `gorm:"column:owner_id;index;type:varchar(36)"`
+	BotID      string  `gorm:"column:bot_id;index;type:varchar(64)"`
+	URL        string  `gorm:"column:url;type:varchar(1024)"`
+	Secret     string  `gorm:"column:secret;type:varchar(128)"`
+	EventTypes *string `gorm:"column:event_types;type:json"`
+	Enabled    bool    `gorm:"column:enabled;index"`
+	CommonPartNoUnique
+}
+
+// WebhookEvent is the outbox: it is written in the transaction of the
+// change it describes and fanned out into deliveries by the dispatcher.
+// BotID is resolved from the conversation at dispatch time.
+type WebhookEvent struct {
+	ID             int64   `gorm:"primaryKey"`
+	Type           string  `gorm:"column:type;type:varchar(64)"`
+	ConversationID string  `gorm:"column:conversation_id;index;type:varchar(36)"`
+	BotID          string  `gorm:"column:bot_id;type:varchar(64)"`
+	Data           *string `gorm:"column:data;type:json"`
+	Dispatched     bool    `gorm:"column:dispatched;index"`
+	CreatedAt      int64   `gorm:"column:created_at;autoCreateTime"`
+}
+
+// WebhookDelivery is one event sent to one subscription. Pending deliveries
+// are retried with backoff until delivered or dead.
+type WebhookDelivery struct {
+	ID             int64  `gorm:"primaryKey"`
+	SubscriptionID string `gorm:"column:subscription_id;index;type:varchar(36)"`
+	EventID        int64  `gorm:"column:event_id;index"`
+	EventType      string `gorm:"column:event_type;type:varchar(64)"`
+	Status         string `gorm:"column:status;index:idx_webhook_delivery_due;type:varchar(16)"`
+	Attempts       int    `gorm:"column:attempts"`
+	NextAttemptAt  int64  `gorm:"column:next_attempt_at;index:idx_webhook_delivery_due"`
+	LastStatusCode int    `gorm:"column:last_status_code"`
+	LastError      string `gorm:"column:last_error;type:varchar(512)"`
+	DeliveredAt    int64  `gorm:"column:delivered_at"`
+	CreatedAt      int64  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      int64  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// Types decodes EventTypes; an empty result means every event type.
+func (s WebhookSubscription) Types() []string {
+	if s.EventTypes == nil || *s.EventTypes == "" {
+		return nil
+	}
+	var types []string
+	_ = json.Unmarshal([]byte(*s.EventTypes), &types)
+	return types
+}
+
+func (WebhookSubscription) TableName() string { return "webhook_subscription" }
+func (WebhookEvent) TableName() string        { return "webhook_event" }
+func (WebhookDelivery) TableName() string     { return "webhook_delivery" }
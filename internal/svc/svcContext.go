@@ -1,28 +1,41 @@
 package svc
 
 import (
+	"os"
+	"time"
+
 	"github.com/im-core-go/im-core-bot-platform/configs"
 	"github.com/im-core-go/im-core-bot-platform/internal/dao"
 	"github.com/im-core-go/im-core-bot-platform/pkg/auth"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+	"github.com/im-core-go/im-core-bot-platform/pkg/cursor"
 	"github.com/im-core-go/im-core-bot-platform/pkg/infra"
 	"github.com/im-core-go/im-core-bot-platform/pkg/utils"
+	"github.com/im-core-go/im-core-bot-platform/pkg/vector"
 )
 
 type Context struct {
-	Config configs.Config
-	Dao    *dao.Dao
-	Utils  *utils.Utils
-	Infra  *infra.Infra
-	Auth   *auth.JwtHandler
+	Config      configs.Config
+	Dao         *dao.Dao
+	Utils       *utils.Utils
+	Infra       *infra.Infra
+	Auth        *auth.JwtHandler
+	VectorIndex vector.Index
+	Cursor      *cursor.Signer
+	BlobURLs    *blob.URLSigner
 }
 
 func NewContext(cfg configs.Config) *Context {
 	infraSvc := infra.NewInfra(cfg)
+	daoSvc := dao.NewDao(infraSvc.DB)
 	return &Context{
-		Config: cfg,
-		Utils:  utils.NewUtils(infraSvc.Redis),
-		Dao:    dao.NewDao(infraSvc.DB),
-		Infra:  infraSvc,
-		Auth:   auth.NewJwtHandler(),
+		Config:      cfg,
+		Utils:       utils.NewUtils(infraSvc.Redis),
+		Dao:         daoSvc,
+		Infra:       infraSvc,
+		Auth:        auth.NewJwtHandler(),
+		VectorIndex: vector.NewMemoryIndex(daoSvc.VectorDao, time.Duration(cfg.VectorConf.CacheTTLSeconds)*time.Second, cfg.VectorConf.MaxNamespaces),
+		Cursor:      cursor.NewSigner([]byte(os.Getenv("CURSOR_SECRET"))),
+		BlobURLs:    blob.NewURLSigner(infraSvc.Blob, []byte(os.Getenv("BLOB_URL_SECRET")), time.Duration(cfg.BlobConf.URLTTLSeconds)*time.Second),
 	}
 }
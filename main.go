@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/im-core-go/im-core-bot-platform/configs"
 	grpcserver "github.com/im-core-go/im-core-bot-platform/internal/grpc"
+	"github.com/im-core-go/im-core-bot-platform/internal/im"
+	"github.com/im-core-go/im-core-bot-platform/internal/job"
 	"github.com/im-core-go/im-core-bot-platform/internal/svc"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
 	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
 	"github.com/im-core-go/im-core-proto/gen/bot/v1"
 
@@ -38,8 +45,46 @@ func main() {
 		lgr.Fatalf("grpc server init error: %v", err)
 	}
 	botv1.RegisterChatServiceServer(server, chatServer)
+	go job.NewPurger(svcCtx).Run(context.Background())
+	if cfg.ReminderConf.Enabled {
+		worker, err := job.NewReminderWorker(svcCtx)
+		if err != nil {
+			lgr.Fatalf("reminder worker init error: %v", err)
+		}
+		go worker.Run(context.Background())
+	}
+	if cfg.WebhookConf.Enabled {
+		go job.NewWebhookWorker(svcCtx).Run(context.Background())
+	}
+	if cfg.IMConf.Enabled {
+		adapter, err := im.NewAdapter(context.Background(), svcCtx)
+		if err != nil {
+			lgr.Fatalf("im adapter init error: %v", err)
+		}
+		go adapter.Run(context.Background())
+	}
+	if cfg.BlobConf.HTTPAddr != "" {
+		go serveBlobs(svcCtx)
+	}
 	lgr.Infof("grpc server start on %s", addr)
 	if err := server.Serve(listener); err != nil {
 		lgr.Fatalf("grpc server stopped: %v", err)
 	}
 }
+
+// serveBlobs serves signed blob links under the path of the public URL.
+func serveBlobs(svcCtx *svc.Context) {
+	lgr := logger.L()
+	conf := svcCtx.Config.BlobConf
+	public, err := url.Parse(conf.Local.PublicURL)
+	if err != nil {
+		lgr.Fatalf("parse blob public url error: %v", err)
+	}
+	prefix := strings.TrimRight(public.Path, "/") + "/"
+	mux := http.NewServeMux()
+	mux.Handle(prefix, http.StripPrefix(prefix, blob.NewHandler(svcCtx.Infra.Blob, svcCtx.BlobURLs)))
+	lgr.Infof("blob server start on %s", conf.HTTPAddr)
+	if err := http.ListenAndServe(conf.HTTPAddr, mux); err != nil {
+		lgr.Fatalf("blob server stopped: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+type Object struct {
+	Key      string
+	URL      string
+	MimeType string
+	Size     int64
+}
+
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, mimeType string) (Object, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
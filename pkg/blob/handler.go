@@ -0,0 +1,61 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/im-core-go/im-core-bot-platform/pkg/logger"
+)
+
+// Handler serves objects by key, taken from the request path, to holders of
+// a link from signer. Mount it under the path of the store's public URL
+// with http.StripPrefix.
+type Handler struct {
+	store  Store
+	signer *URLSigner
+}
+
+func NewHandler(store Store, signer *URLSigner) *Handler {
+	return &Handler{store: store, signer: signer}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	key := strings.TrimLeft(r.URL.Path, "/")
+	q := r.URL.Query()
+	if key == "" || h.signer.Verify(key, q.Get("expires"), q.Get("signature")) != nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	body, err := h.store.Get(r.Context(), key)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.L().Errorf("read blob %s error: %v", key, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = io.Copy(w, body)
+}
@@ -0,0 +1,78 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerServesSignedLinks(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "http://blobs.test/blobs")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if _, err := store.Put(context.Background(), "images/c1/a.png", strings.NewReader("png-bytes"), "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	signer := NewURLSigner(store, []byte("secret"), time.Minute)
+	srv := httptest.NewServer(http.StripPrefix("/blobs/", NewHandler(store, signer)))
+	defer srv.Close()
+
+	get := func(link string) (*http.Response, string) {
+		t.Helper()
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("parse %q: %v", link, err)
+		}
+		resp, err := http.Get(srv.URL + u.RequestURI())
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	link := signer.URL("images/c1/a.png")
+	resp, body := get(link)
+	if resp.StatusCode != http.StatusOK || body != "png-bytes" {
+		t.Fatalf("signed link = %d %q, want the object", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "image/png" {
+		t.Errorf("content type = %q, want image/png", ct)
+	}
+
+	for name, bad := range map[string]string{
+		"unsigned":     "http://blobs.test/blobs/images/c1/a.png",
+		"other key":    strings.Replace(link, "a.png", "b.png", 1),
+		"tampered sig": strings.Replace(link, "signature=", "signature=x", 1),
+	} {
+		if resp, _ := get(bad); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", name, resp.StatusCode)
+		}
+	}
+
+	signer.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if resp, _ := get(link); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expired link: status %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestURLSignerWithoutPublicURL(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if link := NewURLSigner(store, nil, 0).URL("a.png"); link != "" {
+		t.Errorf("URL = %q, want none without a public URL", link)
+	}
+	var nilSigner *URLSigner
+	if link := nilSigner.URL("a.png"); link != "" {
+		t.Errorf("nil signer URL = %q, want none", link)
+	}
+}
@@ -0,0 +1,101 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrUnsupported = errors.New("unsupported file type")
+
+const (
+	TypePlain    = "text/plain"
+	TypeMarkdown = "text/markdown"
+	TypeCSV      = "text/csv"
+	TypeJSON     = "application/json"
+	TypePDF      = "application/pdf"
+)
+
+var extensionTypes = map[string]string{
+	".txt":      TypePlain,
+	".log":      TypePlain,
+	".md":       TypeMarkdown,
+	".markdown": TypeMarkdown,
+	".csv":      TypeCSV,
+	".json":     TypeJSON,
+	".pdf":      TypePDF,
+}
+
+// DetectType resolves the MIME type from the declared type, the file
+// extension and finally the content itself.
+func DetectType(fileName, declared string, data []byte) string {
+	if declared != "" {
+		if t, _, err := mime.ParseMediaType(declared); err == nil && t != "application/octet-stream" {
+			return t
+		}
+	}
+	if t, ok := extensionTypes[strings.ToLower(filepath.Ext(fileName))]; ok {
+		return t
+	}
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return TypePDF
+	}
+	t, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return t
+}
+
+func Text(mimeType string, data []byte) (string, error) {
+	switch mimeType {
+	case TypePlain, TypeMarkdown, "text/x-markdown":
+		return plainText(data), nil
+	case TypeCSV:
+		return csvText(data), nil
+	case TypeJSON:
+		return jsonText(data)
+	case TypePDF:
+		return pdfText(data)
+	default:
+		return "", ErrUnsupported
+	}
+}
+
+func plainText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return strings.ToValidUTF8(string(data), "")
+	}
+	return string(data)
+}
+
+// csvText renders rows as pipe separated lines, which models read more
+// reliably than raw CSV with quoted fields.
+func csvText(data []byte) string {
+	text := plainText(data)
+	r := csv.NewReader(strings.NewReader(text))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return text
+	}
+	var b strings.Builder
+	for _, record := range records {
+		b.WriteString(strings.Join(record, " | "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func jsonText(data []byte) (string, error) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), "", "  "); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,400 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	maxPDFStreamLen = 32 << 20
+	// maxPDFArrayDepth bounds array nesting; content streams need a single
+	// level, and unbounded recursion would overflow the stack.
+	maxPDFArrayDepth = 64
+)
+
+var errPDFNesting = errors.New("pdf arrays nested too deeply")
+
+var (
+	streamKeyword    = []byte("stream")
+	endstreamKeyword = []byte("endstream")
+	objKeyword       = []byte("obj")
+)
+
+// pdfText is a best-effort extractor for text-based PDFs: it inflates content
+// streams and collects the strings drawn by the text operators. Scanned PDFs
+// and fonts with custom CID encodings yield little or no text.
+func pdfText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("invalid pdf")
+	}
+	var out strings.Builder
+	pos := 0
+	for {
+		idx := bytes.Index(data[pos:], streamKeyword)
+		if idx < 0 {
+			break
+		}
+		start := pos + idx
+		pos = start + len(streamKeyword)
+		if start >= 3 && bytes.Equal(data[start-3:start], []byte("end")) {
+			continue
+		}
+		bodyStart := pos
+		if bodyStart < len(data) && data[bodyStart] == '\r' {
+			bodyStart++
+		}
+		if bodyStart < len(data) && data[bodyStart] == '\n' {
+			bodyStart++
+		}
+		end := bytes.Index(data[bodyStart:], endstreamKeyword)
+		if end < 0 {
+			break
+		}
+		body := data[bodyStart : bodyStart+end]
+		pos = bodyStart + end + len(endstreamKeyword)
+
+		dict := streamDict(data[:start])
+		if skipStream(dict) {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			inflated, err := inflate(body)
+			if err != nil {
+				continue
+			}
+			body = inflated
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue
+		}
+		if !bytes.Contains(body, []byte("BT")) {
+			continue
+		}
+		text, err := contentText(body)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		out.WriteString(text)
+		out.WriteByte('\n')
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func streamDict(prefix []byte) []byte {
+	if idx := bytes.LastIndex(prefix, objKeyword); idx >= 0 {
+		return prefix[idx:]
+	}
+	if len(prefix) > 512 {
+		return prefix[len(prefix)-512:]
+	}
+	return prefix
+}
+
+func skipStream(dict []byte) bool {
+	for _, marker := range []string{"/Image", "/XRef", "/Length1", "/Length2", "/FontFile", "/Metadata"} {
+		if bytes.Contains(dict, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+func inflate(body []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, maxPDFStreamLen))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int
+	err   error
+}
+
+type pdfToken struct {
+	kind  byte // 's' string, 'n' number, 'o' operator, '[' and ']' arrays, 0 other
+	str   string
+	num   float64
+	items []pdfToken
+}
+
+func contentText(content []byte) (string, error) {
+	lx := &pdfLexer{data: content}
+	var (
+		out      strings.Builder
+		operands []pdfToken
+	)
+	newline := func() {
+		if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	for {
+		tok, ok := lx.next()
+		if !ok {
+			break
+		}
+		if tok.kind != 'o' {
+			operands = append(operands, tok)
+			continue
+		}
+		switch tok.str {
+		case "Tj":
+			writeStrings(&out, operands)
+		case "'", "\"":
+			newline()
+			writeStrings(&out, operands)
+		case "TJ":
+			for _, op := range operands {
+				if op.kind != '[' {
+					continue
+				}
+				for _, item := range op.items {
+					switch {
+					case item.kind == 's':
+						out.WriteString(item.str)
+					case item.kind == 'n' && item.num < -200:
+						out.WriteByte(' ')
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 && operands[1].kind == 'n' && operands[1].num != 0 {
+				newline()
+			} else if out.Len() > 0 {
+				out.WriteByte(' ')
+			}
+		case "T*", "ET":
+			newline()
+		}
+		operands = operands[:0]
+	}
+	if lx.err != nil {
+		return "", lx.err
+	}
+	return out.String(), nil
+}
+
+func writeStrings(out *strings.Builder, operands []pdfToken) {
+	for _, op := range operands {
+		if op.kind == 's' {
+			out.WriteString(op.str)
+		}
+	}
+}
+
+// next returns false at the end of the data or on an error, which it
+// leaves in lx.err.
+func (lx *pdfLexer) next() (pdfToken, bool) {
+	lx.skipSpace()
+	if lx.err != nil || lx.pos >= len(lx.data) {
+		return pdfToken{}, false
+	}
+	c := lx.data[lx.pos]
+	switch {
+	case c == '(':
+		lx.pos++
+		return pdfToken{kind: 's', str: decodePDFString(lx.literal())}, true
+	case c == '<' && lx.peek(1) == '<':
+		lx.pos += 2
+		return pdfToken{}, true
+	case c == '>' && lx.peek(1) == '>':
+		lx.pos += 2
+		return pdfToken{}, true
+	case c == '<':
+		lx.pos++
+		return pdfToken{kind: 's', str: decodePDFString(lx.hex())}, true
+	case c == '[':
+		if lx.depth >= maxPDFArrayDepth {
+			lx.err = errPDFNesting
+			return pdfToken{}, false
+		}
+		lx.pos++
+		lx.depth++
+		defer func() { lx.depth-- }()
+		arr := pdfToken{kind: '['}
+		for {
+			lx.skipSpace()
+			if lx.pos >= len(lx.data) {
+				return arr, true
+			}
+			if lx.data[lx.pos] == ']' {
+				lx.pos++
+				return arr, true
+			}
+			item, ok := lx.next()
+			if !ok {
+				return arr, lx.err == nil
+			}
+			arr.items = append(arr.items, item)
+		}
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		lx.pos++
+		return pdfToken{}, true
+	case c == '/':
+		lx.pos++
+		lx.regular()
+		return pdfToken{}, true
+	}
+	word := lx.regular()
+	if word == "" {
+		lx.pos++
+		return pdfToken{}, true
+	}
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		return pdfToken{kind: 'n', num: n}, true
+	}
+	return pdfToken{kind: 'o', str: word}, true
+}
+
+func (lx *pdfLexer) peek(offset int) byte {
+	if lx.pos+offset < len(lx.data) {
+		return lx.data[lx.pos+offset]
+	}
+	return 0
+}
+
+func (lx *pdfLexer) skipSpace() {
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if c == '%' {
+			for lx.pos < len(lx.data) && lx.data[lx.pos] != '\n' && lx.data[lx.pos] != '\r' {
+				lx.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		lx.pos++
+	}
+}
+
+func (lx *pdfLexer) regular() string {
+	start := lx.pos
+	for lx.pos < len(lx.data) && !isPDFSpace(lx.data[lx.pos]) && !isPDFDelimiter(lx.data[lx.pos]) {
+		lx.pos++
+	}
+	return string(lx.data[start:lx.pos])
+}
+
+func (lx *pdfLexer) literal() []byte {
+	var out []byte
+	depth := 1
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		lx.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			if lx.pos >= len(lx.data) {
+				return out
+			}
+			e := lx.data[lx.pos]
+			lx.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r':
+				if lx.peek(0) == '\n' {
+					lx.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && lx.pos < len(lx.data) && lx.data[lx.pos] >= '0' && lx.data[lx.pos] <= '7'; i++ {
+						v = v*8 + int(lx.data[lx.pos]-'0')
+						lx.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (lx *pdfLexer) hex() []byte {
+	var digits []byte
+	for lx.pos < len(lx.data) && lx.data[lx.pos] != '>' {
+		c := lx.data[lx.pos]
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+		lx.pos++
+	}
+	lx.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		v, _ := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		out = append(out, byte(v))
+	}
+	return out
+}
+
+func decodePDFString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		units := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	var out strings.Builder
+	for _, c := range b {
+		r := rune(c)
+		if r == '\n' || r == '\t' || unicode.IsPrint(r) {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,41 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func pdfWithContent(content string) []byte {
+	return []byte(fmt.Sprintf("%%PDF-1.4\n1 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n%%%%EOF\n", len(content), content))
+}
+
+func TestPDFText(t *testing.T) {
+	data := pdfWithContent("BT /F1 12 Tf (Hello) Tj 0 -14 Td [(Wor) -50 (ld)] TJ ET")
+	text, err := pdfText(data)
+	if err != nil {
+		t.Fatalf("pdfText: %v", err)
+	}
+	if text != "Hello\nWorld" {
+		t.Errorf("text = %q, want %q", text, "Hello\nWorld")
+	}
+}
+
+func TestPDFTextDeepNesting(t *testing.T) {
+	data := pdfWithContent("BT " + strings.Repeat("[", 1<<20) + " TJ ET")
+	if _, err := pdfText(data); !errors.Is(err, errPDFNesting) {
+		t.Errorf("err = %v, want %v", err, errPDFNesting)
+	}
+}
+
+func TestPDFTextNestingWithinLimit(t *testing.T) {
+	content := "BT " + strings.Repeat("[", maxPDFArrayDepth-1) + "(deep)" + strings.Repeat("]", maxPDFArrayDepth-1) + " TJ (flat) Tj ET"
+	text, err := pdfText(pdfWithContent(content))
+	if err != nil {
+		t.Fatalf("pdfText: %v", err)
+	}
+	if text != "flat" {
+		t.Errorf("text = %q, want %q", text, "flat")
+	}
+}
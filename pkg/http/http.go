@@ -12,17 +12,28 @@ import (
 
 type RequestHandler struct {
 	commonClient *http.Client
+	longClient   *http.Client
 	sseClient    *http.Client
 }
 
 func NewRequestHandler() *RequestHandler {
 	return &RequestHandler{
 		commonClient: &http.Client{Timeout: time.Second * 5},
+		longClient:   &http.Client{Timeout: time.Minute * 2},
 		sseClient:    &http.Client{Timeout: 0},
 	}
 }
 
 func (r *RequestHandler) DoCommon(ctx context.Context, method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	return r.do(ctx, r.commonClient, method, url, body, headers)
+}
+
+// DoLong is DoCommon for slow upstream calls such as image generation.
+func (r *RequestHandler) DoLong(ctx context.Context, method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	return r.do(ctx, r.longClient, method, url, body, headers)
+}
+
+func (r *RequestHandler) do(ctx context.Context, client *http.Client, method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
@@ -30,7 +41,7 @@ func (r *RequestHandler) DoCommon(ctx context.Context, method, url string, body
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
-	resp, err := r.commonClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,24 @@
+package infra
+
+import (
+	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
+)
+
+func newBlob(cfg configs.Config) blob.Store {
+	blobConf := cfg.BlobConf
+	switch blobConf.Driver {
+	case "", "local":
+		root := blobConf.Local.Root
+		if root == "" {
+			root = "data/blobs"
+		}
+		store, err := blob.NewLocalStore(root, blobConf.Local.PublicURL)
+		if err != nil {
+			panic("failed to init blob store")
+		}
+		return store
+	default:
+		panic("unsupported blob driver: " + blobConf.Driver)
+	}
+}
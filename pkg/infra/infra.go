@@ -2,6 +2,7 @@ package infra
 
 import (
 	"github.com/im-core-go/im-core-bot-platform/configs"
+	"github.com/im-core-go/im-core-bot-platform/pkg/blob"
 
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -10,11 +11,13 @@ import (
 type Infra struct {
 	Redis *redis.Client
 	DB    *gorm.DB
+	Blob  blob.Store
 }
 
 func NewInfra(cfg configs.Config) *Infra {
 	return &Infra{
 		Redis: newRedis(cfg),
 		DB:    newMysql(cfg),
+		Blob:  newBlob(cfg),
 	}
 }
@@ -26,6 +26,22 @@ func newMysql(cfg configs.Config) *gorm.DB {
 	db.AutoMigrate(
 		&model.Message{},
 		&model.Conversation{},
+		&model.Folder{},
+		&model.ConversationTag{},
+		&model.ConversationParticipant{},
+		&model.Share{},
+		&model.ShareMessage{},
+		&model.ChannelBinding{},
+		&model.WebhookSubscription{},
+		&model.WebhookEvent{},
+		&model.WebhookDelivery{},
+		&model.Reminder{},
+		&model.MessageFeedback{},
+		&model.Attachment{},
+		&model.KnowledgeBase{},
+		&model.KnowledgeDocument{},
+		&model.KnowledgeChunk{},
+		&model.VectorRecord{},
 	)
 	return db
 }
@@ -0,0 +1,49 @@
+package lease
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/acquire.lua
+var acquireScript string
+
+//go:embed lua/release.lua
+var releaseScript string
+
+// Lease is a Redis lock held by one process at a time, used to elect a
+// leader among replicas. The holder must call Acquire again well within
+// the TTL to keep it; a holder that stops renewing loses it when the key
+// expires.
+type Lease struct {
+	cmd   redis.Cmdable
+	key   string
+	token string
+	ttl   time.Duration
+}
+
+func New(cmd redis.Cmdable, key string, ttl time.Duration) *Lease {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return &Lease{cmd: cmd, key: key, token: hex.EncodeToString(b), ttl: ttl}
+}
+
+// Acquire takes the lease if it is free and renews it if this holder
+// already has it. It reports whether this holder is the leader.
+func (l *Lease) Acquire(ctx context.Context) (bool, error) {
+	res, err := l.cmd.Eval(ctx, acquireScript, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// Release gives the lease up if this holder has it.
+func (l *Lease) Release(ctx context.Context) error {
+	return l.cmd.Eval(ctx, releaseScript, []string{l.key}, l.token).Err()
+}
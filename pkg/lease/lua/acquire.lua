@@ -0,0 +1,12 @@
+local key = KEYS[1]
+local token = ARGV[1]
+local ttl = ARGV[2]
+
+if redis.call("get", key) == token then
+    redis.call("pexpire", key, ttl)
+    return 1
+end
+if redis.call("set", key, token, "NX", "PX", ttl) then
+    return 1
+end
+return 0
@@ -0,0 +1,492 @@
+// Package openaitest is a scripted OpenAI-compatible API for tests and
+// local development. It serves /models, /chat/completions (plain and
+// streamed), /embeddings and /moderations, with or without a /v1 prefix,
+// and can inject the failures real providers produce.
+package openaitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultChunkSize    = 4
+	defaultEmbeddingDim = 64
+	defaultFinishReason = "stop"
+)
+
+// ChatRequest is a /chat/completions request as the provider received it.
+type ChatRequest struct {
+	Model         string    `json:"model"`
+	Messages      []Message `json:"messages"`
+	Stream        bool      `json:"stream"`
+	Temperature   *float64  `json:"temperature"`
+	TopP          *float64  `json:"top_p"`
+	MaxTokens     int       `json:"max_tokens"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+// LastUserText is the text of the last user message.
+func (r ChatRequest) LastUserText() string {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Role == "user" {
+			return r.Messages[i].Text()
+		}
+	}
+	return ""
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// Text is the message content, joining the text parts of a vision message.
+func (m Message) Text() string {
+	switch c := m.Content.(type) {
+	case string:
+		return c
+	case []any:
+		var parts []string
+		for _, p := range c {
+			if part, ok := p.(map[string]any); ok && part["type"] == "text" {
+				if text, ok := part["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, "\n")
+	default:
+		return ""
+	}
+}
+
+// Reply is what the provider answers a chat request with. Streamed, the
+// reasoning and then the content are sent in deltas of ChunkSize runes.
+type Reply struct {
+	Content   string
+	Reasoning string
+	// FinishReason defaults to "stop".
+	FinishReason string
+	// Usage defaults to word counts of the prompt and the reply.
+	Usage     *Usage
+	ChunkSize int
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Fault breaks the next request. Status answers with that HTTP status and
+// an OpenAI-style error body. Malformed sends invalid JSON, as the body or,
+// streamed, as the chunk after AfterChunks. Disconnect drops a stream after
+// AfterChunks chunks without finishing it. ChunkDelay slows every streamed
+// chunk; the wait ends early when the client goes away.
+type Fault struct {
+	Status      int
+	Malformed   bool
+	Disconnect  bool
+	AfterChunks int
+	ChunkDelay  time.Duration
+}
+
+// Provider is the API as an http.Handler. It is safe for concurrent use.
+type Provider struct {
+	mu         sync.Mutex
+	models     []string
+	respond    func(ChatRequest) Reply
+	queue      []Reply
+	faults     []Fault
+	requests   []ChatRequest
+	embeddings int
+	embedded   []string
+	flagged    []string
+}
+
+// NewProvider returns a provider listing "mock-model" that echoes the last
+// user message.
+func NewProvider() *Provider {
+	return &Provider{
+		models: []string{"mock-model"},
+		respond: func(req ChatRequest) Reply {
+			return Reply{Content: "echo: " + req.LastUserText()}
+		},
+	}
+}
+
+// SetModels replaces the models /models lists.
+func (p *Provider) SetModels(ids ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models = ids
+}
+
+// Respond answers chat requests with fn once the queued replies are used up.
+func (p *Provider) Respond(fn func(ChatRequest) Reply) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.respond = fn
+}
+
+// Queue answers the next chat requests with replies, in order.
+func (p *Provider) Queue(replies ...Reply) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queue = append(p.queue, replies...)
+}
+
+// Fail applies faults to the next requests to any endpoint, in order.
+func (p *Provider) Fail(faults ...Fault) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faults = append(p.faults, faults...)
+}
+
+// Flag makes /moderations flag input containing any of the terms.
+func (p *Provider) Flag(terms ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.flagged = append(p.flagged, terms...)
+}
+
+// Requests returns the chat requests received so far, faulted ones
+// included.
+func (p *Provider) Requests() []ChatRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ChatRequest(nil), p.requests...)
+}
+
+// EmbeddingCalls counts the /embeddings requests received so far.
+func (p *Provider) EmbeddingCalls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.embeddings
+}
+
+// EmbeddingInputs returns every text sent to /embeddings so far, in order.
+func (p *Provider) EmbeddingInputs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.embedded...)
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+	var fault Fault
+	p.mu.Lock()
+	if len(p.faults) > 0 {
+		fault = p.faults[0]
+		p.faults = p.faults[1:]
+	}
+	p.mu.Unlock()
+
+	switch {
+	case path == "/models" && r.Method == http.MethodGet:
+		if p.fail(w, fault, false) {
+			return
+		}
+		p.serveModels(w)
+	case path == "/chat/completions" && r.Method == http.MethodPost:
+		p.serveChat(w, r, fault)
+	case path == "/embeddings" && r.Method == http.MethodPost:
+		p.mu.Lock()
+		p.embeddings++
+		p.mu.Unlock()
+		if p.fail(w, fault, false) {
+			return
+		}
+		p.serveEmbeddings(w, r)
+	case path == "/moderations" && r.Method == http.MethodPost:
+		if p.fail(w, fault, false) {
+			return
+		}
+		p.serveModerations(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "unknown endpoint "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// fail answers with a status or malformed body fault; faults of a stream
+// that has started are left to the stream.
+func (p *Provider) fail(w http.ResponseWriter, f Fault, streaming bool) bool {
+	switch {
+	case f.Status != 0:
+		if f.Status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, f.Status, "rate_limit_exceeded", "rate limit reached")
+		} else {
+			writeError(w, f.Status, "server_error", "injected failure")
+		}
+		return true
+	case f.Malformed && !streaming:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices": [`))
+		return true
+	}
+	return false
+}
+
+func (p *Provider) serveModels(w http.ResponseWriter) {
+	p.mu.Lock()
+	ids := append([]string(nil), p.models...)
+	p.mu.Unlock()
+	type model struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Created int64  `json:"created"`
+		OwnedBy string `json:"owned_by"`
+	}
+	data := make([]model, 0, len(ids))
+	for _, id := range ids {
+		data = append(data, model{ID: id, Object: "model", Created: 1700000000, OwnedBy: "openaitest"})
+	}
+	writeJSON(w, map[string]any{"object": "list", "data": data})
+}
+
+func (p *Provider) serveChat(w http.ResponseWriter, r *http.Request, fault Fault) {
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+	if req.Model == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "missing model")
+		return
+	}
+	// A request failed up front leaves the queued replies to the retry.
+	if p.fail(w, fault, req.Stream) {
+		return
+	}
+
+	p.mu.Lock()
+	var reply Reply
+	queued := len(p.queue) > 0
+	if queued {
+		reply = p.queue[0]
+		p.queue = p.queue[1:]
+	}
+	respond := p.respond
+	p.mu.Unlock()
+	if !queued {
+		reply = respond(req)
+	}
+	if reply.FinishReason == "" {
+		reply.FinishReason = defaultFinishReason
+	}
+	if reply.Usage == nil {
+		reply.Usage = estimateUsage(req, reply)
+	}
+	if req.Stream {
+		p.streamChat(w, r, req, reply, fault)
+		return
+	}
+	message := map[string]any{"role": "assistant", "content": reply.Content}
+	if reply.Reasoning != "" {
+		message["reasoning_content"] = reply.Reasoning
+	}
+	writeJSON(w, map[string]any{
+		"id":      "chatcmpl-mock",
+		"object":  "chat.completion",
+		"model":   req.Model,
+		"choices": []any{map[string]any{"index": 0, "message": message, "finish_reason": reply.FinishReason}},
+		"usage":   reply.Usage,
+	})
+}
+
+func (p *Provider) streamChat(w http.ResponseWriter, r *http.Request, req ChatRequest, reply Reply, fault Fault) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "server_error", "streaming unsupported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var chunks []string
+	chunk := func(delta map[string]any, finishReason any) string {
+		return mustJSON(map[string]any{
+			"id":      "chatcmpl-mock",
+			"object":  "chat.completion.chunk",
+			"model":   req.Model,
+			"choices": []any{map[string]any{"index": 0, "delta": delta, "finish_reason": finishReason}},
+		})
+	}
+	size := reply.ChunkSize
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	for _, part := range split(reply.Reasoning, size) {
+		chunks = append(chunks, chunk(map[string]any{"reasoning_content": part}, nil))
+	}
+	for _, part := range split(reply.Content, size) {
+		chunks = append(chunks, chunk(map[string]any{"content": part}, nil))
+	}
+	chunks = append(chunks, chunk(map[string]any{}, reply.FinishReason))
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		chunks = append(chunks, mustJSON(map[string]any{
+			"id":      "chatcmpl-mock",
+			"object":  "chat.completion.chunk",
+			"model":   req.Model,
+			"choices": []any{},
+			"usage":   reply.Usage,
+		}))
+	}
+	chunks = append(chunks, "[DONE]")
+
+	for i, data := range chunks {
+		if fault.ChunkDelay > 0 {
+			select {
+			case <-time.After(fault.ChunkDelay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if i == fault.AfterChunks {
+			if fault.Disconnect {
+				// Aborting closes the connection without ending the chunked
+				// body, as a dropped upstream connection does.
+				panic(http.ErrAbortHandler)
+			}
+			if fault.Malformed {
+				data = `{"choices": [{"delta": `
+			}
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func (p *Provider) serveEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model string `json:"model"`
+		Input any    `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	var inputs []string
+	switch in := req.Input.(type) {
+	case string:
+		inputs = []string{in}
+	case []any:
+		for _, v := range in {
+			s, _ := v.(string)
+			inputs = append(inputs, s)
+		}
+	}
+	p.mu.Lock()
+	p.embedded = append(p.embedded, inputs...)
+	p.mu.Unlock()
+	data := make([]any, 0, len(inputs))
+	for i, in := range inputs {
+		data = append(data, map[string]any{"object": "embedding", "index": i, "embedding": Embed(in)})
+	}
+	writeJSON(w, map[string]any{"object": "list", "model": req.Model, "data": data})
+}
+
+func (p *Provider) serveModerations(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Input any `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	text, _ := req.Input.(string)
+	p.mu.Lock()
+	flagged := false
+	for _, term := range p.flagged {
+		if strings.Contains(strings.ToLower(text), strings.ToLower(term)) {
+			flagged = true
+			break
+		}
+	}
+	p.mu.Unlock()
+	writeJSON(w, map[string]any{
+		"id":      "modr-mock",
+		"results": []any{map[string]any{"flagged": flagged, "categories": map[string]bool{"harassment": flagged}}},
+	})
+}
+
+// Embed is the provider's embedding: words hashed into buckets, so texts
+// sharing words are similar and identical texts are identical.
+func Embed(text string) []float32 {
+	v := make([]float32, defaultEmbeddingDim)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(word))
+		v[h.Sum32()%defaultEmbeddingDim]++
+	}
+	v[0] += 1e-3
+	return v
+}
+
+// Server runs a Provider on a local httptest server; base URLs point at
+// URL.
+type Server struct {
+	*Provider
+	*httptest.Server
+}
+
+func NewServer() *Server {
+	p := NewProvider()
+	return &Server{Provider: p, Server: httptest.NewServer(p)}
+}
+
+func estimateUsage(req ChatRequest, reply Reply) *Usage {
+	prompt := 0
+	for _, m := range req.Messages {
+		prompt += len(strings.Fields(m.Text()))
+	}
+	completion := len(strings.Fields(reply.Content)) + len(strings.Fields(reply.Reasoning))
+	return &Usage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+func split(text string, size int) []string {
+	runes := []rune(text)
+	var out []string
+	for start := 0; start < len(runes); start += size {
+		out = append(out, string(runes[start:min(start+size, len(runes))]))
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, typ, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"message": message, "type": typ, "code": typ},
+	})
+}
+
+func mustJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
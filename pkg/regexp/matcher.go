@@ -0,0 +1,70 @@
+package regexp
+
+import (
+	"time"
+
+	regexp "github.com/dlclark/regexp2"
+)
+
+// matchTimeout bounds each match so a badly written pattern cannot stall
+// the caller on adversarial input.
+const matchTimeout = 100 * time.Millisecond
+
+// Matcher finds any of a set of patterns, case-insensitively. Patterns use
+// the .NET-style syntax of regexp2, which adds lookarounds to RE2.
+type Matcher struct {
+	patterns []*regexp.Regexp
+}
+
+// Match is one occurrence of pattern Pattern at rune offset Index.
+type Match struct {
+	Pattern int
+	Index   int
+	Length  int
+	Text    string
+}
+
+func NewMatcher(patterns []string) (*Matcher, error) {
+	m := &Matcher{patterns: make([]*regexp.Regexp, 0, len(patterns))}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p, regexp.IgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+		re.MatchTimeout = matchTimeout
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// QuoteMeta escapes s so it matches literally.
+func QuoteMeta(s string) string {
+	return regexp.Escape(s)
+}
+
+// FindAll returns every match of every pattern, grouped by pattern.
+func (m *Matcher) FindAll(s string) ([]Match, error) {
+	var out []Match
+	for i, re := range m.patterns {
+		match, err := re.FindStringMatch(s)
+		for match != nil && err == nil {
+			out = append(out, Match{Pattern: i, Index: match.Index, Length: match.Length, Text: match.String()})
+			match, err = re.FindNextMatch(match)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ReplaceAll replaces every match of every pattern with repl.
+func (m *Matcher) ReplaceAll(s, repl string) (string, error) {
+	for _, re := range m.patterns {
+		var err error
+		if s, err = re.ReplaceFunc(s, func(regexp.Match) string { return repl }, -1, -1); err != nil {
+			return "", err
+		}
+	}
+	return s, nil
+}
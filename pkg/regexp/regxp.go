@@ -3,14 +3,18 @@ package regexp
 import regexp "github.com/dlclark/regexp2"
 
 type Handler struct {
-	validDatePhone *regexp.Regexp
-	validDateEmail *regexp.Regexp
+	validDatePhone    *regexp.Regexp
+	validDateEmail    *regexp.Regexp
+	validDateIDCard   *regexp.Regexp
+	validDateBankCard *regexp.Regexp
 }
 
 func NewHandler() *Handler {
 	return &Handler{
-		validDatePhone: regexp.MustCompile(`^1[3-9]\d{9}$`, regexp.Compiled),
-		validDateEmail: regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`, regexp.Compiled),
+		validDatePhone:    regexp.MustCompile(`^1[3-9]\d{9}$`, regexp.Compiled),
+		validDateEmail:    regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`, regexp.Compiled),
+		validDateIDCard:   regexp.MustCompile(`^[1-9][0-9]{5}(18|19|20)[0-9]{2}(0[1-9]|1[0-2])(0[1-9]|[12][0-9]|3[01])[0-9]{3}[0-9Xx]$`, regexp.Compiled),
+		validDateBankCard: regexp.MustCompile(`^[0-9]{13,19}$`, regexp.Compiled),
 	}
 }
 func (h *Handler) ValidatePhone(phone string) (bool, error) {
@@ -19,3 +23,46 @@ func (h *Handler) ValidatePhone(phone string) (bool, error) {
 func (h *Handler) ValidateEmail(email string) (bool, error) {
 	return h.validDateEmail.MatchString(email)
 }
+
+// ValidateIDCard checks the layout and check digit of an 18-digit resident
+// identity card number. The pattern spells digits as [0-9] since \d also
+// matches non-ASCII digits, which the checksum cannot read.
+func (h *Handler) ValidateIDCard(id string) (bool, error) {
+	ok, err := h.validDateIDCard.MatchString(id)
+	if !ok || err != nil {
+		return false, err
+	}
+	weights := [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(id[i]-'0') * w
+	}
+	check := "10X98765432"[sum%11]
+	last := id[17]
+	if last == 'x' {
+		last = 'X'
+	}
+	return last == check, nil
+}
+
+// ValidateBankCard checks the length and Luhn check digit of a card number
+// made of ASCII digits.
+func (h *Handler) ValidateBankCard(number string) (bool, error) {
+	ok, err := h.validDateBankCard.MatchString(number)
+	if !ok || err != nil {
+		return false, err
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0, nil
+}
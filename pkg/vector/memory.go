@@ -0,0 +1,193 @@
+package vector
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultMaxNamespaces = 1024
+
+type cachedRecord struct {
+	vector []float32
+	scope  string
+}
+
+type namespaceCache struct {
+	name     string
+	records  map[string]cachedRecord
+	loadedAt time.Time
+	elem     *list.Element
+}
+
+type memoryIndex struct {
+	store Store
+	ttl   time.Duration
+	max   int
+	mu    sync.RWMutex
+	cache map[string]*namespaceCache
+	// lru orders cached namespaces from most to least recently used.
+	lru *list.List
+}
+
+// NewMemoryIndex returns a brute-force cosine index. Namespaces are loaded
+// from the store on first use and reloaded after ttl so that writes made by
+// other instances become visible; a zero ttl keeps them until evicted by a
+// local write. At most maxNamespaces are cached, evicting the least recently
+// used; zero means a default of 1024.
+func NewMemoryIndex(store Store, ttl time.Duration, maxNamespaces int) Index {
+	if maxNamespaces <= 0 {
+		maxNamespaces = defaultMaxNamespaces
+	}
+	return &memoryIndex{
+		store: store,
+		ttl:   ttl,
+		max:   maxNamespaces,
+		cache: make(map[string]*namespaceCache),
+		lru:   list.New(),
+	}
+}
+
+func (m *memoryIndex) Upsert(ctx context.Context, namespace string, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	normalized := make([]Record, 0, len(records))
+	for _, r := range records {
+		normalized = append(normalized, Record{ID: r.ID, Vector: Normalize(r.Vector), Scope: r.Scope})
+	}
+	if err := m.store.SaveRecords(ctx, namespace, normalized); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.cache[namespace]; ok {
+		for _, r := range normalized {
+			c.records[r.ID] = cachedRecord{vector: r.Vector, scope: r.Scope}
+		}
+	}
+	return nil
+}
+
+func (m *memoryIndex) Delete(ctx context.Context, namespace string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := m.store.DeleteRecords(ctx, namespace, ids); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.cache[namespace]; ok {
+		for _, id := range ids {
+			delete(c.records, id)
+		}
+	}
+	return nil
+}
+
+func (m *memoryIndex) DeleteScope(ctx context.Context, scope string) error {
+	if scope == "" {
+		return nil
+	}
+	if err := m.store.DeleteScope(ctx, scope); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.cache {
+		for id, r := range c.records {
+			if r.scope == scope {
+				delete(c.records, id)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryIndex) DeleteNamespace(ctx context.Context, namespace string) error {
+	if err := m.store.DeleteNamespace(ctx, namespace); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	if c, ok := m.cache[namespace]; ok {
+		m.lru.Remove(c.elem)
+		delete(m.cache, namespace)
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memoryIndex) Search(ctx context.Context, namespace string, query []float32, k int, scopes ...string) ([]Hit, error) {
+	if k <= 0 || len(query) == 0 {
+		return nil, nil
+	}
+	c, err := m.namespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	query = Normalize(query)
+	var allowed map[string]struct{}
+	if len(scopes) > 0 {
+		allowed = make(map[string]struct{}, len(scopes))
+		for _, s := range scopes {
+			allowed[s] = struct{}{}
+		}
+	}
+
+	m.mu.RLock()
+	hits := make([]Hit, 0, len(c.records))
+	for id, r := range c.records {
+		if allowed != nil {
+			if _, ok := allowed[r.scope]; !ok {
+				continue
+			}
+		}
+		hits = append(hits, Hit{ID: id, Score: Dot(query, r.vector)})
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+func (m *memoryIndex) namespace(ctx context.Context, namespace string) (*namespaceCache, error) {
+	m.mu.Lock()
+	c, ok := m.cache[namespace]
+	if ok && (m.ttl <= 0 || time.Since(c.loadedAt) < m.ttl) {
+		m.lru.MoveToFront(c.elem)
+		m.mu.Unlock()
+		return c, nil
+	}
+	m.mu.Unlock()
+
+	records, err := m.store.LoadNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	c = &namespaceCache{
+		name:     namespace,
+		records:  make(map[string]cachedRecord, len(records)),
+		loadedAt: time.Now(),
+	}
+	for _, r := range records {
+		c.records[r.ID] = cachedRecord{vector: r.Vector, scope: r.Scope}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if old, ok := m.cache[namespace]; ok {
+		m.lru.Remove(old.elem)
+	}
+	c.elem = m.lru.PushFront(c)
+	m.cache[namespace] = c
+	for m.lru.Len() > m.max {
+		oldest := m.lru.Remove(m.lru.Back()).(*namespaceCache)
+		delete(m.cache, oldest.name)
+	}
+	return c, nil
+}
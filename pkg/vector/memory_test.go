@@ -0,0 +1,94 @@
+package vector
+
+import (
+	"context"
+	"testing"
+)
+
+type mapStore struct {
+	records map[string][]Record
+	loads   map[string]int
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{records: make(map[string][]Record), loads: make(map[string]int)}
+}
+
+func (s *mapStore) LoadNamespace(_ context.Context, namespace string) ([]Record, error) {
+	s.loads[namespace]++
+	return s.records[namespace], nil
+}
+
+func (s *mapStore) SaveRecords(_ context.Context, namespace string, records []Record) error {
+	s.records[namespace] = append(s.records[namespace], records...)
+	return nil
+}
+
+func (s *mapStore) DeleteRecords(context.Context, string, []string) error { return nil }
+
+func (s *mapStore) DeleteNamespace(_ context.Context, namespace string) error {
+	delete(s.records, namespace)
+	return nil
+}
+
+func (s *mapStore) DeleteScope(_ context.Context, scope string) error {
+	for ns, records := range s.records {
+		kept := records[:0]
+		for _, r := range records {
+			if r.Scope != scope {
+				kept = append(kept, r)
+			}
+		}
+		s.records[ns] = kept
+	}
+	return nil
+}
+
+func TestMemoryIndexScopes(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore()
+	store.records["u"] = []Record{
+		{ID: "1", Vector: []float32{1, 0}, Scope: "a"},
+		{ID: "2", Vector: []float32{1, 0.1}, Scope: "b"},
+	}
+	index := NewMemoryIndex(store, 0, 0)
+
+	hits, err := index.Search(ctx, "u", []float32{1, 0}, 10, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].ID != "2" {
+		t.Fatalf("scoped search = %+v, want only record 2", hits)
+	}
+	if hits, _ := index.Search(ctx, "u", []float32{1, 0}, 10); len(hits) != 2 {
+		t.Fatalf("unscoped search = %+v, want both records", hits)
+	}
+
+	if err := index.DeleteScope(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	hits, _ = index.Search(ctx, "u", []float32{1, 0}, 10)
+	if len(hits) != 1 || hits[0].ID != "2" {
+		t.Fatalf("after DeleteScope = %+v, want only record 2", hits)
+	}
+}
+
+func TestMemoryIndexEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore()
+	index := NewMemoryIndex(store, 0, 2)
+	query := []float32{1}
+
+	for _, ns := range []string{"a", "b", "a", "c", "a", "b"} {
+		if _, err := index.Search(ctx, ns, query, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "b" was evicted by "c" while "a" stayed in use, so only "b" reloads.
+	want := map[string]int{"a": 1, "b": 2, "c": 1}
+	for ns, n := range want {
+		if store.loads[ns] != n {
+			t.Errorf("loads[%s] = %d, want %d", ns, store.loads[ns], n)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package vector
+
+import (
+	"context"
+	"math"
+)
+
+// Record is one stored vector. Scope optionally tags it, e.g. with the
+// conversation a message belongs to, so that a search can be limited to
+// some scopes and a scope deleted across namespaces.
+type Record struct {
+	ID     string
+	Vector []float32
+	Scope  string
+}
+
+type Hit struct {
+	ID    string
+	Score float32
+}
+
+type Index interface {
+	Upsert(ctx context.Context, namespace string, records []Record) error
+	Delete(ctx context.Context, namespace string, ids []string) error
+	DeleteNamespace(ctx context.Context, namespace string) error
+	// DeleteScope removes the records of a scope from every namespace.
+	DeleteScope(ctx context.Context, scope string) error
+	// Search returns the k records nearest to query. When scopes are given,
+	// only records in one of them are ranked.
+	Search(ctx context.Context, namespace string, query []float32, k int, scopes ...string) ([]Hit, error)
+}
+
+// Store persists the records behind an in-process index.
+type Store interface {
+	LoadNamespace(ctx context.Context, namespace string) ([]Record, error)
+	SaveRecords(ctx context.Context, namespace string, records []Record) error
+	DeleteRecords(ctx context.Context, namespace string, ids []string) error
+	DeleteNamespace(ctx context.Context, namespace string) error
+	DeleteScope(ctx context.Context, scope string) error
+}
+
+func Normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+func Dot(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}